@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/spf13/cobra"
+	"go.lsp.dev/protocol"
+)
+
+// Output formats of the check command.
+const (
+	// formatText prints a finding per line, as compilers do.
+	formatText = "text"
+	// formatJSON prints a JSON array of findings.
+	formatJSON = "json"
+)
+
+// finding is a diagnostic reported by the check command for a file.
+type finding struct {
+	File     string       `json:"file"`
+	Range    findingRange `json:"range"`
+	Severity string       `json:"severity"`
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+}
+
+// findingRange is the range of a finding in its file.
+type findingRange struct {
+	Start findingPosition `json:"start"`
+	End   findingPosition `json:"end"`
+}
+
+// findingPosition is a one-based position in a file, as editors and CI
+// annotations count lines and columns.
+type findingPosition struct {
+	Line   uint32 `json:"line"`
+	Column uint32 `json:"column"`
+}
+
+// NewCheckCmd creates a new check command.
+//
+// It runs the same diagnostics as the language server over the given Go
+// files and directories, printing findings to the writer in the format
+// chosen by the format flag. Severities of the text format are colored
+// when writing to a terminal, unless disabled by NO_COLOR or the no-color
+// flag.
+func NewCheckCmd(writer io.Writer) *cobra.Command {
+	var (
+		format  string
+		noColor bool
+	)
+	cmd := cobra.Command{
+		Use:          "check [paths...]",
+		Short:        "Checks the go:embed directives of Go files.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != formatText && format != formatJSON {
+				return fmt.Errorf(
+					"unknown format %q, expected %q or %q",
+					format,
+					formatText,
+					formatJSON,
+				)
+			}
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			files, err := collectGoFiles(args)
+			if err != nil {
+				return err
+			}
+			findings, err := checkFiles(cmd.Context(), files)
+			if err != nil {
+				return err
+			}
+			if format == formatJSON {
+				err = writeJSONFindings(writer, findings)
+			} else {
+				err = writeTextFindings(
+					writer,
+					newRenderer(writer, noColor),
+					findings,
+				)
+			}
+			if err != nil {
+				return err
+			}
+			errCount := 0
+			for _, f := range findings {
+				if f.Severity == severityName(protocol.DiagnosticSeverityError) {
+					errCount++
+				}
+			}
+			if errCount > 0 {
+				return fmt.Errorf("found %d error(s)", errCount)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(
+		&format,
+		"format",
+		formatText,
+		"output format of the findings, text or json",
+	)
+	addNoColorFlag(&cmd, &noColor)
+	return &cmd
+}
+
+// checkFiles returns the findings of the diagnostics run over the files.
+func checkFiles(ctx context.Context, files []string) ([]finding, error) {
+	findings := make([]finding, 0)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		diagnostics := parsers.ParseSuppressions(string(content)).Filter(
+			parsers.DiagnoseDir(
+				ctx,
+				resolver.OS,
+				string(content),
+				filepath.Dir(file),
+				nil,
+			),
+		)
+		for _, diagnostic := range diagnostics {
+			findings = append(findings, finding{
+				File: file,
+				Range: findingRange{
+					Start: findingPosition{
+						Line:   diagnostic.Range.Start.Line + 1,
+						Column: diagnostic.Range.Start.Character + 1,
+					},
+					End: findingPosition{
+						Line:   diagnostic.Range.End.Line + 1,
+						Column: diagnostic.Range.End.Character + 1,
+					},
+				},
+				Severity: severityName(diagnostic.Severity),
+				Code:     fmt.Sprint(diagnostic.Code),
+				Message:  diagnostic.Message,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// severityName returns the lowercase name of a diagnostic severity.
+func severityName(severity protocol.DiagnosticSeverity) string {
+	return strings.ToLower(severity.String())
+}
+
+// writeTextFindings writes a finding per line to the writer, its severity
+// styled by the renderer.
+func writeTextFindings(
+	writer io.Writer,
+	renderer *lipgloss.Renderer,
+	findings []finding,
+) error {
+	for _, f := range findings {
+		_, err := fmt.Fprintf(
+			writer,
+			"%s:%d:%d: %s: %s [%s]\n",
+			f.File,
+			f.Range.Start.Line,
+			f.Range.Start.Column,
+			severityStyle(renderer, f.Severity).Render(f.Severity),
+			f.Message,
+			f.Code,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write finding: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJSONFindings writes the findings to the writer as an indented JSON
+// array, empty when there are none.
+func writeJSONFindings(writer io.Writer, findings []finding) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(findings); err != nil {
+		return fmt.Errorf("failed to write findings: %w", err)
+	}
+	return nil
+}
+
+// collectGoFiles returns the Go files named by the given paths, walking
+// directories recursively.
+func collectGoFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		err := filepath.WalkDir(
+			root,
+			func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if entry.IsDir() {
+					if path != root && skipDir(entry.Name()) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if strings.HasSuffix(path, ".go") {
+					files = append(files, path)
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+		}
+	}
+	return files, nil
+}
+
+// skipDir reports whether a directory is ignored by the go tool and should
+// therefore not be checked.
+func skipDir(name string) bool {
+	return name == "vendor" ||
+		name == "testdata" ||
+		strings.HasPrefix(name, ".") ||
+		strings.HasPrefix(name, "_")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckCmd tests the check command against the fixture directories.
+func TestCheckCmd(t *testing.T) {
+	bad := filepath.Join("testdata", "check", "bad")
+	var out bytes.Buffer
+	cmd := NewCheckCmd(&out)
+	cmd.SetArgs([]string{bad})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	assert.EqualError(t, err, "found 3 error(s)")
+	file := filepath.Join(bad, "main.go")
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if assert.Len(t, lines, 3) {
+		assert.Contains(t, string(lines[0]), file+":5:12: ")
+		assert.Contains(t, string(lines[0]), "'..'")
+		assert.Contains(t, string(lines[0]), "[embed/path-traversal]")
+		assert.Contains(t, string(lines[1]), file+":8:26: ")
+		assert.Contains(t, string(lines[1]), "absolute")
+		assert.Contains(t, string(lines[2]), file+":11:12: ")
+	}
+
+	out.Reset()
+	cmd = NewCheckCmd(&out)
+	cmd.SetArgs([]string{filepath.Join("testdata", "check", "good")})
+	assert.NoError(t, cmd.Execute())
+	assert.Empty(t, out.String())
+}
+
+// TestCheckCmdJSON tests the JSON findings of the check command against the
+// fixture directories.
+func TestCheckCmdJSON(t *testing.T) {
+	bad := filepath.Join("testdata", "check", "bad")
+	var out bytes.Buffer
+	cmd := NewCheckCmd(&out)
+	cmd.SetArgs([]string{"--format", "json", bad})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.EqualError(t, cmd.Execute(), "found 3 error(s)")
+	var findings []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &findings))
+	file := filepath.Join(bad, "main.go")
+	tests := []struct {
+		code  string
+		start [2]float64
+		end   [2]float64
+	}{
+		{code: "embed/path-traversal", start: [2]float64{5, 12}, end: [2]float64{5, 25}},
+		{code: "embed/absolute-path", start: [2]float64{8, 26}, end: [2]float64{8, 36}},
+		{code: "embed/invalid-pattern", start: [2]float64{11, 12}, end: [2]float64{11, 15}},
+	}
+	if !assert.Len(t, findings, len(tests)) {
+		return
+	}
+	for i, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			f := findings[i]
+			assert.ElementsMatch(
+				t,
+				[]string{"file", "range", "severity", "code", "message"},
+				keys(f),
+			)
+			assert.Equal(t, file, f["file"])
+			assert.Equal(t, "error", f["severity"])
+			assert.Equal(t, tt.code, f["code"])
+			assert.NotEmpty(t, f["message"])
+			assert.Equal(t, map[string]interface{}{
+				"start": map[string]interface{}{"line": tt.start[0], "column": tt.start[1]},
+				"end":   map[string]interface{}{"line": tt.end[0], "column": tt.end[1]},
+			}, f["range"])
+		})
+	}
+
+	out.Reset()
+	cmd = NewCheckCmd(&out)
+	cmd.SetArgs([]string{"--format", "json", filepath.Join("testdata", "check", "good")})
+	assert.NoError(t, cmd.Execute())
+	assert.JSONEq(t, "[]", out.String())
+
+	cmd = NewCheckCmd(&out)
+	cmd.SetArgs([]string{"--format", "xml", bad})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.EqualError(t, cmd.Execute(), `unknown format "xml", expected "text" or "json"`)
+}
+
+// TestCheckCmdColor tests that the severities of the text findings are
+// colored unless disabled by the no-color flag or NO_COLOR.
+func TestCheckCmdColor(t *testing.T) {
+	bad := filepath.Join("testdata", "check", "bad")
+	tests := []struct {
+		name    string
+		args    []string
+		noColor string
+		want    bool
+	}{
+		{name: "colored", args: []string{bad}, want: true},
+		{name: "no-color flag", args: []string{"--no-color", bad}, want: false},
+		{name: "NO_COLOR", args: []string{bad}, noColor: "1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// force colors although the output is not a terminal
+			t.Setenv("CLICOLOR_FORCE", "1")
+			t.Setenv("NO_COLOR", tt.noColor)
+			var out bytes.Buffer
+			cmd := NewCheckCmd(&out)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			assert.EqualError(t, cmd.Execute(), "found 3 error(s)")
+			assert.Equal(t, tt.want, strings.Contains(out.String(), "\x1b["))
+			assert.Contains(t, out.String(), "error")
+		})
+	}
+}
+
+// keys returns the keys of a JSON object.
+func keys(object map[string]interface{}) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	return names
+}
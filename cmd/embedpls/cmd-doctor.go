@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"github.com/conneroisu/embedpls/server"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// doctorTimeout bounds the time given to the server to answer the
+	// synthetic initialize request of the doctor command.
+	doctorTimeout = 5 * time.Second
+)
+
+// NewDoctorCmd creates a new doctor command.
+//
+// It starts the language server in process, initializes it over a pipe
+// and prints PASS when it answers with a well-formed response, FAIL
+// otherwise, so users can tell an install problem from an editor one.
+func NewDoctorCmd(writer io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:          "doctor",
+		Short:        "Verifies that the LSP server starts and responds.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			log.SetOutput(io.Discard)
+			if err := doctor(cmd.Context()); err != nil {
+				fmt.Fprintf(writer, "FAIL: %s\n", err)
+				return err
+			}
+			_, err := fmt.Fprintln(writer, "PASS: the server answered initialize")
+			return err
+		},
+	}
+}
+
+// doctor serves an in-process session, sends it an initialize request
+// and verifies its response.
+func doctor(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client, conn := net.Pipe()
+	defer client.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.New(server.Options{}).Serve(ctx, conn)
+		conn.Close()
+	}()
+	if err := client.SetDeadline(time.Now().Add(doctorTimeout)); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"processId":null,"rootUri":null,"capabilities":{}}}`
+	if _, err := fmt.Fprintf(client, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("failed to send initialize: %w", err)
+	}
+	scanner := bufio.NewScanner(client)
+	scanner.Buffer(make([]byte, 0, 4096), rpc.DefaultMaxMessageSize)
+	scanner.Split(rpc.Split)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("no response to initialize: %w", err)
+		}
+		return errors.New("no response to initialize: the server closed the connection")
+	}
+	if err := checkInitializeResponse(scanner.Bytes()); err != nil {
+		return err
+	}
+	client.Close()
+	if err := <-done; err != nil {
+		return fmt.Errorf("server stopped with an error: %w", err)
+	}
+	return nil
+}
+
+// checkInitializeResponse verifies that a message is the well-formed
+// response of the embedpls server to the initialize request of doctor.
+func checkInitializeResponse(message []byte) error {
+	msg, err := rpc.DecodeMessage(message)
+	if err != nil {
+		return fmt.Errorf("malformed response: %w", err)
+	}
+	var response struct {
+		RPC    string `json:"jsonrpc"`
+		ID     *int   `json:"id"`
+		Result *struct {
+			Capabilities json.RawMessage `json:"capabilities"`
+			ServerInfo   *struct {
+				Name string `json:"name"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(msg.Content, &response); err != nil {
+		return fmt.Errorf("malformed response: %w", err)
+	}
+	switch {
+	case response.RPC != "2.0":
+		return fmt.Errorf("unexpected jsonrpc version %q", response.RPC)
+	case response.ID == nil || *response.ID != 1:
+		return errors.New("response does not answer the initialize request")
+	case response.Result == nil:
+		return errors.New("response has no result")
+	case len(response.Result.Capabilities) == 0 ||
+		string(response.Result.Capabilities) == "null":
+		return errors.New("response has no capabilities")
+	case response.Result.ServerInfo == nil ||
+		response.Result.ServerInfo.Name != "embedpls":
+		return errors.New("response is not from an embedpls server")
+	}
+	return nil
+}
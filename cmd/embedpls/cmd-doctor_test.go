@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDoctorCmd tests that the doctor command passes against the real
+// server.
+func TestDoctorCmd(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewDoctorCmd(&out)
+	cmd.SetArgs([]string{})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, "PASS: the server answered initialize\n", out.String())
+}
+
+// TestCheckInitializeResponse tests the verification of the response to
+// the initialize request of the doctor command.
+func TestCheckInitializeResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			body: `{"jsonrpc":"2.0","id":1,"result":{"capabilities":{},"serverInfo":{"name":"embedpls"}}}`,
+		},
+		{
+			name:    "error response",
+			body:    `{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"boom"}}`,
+			wantErr: "response has no result",
+		},
+		{
+			name:    "other request",
+			body:    `{"jsonrpc":"2.0","id":2,"result":{"capabilities":{}}}`,
+			wantErr: "response does not answer the initialize request",
+		},
+		{
+			name:    "other server",
+			body:    `{"jsonrpc":"2.0","id":1,"result":{"capabilities":{},"serverInfo":{"name":"gopls"}}}`,
+			wantErr: "response is not from an embedpls server",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkInitializeResponse([]byte(
+				fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(tt.body), tt.body),
+			))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+// NewFilesCmd creates a new files command.
+//
+// It prints, for each go:embed directive of a Go file, the files the
+// directive embeds with their virtual path and size, which is what ends
+// up in the binary. Errors are colored when writing to a terminal, unless
+// disabled by NO_COLOR or the no-color flag.
+func NewFilesCmd(writer io.Writer) *cobra.Command {
+	var noColor bool
+	cmd := cobra.Command{
+		Use:          "files <file.go>",
+		Short:        "Lists the files embedded by the go:embed directives of a Go file.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listFiles(
+				cmd.Context(),
+				writer,
+				newRenderer(writer, noColor),
+				args[0],
+			)
+		},
+	}
+	addNoColorFlag(&cmd, &noColor)
+	return &cmd
+}
+
+// listFiles writes the files embedded by each directive of a Go file.
+//
+// A directive whose patterns fail to resolve is listed with its error, and
+// an error is returned once every directive is listed. Errors are styled by
+// the renderer.
+func listFiles(
+	ctx context.Context,
+	writer io.Writer,
+	renderer *lipgloss.Renderer,
+	file string,
+) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	dir := filepath.Dir(file)
+	failed := 0
+	for _, directive := range parsers.ParseDirectives(string(content)) {
+		patterns := make([]string, 0, len(directive.Patterns))
+		values := make([]string, 0, len(directive.Patterns))
+		for _, pattern := range directive.Patterns {
+			patterns = append(patterns, parsers.QuotePattern(pattern.Value))
+			values = append(values, pattern.Value)
+		}
+		_, err := fmt.Fprintf(
+			writer,
+			"%s:%d: //go:embed %s\n",
+			file,
+			directive.Line+1,
+			strings.Join(patterns, " "),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write directive: %w", err)
+		}
+		files, err := resolver.Resolve(ctx, resolver.OS, dir, values)
+		if err != nil {
+			failed++
+			_, err := fmt.Fprintf(
+				writer,
+				"  %s: %s\n",
+				severityStyle(renderer, "error").Render("error"),
+				err,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to write error: %w", err)
+			}
+			continue
+		}
+		table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		for _, name := range files {
+			info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				return fmt.Errorf("failed to stat %s: %w", name, err)
+			}
+			fmt.Fprintf(table, "  %s\t%d B\n", name, info.Size())
+		}
+		if err := table.Flush(); err != nil {
+			return fmt.Errorf("failed to write files: %w", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d directive(s) failed to resolve", failed)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFilesCmd tests that the files command lists the files embedded by
+// each directive of the fixture, with their size.
+func TestFilesCmd(t *testing.T) {
+	file := filepath.Join("testdata", "files", "main.go")
+	var out bytes.Buffer
+	cmd := NewFilesCmd(&out)
+	cmd.SetArgs([]string{file})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, file+":5: //go:embed hello.txt\n"+
+		"  hello.txt  6 B\n"+
+		file+":8: //go:embed static/*.js hello.txt\n"+
+		"  hello.txt      6 B\n"+
+		"  static/app.js  20 B\n"+
+		file+":11: //go:embed static\n"+
+		"  static/app.js     20 B\n"+
+		"  static/style.css  8 B\n"+
+		file+":14: //go:embed all:static\n"+
+		"  static/.hidden    7 B\n"+
+		"  static/app.js     20 B\n"+
+		"  static/style.css  8 B\n",
+		out.String(),
+	)
+}
+
+// TestFilesCmdUnresolved tests that the directives failing to resolve are
+// listed with their error and fail the command.
+func TestFilesCmdUnresolved(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	source := "package main\n\n//go:embed missing.txt\nvar s string\n\n//go:embed main.go\nvar m string\n"
+	assert.NoError(t, os.WriteFile(file, []byte(source), 0644))
+	var out bytes.Buffer
+	cmd := NewFilesCmd(&out)
+	cmd.SetArgs([]string{file})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.EqualError(t, cmd.Execute(), "1 directive(s) failed to resolve")
+	assert.Equal(t, file+":3: //go:embed missing.txt\n"+
+		"  error: pattern missing.txt: no matching files found\n"+
+		file+":6: //go:embed main.go\n"+
+		fmt.Sprintf("  main.go  %d B\n", len(source)),
+		out.String(),
+	)
+}
@@ -1,33 +1,44 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path"
-	"reflect"
+	"path/filepath"
+	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/conneroisu/embedpls/internal/rpc"
-	"github.com/conneroisu/embedpls/internal/safe"
-	"github.com/conneroisu/embedpls/internal/server"
+	"github.com/conneroisu/embedpls/server"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
-	"go.lsp.dev/uri"
 )
 
 // NewLspCmd creates a new lsp command.
+//
+// By default the server communicates over the given reader and writer,
+// when the listen flag is set it instead accepts a single TCP connection
+// on the given address. The root flag overrides the workspace root sent
+// by the client.
 func NewLspCmd(
 	reader io.Reader,
 	writer io.Writer,
-	handle func(documents *safe.Map[uri.URI, string]) server.Handler,
 ) *cobra.Command {
+	var (
+		listen  string
+		root    string
+		timeout time.Duration
+	)
 	cmd := cobra.Command{
 		Use:   "lsp",
 		Short: "Starts the LSP server.",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts, err := serverOptions(root, timeout)
+			if err != nil {
+				return err
+			}
 			configPath, err := CreateConfigDir("~/.config/embedpls/")
 			if err != nil {
 				return fmt.Errorf("failed to create config directory: %w", err)
@@ -43,63 +54,88 @@ func NewLspCmd(
 			}
 			log.SetOutput(f)
 			log.SetLevel(log.DebugLevel)
-			scanner := bufio.NewScanner(reader)
-			rpcWriter := rpc.NewWriter(writer)
-			innerCtx, cancel := context.WithCancel(cmd.Context())
-			documents := safe.NewSafeMap[uri.URI, string]()
-			handler := handle(documents)
-			defer cancel()
-			scanner.Split(rpc.Split)
-			for scanner.Scan() {
-				decoded, err := rpc.DecodeMessage(scanner.Bytes())
-				if err != nil {
-					return err
-				}
-				resp, err := handler.Handle(
-					innerCtx,
-					decoded,
-				)
-				if err != nil {
-					log.Errorf(
-						"failed to handle message: %s",
-						err,
-					)
-					continue
-				}
-				if !isNull(resp) {
-					err = rpcWriter.WriteResponse(innerCtx, resp)
-					if err != nil {
-						log.Errorf(
-							"failed to write (%s) response: %s",
-							resp.Method(),
-							err,
-						)
-					}
-				}
+			if listen == "" {
+				return serve(cmd.Context(), reader, writer, opts)
 			}
-			return nil
+			conn, err := acceptOne(listen)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			return serve(cmd.Context(), conn, conn, opts)
 		},
 	}
+	cmd.Flags().StringVar(
+		&listen,
+		"listen",
+		"",
+		"address to accept a single TCP connection on instead of using stdio",
+	)
+	cmd.Flags().StringVar(
+		&root,
+		"root",
+		"",
+		"directory of the workspace root, overriding the one sent by the editor",
+	)
+	cmd.Flags().DurationVar(
+		&timeout,
+		"request-timeout",
+		time.Second,
+		"time given to the server to handle a message before failing it",
+	)
 	return &cmd
 }
 
-// isNull checks if the given interface is nil or points to a nil value
-func isNull(i interface{}) bool {
-	if i == nil {
-		return true
+// acceptOne listens on the given address and returns the first connection
+// accepted on it.
+func acceptOne(address string) (net.Conn, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	defer listener.Close()
+	log.Infof("listening on %s", listener.Addr())
+	conn, err := listener.Accept()
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept connection: %w", err)
+	}
+	return conn, nil
+}
+
+// serverOptions returns the options of the server for the flags of the
+// lsp command, checking that the root, if any, is a directory.
+func serverOptions(root string, timeout time.Duration) (server.Options, error) {
+	opts := server.Options{RequestTimeout: timeout}
+	if root == "" {
+		return opts, nil
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return opts, fmt.Errorf("failed to resolve root %s: %w", root, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return opts, fmt.Errorf("failed to read root: %w", err)
 	}
-	// Use reflect.ValueOf only if the kind is valid for checking nil
-	v := reflect.ValueOf(i)
-	switch v.Kind() {
-	case reflect.Chan,
-		reflect.Func,
-		reflect.Interface,
-		reflect.Map,
-		reflect.Ptr,
-		reflect.Slice:
-		return v.IsNil()
+	if !info.IsDir() {
+		return opts, fmt.Errorf("root %s is not a directory", root)
 	}
-	return false
+	opts.Root = abs
+	return opts, nil
+}
+
+// serve answers the messages read from the reader on the writer with a
+// server of the given options until the reader is exhausted.
+func serve(
+	ctx context.Context,
+	reader io.Reader,
+	writer io.Writer,
+	opts server.Options,
+) error {
+	return server.New(opts).Serve(ctx, struct {
+		io.Reader
+		io.Writer
+	}{reader, writer})
 }
 
 // CreateConfigDir creates a new config directory and returns the path.
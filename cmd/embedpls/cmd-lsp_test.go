@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"github.com/conneroisu/embedpls/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestServe tests that serve answers requests over an in-memory pipe.
+func TestServe(t *testing.T) {
+	client, conn := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(context.Background(), conn, conn, server.Options{})
+		conn.Close()
+	}()
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`
+	_, err := fmt.Fprintf(client, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	assert.NoError(t, err)
+
+	scanner := bufio.NewScanner(client)
+	scanner.Split(rpc.Split)
+	assert.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+	if !assert.True(t, scanner.Scan(), "no response: %v", scanner.Err()) {
+		return
+	}
+	msg, err := rpc.DecodeMessage(scanner.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	var response struct {
+		Result struct {
+			ServerInfo struct {
+				Name string `json:"name"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(msg.Content, &response))
+	assert.Equal(t, "embedpls", response.Result.ServerInfo.Name)
+
+	assert.NoError(t, client.Close())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the connection closed")
+	}
+}
+
+// TestServeOversizedMessage tests that serve stops with an error instead
+// of buffering a message declaring an oversized content.
+func TestServeOversizedMessage(t *testing.T) {
+	reader := strings.NewReader(fmt.Sprintf(
+		"Content-Length: %d\r\n\r\n{}",
+		rpc.DefaultMaxMessageSize+1,
+	))
+	err := serve(context.Background(), reader, io.Discard, server.Options{})
+	assert.ErrorIs(t, err, rpc.ErrMessageTooLarge)
+}
+
+// TestServeLargeMessage tests that serve handles messages larger than the
+// default buffer of bufio.Scanner.
+func TestServeLargeMessage(t *testing.T) {
+	text := "package main\n\n//go:embed ../secret.txt\nvar s string\n\n" +
+		strings.Repeat("// padding\n", 100_000)
+	params, err := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{
+			"uri":  "file:///tmp/main.go",
+			"text": text,
+		},
+	})
+	assert.NoError(t, err)
+	body := `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":` + string(params) + `}`
+	out := &strings.Builder{}
+	err = serve(
+		context.Background(),
+		strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)),
+		out,
+		server.Options{},
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `"method":"textDocument/publishDiagnostics"`)
+	assert.Contains(t, out.String(), "../secret.txt")
+}
+
+// TestServerOptions tests that the root flag is made absolute and must
+// name a directory.
+func TestServerOptions(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	assert.NoError(t, os.WriteFile(file, []byte("package main\n"), 0644))
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	tests := []struct {
+		name     string
+		root     string
+		wantRoot string
+		wantErr  bool
+	}{
+		{name: "no root", root: "", wantRoot: ""},
+		{name: "absolute", root: dir, wantRoot: dir},
+		{name: "relative", root: "testdata", wantRoot: filepath.Join(wd, "testdata")},
+		{name: "missing", root: filepath.Join(dir, "missing"), wantErr: true},
+		{name: "file", root: file, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := serverOptions(tt.root, time.Second)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRoot, opts.Root)
+			assert.Equal(t, time.Second, opts.RequestTimeout)
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package main
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+
+	"github.com/conneroisu/embedpls/internal/version"
+	"github.com/spf13/cobra"
+)
 
 // NewVersionCmd creates a new version command.
+//
+// It prints the same version the server reports to clients at
+// initialization.
 func NewVersionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Prints the version of the tool",
-		Run: func(cmd *cobra.Command, args []string) {
-			// TODO: Implement
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), version.Version())
+			return err
 		},
 	}
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+	"go.lsp.dev/protocol"
+)
+
+// noColorFlag is the flag disabling the colors of the output of a command.
+const noColorFlag = "no-color"
+
+// addNoColorFlag adds the flag disabling colors to a command.
+func addNoColorFlag(cmd *cobra.Command, noColor *bool) {
+	cmd.Flags().BoolVar(
+		noColor,
+		noColorFlag,
+		false,
+		"disable colors, which are otherwise used when writing to a terminal and NO_COLOR is unset",
+	)
+}
+
+// newRenderer returns the renderer of the styles written to the writer.
+//
+// Colors are only rendered when the writer is a terminal, NO_COLOR is
+// unset and noColor is false.
+func newRenderer(writer io.Writer, noColor bool) *lipgloss.Renderer {
+	renderer := lipgloss.NewRenderer(writer)
+	if noColor {
+		renderer.SetColorProfile(termenv.Ascii)
+	}
+	return renderer
+}
+
+// severityStyle returns the style of the name of a severity.
+func severityStyle(renderer *lipgloss.Renderer, severity string) lipgloss.Style {
+	style := renderer.NewStyle().Bold(true)
+	switch severity {
+	case severityName(protocol.DiagnosticSeverityError):
+		return style.Foreground(lipgloss.Color("1"))
+	case severityName(protocol.DiagnosticSeverityWarning):
+		return style.Foreground(lipgloss.Color("3"))
+	case severityName(protocol.DiagnosticSeverityInformation):
+		return style.Foreground(lipgloss.Color("4"))
+	default:
+		return style.Foreground(lipgloss.Color("6"))
+	}
+}
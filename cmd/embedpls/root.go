@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/charmbracelet/log"
-	"github.com/conneroisu/embedpls/internal/server"
 	"github.com/spf13/cobra"
 )
 
@@ -29,12 +28,11 @@ var (
 )
 
 func init() {
-	rootCmd.AddCommand(NewLspCmd(
-		os.Stdin,
-		os.Stdout,
-		server.NewLSPHandler,
-	))
+	rootCmd.AddCommand(NewLspCmd(os.Stdin, os.Stdout))
 	rootCmd.AddCommand(NewVersionCmd())
+	rootCmd.AddCommand(NewCheckCmd(os.Stdout))
+	rootCmd.AddCommand(NewFilesCmd(os.Stdout))
+	rootCmd.AddCommand(NewDoctorCmd(os.Stdout))
 }
 
 // run is the main function for the application.
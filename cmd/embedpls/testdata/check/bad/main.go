@@ -0,0 +1,12 @@
+package main
+
+import "embed"
+
+//go:embed ../secret.txt
+var secret string
+
+//go:embed static/*.html /etc/hosts
+var static embed.FS
+
+//go:embed [a-
+var broken []byte
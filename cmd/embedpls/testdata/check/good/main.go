@@ -0,0 +1,9 @@
+package main
+
+import "embed"
+
+//go:embed hello.txt
+var hello string
+
+//go:embed static/*.html
+var static embed.FS
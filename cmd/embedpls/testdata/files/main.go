@@ -0,0 +1,15 @@
+package main
+
+import "embed"
+
+//go:embed hello.txt
+var hello string
+
+//go:embed static/*.js hello.txt
+var scripts embed.FS
+
+//go:embed static
+var static embed.FS
+
+//go:embed all:static
+var everything embed.FS
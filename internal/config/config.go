@@ -0,0 +1,197 @@
+// Package config loads the workspace configuration of the embedpls
+// language server.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Files are the names of the configuration files looked up in the
+// workspace root, in order of precedence.
+var Files = []string{
+	".embedpls.json",
+	".embedpls.yaml",
+	".embedpls.yml",
+}
+
+// Section is the section of the settings of embedpls among the settings of
+// a client.
+const Section = "embedpls"
+
+// DefaultPreviewSize is the default number of bytes read from the start of
+// a file to preview it.
+const DefaultPreviewSize = 1024
+
+// Config is the configuration of a workspace.
+type Config struct {
+	// Ignore are the glob patterns of the files and directories left out
+	// when scanning directories.
+	//
+	// A pattern without a slash is matched against every element of a
+	// path, a pattern containing one against the whole path relative to
+	// the workspace root. A trailing slash only matches directories.
+	Ignore []string `json:"ignore" yaml:"ignore"`
+	// RespectGitignore is whether the files ignored by the .gitignore
+	// files of their git repository are also left out of completions and
+	// directory listings. Diagnostics still report them, as the go command
+	// embeds them all the same.
+	RespectGitignore bool `json:"respectGitignore" yaml:"respectGitignore"`
+	// PreviewSize is the number of bytes read from the start of a file to
+	// preview it, DefaultPreviewSize when not positive.
+	PreviewSize int `json:"previewSize" yaml:"previewSize"`
+	// LogLevel is the level of the logs of the server, such as "debug" or
+	// "info", left as is when empty.
+	LogLevel string `json:"logLevel" yaml:"logLevel"`
+	// EmbedBase is the slash-separated path, relative to the workspace
+	// root, of the directory completion and hover resolve embedding
+	// patterns against instead of the directory of their Go file, for
+	// generated layouts whose assets live elsewhere. Empty means the
+	// directory of the file. Diagnostics keep the directory of the file,
+	// as the go command does.
+	EmbedBase string `json:"embedBase" yaml:"embedBase"`
+	// LintExportedFS is whether exported embed.FS variables are warned
+	// about, as embedded assets are usually an implementation detail.
+	LintExportedFS bool `json:"lintExportedFS" yaml:"lintExportedFS"`
+}
+
+// Default returns the configuration used when a workspace has none.
+func Default() Config {
+	return Config{
+		Ignore: []string{
+			".DS_Store",
+			".git/",
+			"node_modules/",
+		},
+	}
+}
+
+// Apply returns the configuration with the settings sent by a client, as
+// a JSON object of the fields of Config, applied over it.
+//
+// Fields missing from the settings keep their value, so the settings of
+// the client refine the configuration file of the workspace.
+func Apply(base Config, settings []byte) (Config, error) {
+	config := base
+	config.Ignore = append([]string(nil), base.Ignore...)
+	if err := json.Unmarshal(settings, &config); err != nil {
+		return base, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return base, fmt.Errorf("invalid settings: %w", err)
+	}
+	return config, nil
+}
+
+// Preview returns the number of bytes read from the start of a file to
+// preview it.
+func (c Config) Preview() int {
+	if c.PreviewSize <= 0 {
+		return DefaultPreviewSize
+	}
+	return c.PreviewSize
+}
+
+// Load loads the configuration of the workspace at root.
+//
+// The default configuration is returned when no configuration file exists.
+func Load(root string) (Config, error) {
+	for _, name := range Files {
+		filename := filepath.Join(root, name)
+		data, err := os.ReadFile(filename)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Default(), fmt.Errorf("failed to read config: %w", err)
+		}
+		config, err := parse(name, data)
+		if err != nil {
+			return Default(), fmt.Errorf(
+				"failed to parse config %s: %w",
+				filename,
+				err,
+			)
+		}
+		return config, nil
+	}
+	return Default(), nil
+}
+
+// parse parses the content of a configuration file based on its name.
+func parse(name string, data []byte) (Config, error) {
+	var config Config
+	var err error
+	if filepath.Ext(name) == ".json" {
+		err = json.Unmarshal(data, &config)
+	} else {
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	if err := config.validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// validate checks the ignore patterns, the log level and the embed base
+// of a configuration.
+func (c Config) validate() error {
+	for _, pattern := range c.Ignore {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/"), ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+	}
+	if c.LogLevel != "" {
+		if _, err := log.ParseLevel(c.LogLevel); err != nil {
+			return fmt.Errorf("invalid log level %q: %w", c.LogLevel, err)
+		}
+	}
+	if c.EmbedBase != "" {
+		base := path.Clean(c.EmbedBase)
+		if path.IsAbs(base) || base == ".." || strings.HasPrefix(base, "../") {
+			return fmt.Errorf("invalid embed base %q: not below the workspace root", c.EmbedBase)
+		}
+	}
+	return nil
+}
+
+// Ignored reports whether the file or directory with the given
+// slash-separated name, relative to the workspace root, is ignored.
+func (c Config) Ignored(name string, isDir bool) bool {
+	elements := strings.Split(name, "/")
+	for _, pattern := range c.Ignore {
+		dirOnly := strings.HasSuffix(pattern, "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if strings.Contains(pattern, "/") {
+			for i := len(elements); i > 0; i-- {
+				if dirOnly && i == len(elements) && !isDir {
+					continue
+				}
+				if matched, _ := path.Match(pattern, strings.Join(elements[:i], "/")); matched {
+					return true
+				}
+			}
+			continue
+		}
+		for i, element := range elements {
+			if dirOnly && i == len(elements)-1 && !isDir {
+				continue
+			}
+			if matched, _ := path.Match(pattern, element); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
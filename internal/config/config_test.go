@@ -0,0 +1,195 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoad tests loading the configuration of a workspace.
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "default when absent",
+			want: Default(),
+		},
+		{
+			name: "json",
+			files: map[string]string{
+				".embedpls.json": `{"ignore": ["*.tmp", "dist/"]}`,
+			},
+			want: Config{Ignore: []string{"*.tmp", "dist/"}},
+		},
+		{
+			name: "yaml",
+			files: map[string]string{
+				".embedpls.yaml": "ignore:\n  - \"*.tmp\"\n  - dist/\n",
+			},
+			want: Config{Ignore: []string{"*.tmp", "dist/"}},
+		},
+		{
+			name: "json takes precedence over yaml",
+			files: map[string]string{
+				".embedpls.json": `{"ignore": ["a"]}`,
+				".embedpls.yaml": "ignore: [b]\n",
+			},
+			want: Config{Ignore: []string{"a"}},
+		},
+		{
+			name: "parse error",
+			files: map[string]string{
+				".embedpls.json": `{"ignore": [`,
+			},
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name: "wrong type",
+			files: map[string]string{
+				".embedpls.yaml": "ignore: 3\n",
+			},
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name: "invalid pattern",
+			files: map[string]string{
+				".embedpls.json": `{"ignore": ["[a-"]}`,
+			},
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name: "embed base",
+			files: map[string]string{
+				".embedpls.yaml": "embedBase: gen/assets\n",
+			},
+			want: Config{EmbedBase: "gen/assets"},
+		},
+		{
+			name: "embed base outside of the root",
+			files: map[string]string{
+				".embedpls.yaml": "embedBase: ../assets\n",
+			},
+			want:    Default(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := Load(root)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestIgnored tests matching paths against the ignore list.
+func TestIgnored(t *testing.T) {
+	config := Config{Ignore: []string{
+		".DS_Store",
+		"node_modules/",
+		"*.tmp",
+		"web/dist",
+	}}
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{name: ".DS_Store", want: true},
+		{name: "static/.DS_Store", want: true},
+		{name: "node_modules", isDir: true, want: true},
+		{name: "node_modules", want: false},
+		{name: "web/node_modules/lib.js", want: true},
+		{name: "build.tmp", want: true},
+		{name: "web/dist", isDir: true, want: true},
+		{name: "web/dist/index.html", want: true},
+		{name: "dist/index.html", want: false},
+		{name: "static/index.html", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.Ignored(tt.name, tt.isDir); got != tt.want {
+				t.Errorf("Ignored(%q, %v) = %v, want %v", tt.name, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApply tests applying the settings of a client over a configuration.
+func TestApply(t *testing.T) {
+	base := Config{Ignore: []string{"*.tmp"}, PreviewSize: 512}
+	tests := []struct {
+		name     string
+		settings string
+		want     Config
+		wantErr  bool
+	}{
+		{
+			name:     "every field",
+			settings: `{"ignore": ["dist/"], "previewSize": 64, "logLevel": "info"}`,
+			want:     Config{Ignore: []string{"dist/"}, PreviewSize: 64, LogLevel: "info"},
+		},
+		{
+			name:     "missing fields are kept",
+			settings: `{"logLevel": "warn"}`,
+			want:     Config{Ignore: []string{"*.tmp"}, PreviewSize: 512, LogLevel: "warn"},
+		},
+		{
+			name:     "null",
+			settings: `null`,
+			want:     base,
+		},
+		{
+			name:     "invalid pattern",
+			settings: `{"ignore": ["[a-"]}`,
+			want:     base,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid log level",
+			settings: `{"logLevel": "loud"}`,
+			want:     base,
+			wantErr:  true,
+		},
+		{
+			name:     "absolute embed base",
+			settings: `{"embedBase": "/srv/assets"}`,
+			want:     base,
+			wantErr:  true,
+		},
+		{
+			name:     "wrong type",
+			settings: `{"previewSize": "big"}`,
+			want:     base,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(base, []byte(tt.settings))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, []string{"*.tmp"}, base.Ignore, "the base is not modified")
+}
@@ -0,0 +1,229 @@
+// Package gitignore matches paths against the .gitignore files of their
+// git repository.
+package gitignore
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+)
+
+// FileName is the name of the files holding the rules of a directory.
+const FileName = ".gitignore"
+
+// rule is a pattern of a .gitignore file.
+type rule struct {
+	// elements are the slash-separated elements of the pattern, "**"
+	// matching any number of directories.
+	elements []string
+	// anchored is whether the pattern is matched against the path
+	// relative to the directory of its file rather than against the
+	// last element of the path.
+	anchored bool
+	// dirOnly is whether the pattern only matches directories.
+	dirOnly bool
+	// negate is whether the pattern re-includes what previous patterns
+	// ignore.
+	negate bool
+}
+
+// Rules are the rules of a .gitignore file, in order.
+type Rules []rule
+
+// Parse parses the content of a .gitignore file.
+//
+// Blank lines and comments are skipped, a leading "!" negates a pattern, a
+// trailing "/" matches directories only and a pattern holding another "/"
+// is relative to the directory of the file.
+func Parse(data []byte) Rules {
+	rules := make(Rules, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r rule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		r.elements = strings.Split(line, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// Match reports whether the rules ignore the file or directory with the
+// given slash-separated name, relative to the directory of the rules, and
+// whether any rule matched it at all.
+//
+// The last matching rule wins, so a negated rule re-includes a name
+// ignored by a previous one.
+func (rules Rules) Match(name string, isDir bool) (ignored bool, matched bool) {
+	elements := strings.Split(name, "/")
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.anchored {
+			if !matchElements(r.elements, elements) {
+				continue
+			}
+		} else if ok, _ := path.Match(r.elements[0], elements[len(elements)-1]); !ok {
+			continue
+		}
+		ignored, matched = !r.negate, true
+	}
+	return ignored, matched
+}
+
+// matchElements reports whether the elements of a pattern match those of
+// a name, "**" matching any number of elements.
+func matchElements(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				// "dir/**" matches what is inside of dir, not dir
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchElements(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Matcher matches the files of a file system against the .gitignore
+// files of their directory and of its parents, up to the root of their
+// git repository.
+//
+// The rules of each directory are cached and read again when their file
+// is modified, so a matcher can serve a whole session.
+type Matcher struct {
+	fsys resolver.FS
+	mu   sync.Mutex
+	// files are the rules read by directory.
+	files map[string]cachedRules
+}
+
+// cachedRules are the rules read from the .gitignore file of a directory.
+type cachedRules struct {
+	modTime time.Time
+	rules   Rules
+}
+
+// NewMatcher returns a matcher reading the .gitignore files of fsys.
+func NewMatcher(fsys resolver.FS) *Matcher {
+	return &Matcher{fsys: fsys, files: make(map[string]cachedRules)}
+}
+
+// Ignored reports whether the file or directory at filename is ignored by
+// git.
+//
+// Like git, the files of an ignored directory are ignored whatever the
+// rules matching them. Files outside of a git repository are never
+// ignored.
+func (m *Matcher) Ignored(filename string, isDir bool) bool {
+	filename = filepath.Clean(filename)
+	root, ok := m.repositoryRoot(filepath.Dir(filename))
+	if !ok || filename == root {
+		return false
+	}
+	rel, err := filepath.Rel(root, filename)
+	if err != nil {
+		return false
+	}
+	elements := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range elements {
+		if m.ignoredBy(root, elements[:i+1], isDir || i < len(elements)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredBy reports whether the rules of the directories of the repository
+// at root ignore the path with the given elements relative to root.
+//
+// The rules of deeper directories take precedence over those of their
+// parents.
+func (m *Matcher) ignoredBy(root string, elements []string, isDir bool) bool {
+	ignored := false
+	dir := root
+	for i := range elements {
+		name := strings.Join(elements[i:], "/")
+		if ignore, matched := m.rules(dir).Match(name, isDir); matched {
+			ignored = ignore
+		}
+		dir = filepath.Join(dir, elements[i])
+	}
+	return ignored
+}
+
+// rules returns the rules of the .gitignore file of a directory, none when
+// it has no such file.
+func (m *Matcher) rules(dir string) Rules {
+	filename := filepath.Join(dir, FileName)
+	info, err := m.fsys.Stat(filename)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cached, ok := m.files[dir]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.rules
+	}
+	data, err := m.fsys.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	rules := Parse(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	m.files[dir] = cachedRules{modTime: info.ModTime(), rules: rules}
+	return rules
+}
+
+// repositoryRoot returns the closest directory holding a .git entry among
+// dir and its parents.
+func (m *Matcher) repositoryRoot(dir string) (string, bool) {
+	for {
+		if _, err := m.fsys.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
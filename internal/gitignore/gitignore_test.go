@@ -0,0 +1,95 @@
+package gitignore
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRulesMatch tests matching names relative to the directory of a
+// .gitignore file.
+func TestRulesMatch(t *testing.T) {
+	rules := Parse([]byte("# build output\n" +
+		"*.log\n" +
+		"!keep.log\n" +
+		"/dist\n" +
+		"build/\n" +
+		"docs/*.pdf\n" +
+		"**/tmp/**\n" +
+		"\\#literal\n" +
+		"trailing   \n" +
+		"\n"))
+	tests := []struct {
+		name        string
+		isDir       bool
+		wantIgnored bool
+		wantMatched bool
+	}{
+		{name: "app.log", wantIgnored: true, wantMatched: true},
+		{name: "nested/deep/app.log", wantIgnored: true, wantMatched: true},
+		{name: "keep.log", wantMatched: true},
+		{name: "dist", isDir: true, wantIgnored: true, wantMatched: true},
+		{name: "src/dist", isDir: true},
+		{name: "build", isDir: true, wantIgnored: true, wantMatched: true},
+		{name: "build"},
+		{name: "docs/manual.pdf", wantIgnored: true, wantMatched: true},
+		{name: "docs/v1/manual.pdf"},
+		{name: "a/tmp/b/c.txt", wantIgnored: true, wantMatched: true},
+		{name: "tmp", isDir: true},
+		{name: "#literal", wantIgnored: true, wantMatched: true},
+		{name: "trailing", wantIgnored: true, wantMatched: true},
+		{name: "main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignored, matched := rules.Match(tt.name, tt.isDir)
+			assert.Equal(t, tt.wantIgnored, ignored)
+			assert.Equal(t, tt.wantMatched, matched)
+		})
+	}
+}
+
+// TestMatcherIgnored tests matching files against the .gitignore files of
+// their repository, deeper files taking precedence and the files of
+// ignored directories staying ignored.
+func TestMatcherIgnored(t *testing.T) {
+	matcher := NewMatcher(resolver.FromFS(fstest.MapFS{
+		"repo/.git/HEAD":           {Data: []byte("ref: refs/heads/main\n")},
+		"repo/.gitignore":          {Data: []byte("*.tmp\nbin/\n")},
+		"repo/pkg/.gitignore":      {Data: []byte("!keep.tmp\ngenerated.go\n")},
+		"repo/pkg/keep.tmp":        {},
+		"repo/pkg/drop.tmp":        {},
+		"repo/pkg/generated.go":    {},
+		"repo/pkg/main.go":         {},
+		"repo/pkg/bin/keep.tmp":    {},
+		"repo/other/generated.go":  {},
+		"loose/.gitignore":         {Data: []byte("*\n")},
+		"loose/main.go":            {},
+		"repo/pkg/static/app.tmp":  {},
+		"repo/pkg/static/app.html": {},
+	}))
+	tests := []struct {
+		filename string
+		isDir    bool
+		want     bool
+	}{
+		{filename: "/repo/pkg/drop.tmp", want: true},
+		{filename: "/repo/pkg/keep.tmp"},
+		{filename: "/repo/pkg/generated.go", want: true},
+		{filename: "/repo/other/generated.go"},
+		{filename: "/repo/pkg/main.go"},
+		{filename: "/repo/pkg/bin", isDir: true, want: true},
+		{filename: "/repo/pkg/bin/keep.tmp", want: true},
+		{filename: "/repo/pkg/static/app.tmp", want: true},
+		{filename: "/repo/pkg/static/app.html"},
+		{filename: "/repo", isDir: true},
+		{filename: "/loose/main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			assert.Equal(t, tt.want, matcher.Ignored(tt.filename, tt.isDir))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package lsp
+
+import (
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// PositionEncodingKind is the encoding of the character offsets of
+// positions exchanged between the client and the server.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#positionEncodingKind
+type PositionEncodingKind string
+
+const (
+	// PositionEncodingUTF16 counts character offsets in UTF-16 code units.
+	//
+	// It is the default encoding and must always be supported by servers,
+	// and the one every position of the server is counted in.
+	PositionEncodingUTF16 PositionEncodingKind = "utf-16"
+)
+
+// InitializeParams are the parameters of an initialize request.
+type InitializeParams struct {
+	protocol.InitializeParams
+}
+
+// WorkDoneProgress reports whether the client supports progress
+// notifications initiated by the server.
+func (p InitializeParams) WorkDoneProgress() bool {
+	return p.Capabilities.Window != nil && p.Capabilities.Window.WorkDoneProgress
+}
+
+// Configuration reports whether the client supports workspace/configuration
+// requests initiated by the server.
+func (p InitializeParams) Configuration() bool {
+	return p.Capabilities.Workspace != nil && p.Capabilities.Workspace.Configuration
+}
+
+// WatchedFilesRegistration reports whether the client supports registering
+// file watchers dynamically for workspace/didChangeWatchedFiles
+// notifications.
+func (p InitializeParams) WatchedFilesRegistration() bool {
+	return p.Capabilities.Workspace != nil &&
+		p.Capabilities.Workspace.DidChangeWatchedFiles != nil &&
+		p.Capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration
+}
+
+// HoverMarkupKind returns the markup kind the server should use for hover
+// contents.
+//
+// Markdown is used when the client lists it among its hover content
+// formats, otherwise the contents are sent as plain text.
+func (p InitializeParams) HoverMarkupKind() protocol.MarkupKind {
+	if p.Capabilities.TextDocument == nil || p.Capabilities.TextDocument.Hover == nil {
+		return protocol.PlainText
+	}
+	for _, kind := range p.Capabilities.TextDocument.Hover.ContentFormat {
+		if kind == protocol.Markdown {
+			return protocol.Markdown
+		}
+	}
+	return protocol.PlainText
+}
+
+// WorkspaceRoot returns the URI of the workspace root, or an empty URI
+// when the client opened no workspace.
+//
+// The first workspace folder is preferred over the deprecated root URI
+// and root path.
+func (p InitializeParams) WorkspaceRoot() uri.URI {
+	switch {
+	case len(p.WorkspaceFolders) > 0:
+		return uri.URI(p.WorkspaceFolders[0].URI)
+	case p.RootURI != "":
+		return p.RootURI
+	case p.RootPath != "":
+		return uri.File(p.RootPath)
+	default:
+		return ""
+	}
+}
+
+// InitializeResult is the result of an initialize request.
+type InitializeResult struct {
+	// Capabilities are the capabilities provided by the server.
+	Capabilities ServerCapabilities `json:"capabilities"`
+	// ServerInfo is the information about the server.
+	ServerInfo *protocol.ServerInfo `json:"serverInfo,omitempty"`
+}
+
+// ServerCapabilities are the capabilities provided by the server.
+type ServerCapabilities struct {
+	protocol.ServerCapabilities
+	// PositionEncoding is the position encoding chosen by the server.
+	PositionEncoding PositionEncodingKind `json:"positionEncoding,omitempty"`
+	// InlayHintProvider is whether the server provides inlay hints.
+	InlayHintProvider bool `json:"inlayHintProvider,omitempty"`
+}
@@ -0,0 +1,40 @@
+package lsp
+
+const (
+	// CommandFindEmbedders is the command finding the go:embed directives
+	// embedding an asset.
+	//
+	// It takes the path or URI of the asset as its only argument and
+	// returns the locations of the matching patterns.
+	CommandFindEmbedders = "embedpls.findEmbedders"
+	// CommandStats is the command returning the counters of the work of
+	// the server, for performance tuning.
+	//
+	// It takes no argument.
+	CommandStats = "embedpls.stats"
+	// CommandScaffold is the command generating a starter embed.FS
+	// variable embedding a directory.
+	//
+	// It takes the URI of a Go document, a position in it and the
+	// slash-separated path of a directory relative to the document, and
+	// returns the workspace edit inserting the variable and its directive
+	// above the line of the position, along with the embed import.
+	CommandScaffold = "embedpls.scaffold"
+	// CommandShowMatchedFiles is the command listing the files a pattern
+	// of a go:embed directive currently matches.
+	//
+	// It takes the URI of a Go document and a pattern of one of its
+	// directives, shows the matched files to the user and returns their
+	// slash-separated paths relative to the document, sorted.
+	CommandShowMatchedFiles = "embedpls.showMatchedFiles"
+)
+
+// Commands returns the commands the server can execute.
+func Commands() []string {
+	return []string{
+		CommandFindEmbedders,
+		CommandStats,
+		CommandScaffold,
+		CommandShowMatchedFiles,
+	}
+}
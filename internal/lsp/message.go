@@ -1,10 +1,5 @@
 package lsp
 
-import (
-	"github.com/conneroisu/embedpls/internal/lsp/methods"
-	"go.lsp.dev/protocol"
-)
-
 // Request is the request to a LSP
 type Request struct {
 	// RPC is the rpc method for the request
@@ -25,11 +20,6 @@ type Response struct {
 	// Error  string `json:"error"`
 }
 
-// Method returns the method for the response
-func (r Response) Method() methods.Method {
-	return protocol.MethodInitialize
-}
-
 // Notification is a notification from a LSP
 type Notification struct {
 	// RPC is the rpc method for the notification.
@@ -0,0 +1,89 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/stretchr/testify/assert"
+)
+
+// methodActor is a message reporting its method.
+type methodActor interface {
+	Method() methods.Method
+}
+
+// TestMethod tests that every message type reports the method it is sent
+// or received with on the wire.
+func TestMethod(t *testing.T) {
+	tests := []struct {
+		actor methodActor
+		want  string
+	}{
+		{InitializeRequest{}, "initialize"},
+		{InitializeResponse{}, "initialize"},
+		{InitializedParamsRequest{}, "initialized"},
+		{ShutdownRequest{}, "shutdown"},
+		{ShutdownResponse{}, "shutdown"},
+		{CancelRequest{}, "$/cancelRequest"},
+		{SetTraceNotification{}, "$/setTrace"},
+		{ProgressNotification{}, "$/progress"},
+		{LogMessageNotification{}, "window/logMessage"},
+		{ShowMessageNotification{}, "window/showMessage"},
+		{NotificationDidOpenTextDocument{}, "textDocument/didOpen"},
+		{TextDocumentDidChangeNotification{}, "textDocument/didChange"},
+		{WillSaveTextDocumentNotification{}, "textDocument/willSave"},
+		{WillSaveWaitUntilRequest{}, "textDocument/willSaveWaitUntil"},
+		{WillSaveWaitUntilResponse{}, "textDocument/willSaveWaitUntil"},
+		{DidSaveTextDocumentNotification{}, "textDocument/didSave"},
+		{DidCloseTextDocumentParamsNotification{}, "textDocument/didClose"},
+		{PublishDiagnosticsNotification{}, "textDocument/publishDiagnostics"},
+		{TextDocumentCompletionRequest{}, "textDocument/completion"},
+		{TextDocumentCompletionResponse{}, "textDocument/completion"},
+		{CompletionItemResolveRequest{}, "completionItem/resolve"},
+		{CompletionItemResolveResponse{}, "completionItem/resolve"},
+		{HoverRequest{}, "textDocument/hover"},
+		{HoverResponse{}, "textDocument/hover"},
+		{SignatureHelpRequest{}, "textDocument/signatureHelp"},
+		{SignatureHelpResponse{}, "textDocument/signatureHelp"},
+		{TextDocumentCodeActionRequest{}, "textDocument/codeAction"},
+		{TextDocumentCodeActionResponse{}, "textDocument/codeAction"},
+		{DocumentHighlightRequest{}, "textDocument/documentHighlight"},
+		{DocumentHighlightResponse{}, "textDocument/documentHighlight"},
+		{FoldingRangeRequest{}, "textDocument/foldingRange"},
+		{FoldingRangeResponse{}, "textDocument/foldingRange"},
+		{SelectionRangeRequest{}, "textDocument/selectionRange"},
+		{SelectionRangeResponse{}, "textDocument/selectionRange"},
+		{InlayHintRequest{}, "textDocument/inlayHint"},
+		{InlayHintResponse{}, "textDocument/inlayHint"},
+		{RenameRequest{}, "textDocument/rename"},
+		{RenameResponse{}, "textDocument/rename"},
+		{PrepareRenameRequest{}, "textDocument/prepareRename"},
+		{PrepareRenameResponse{}, "textDocument/prepareRename"},
+		{DocumentFormattingRequest{}, "textDocument/formatting"},
+		{DocumentFormattingResponse{}, "textDocument/formatting"},
+		{ExecuteCommandRequest{}, "workspace/executeCommand"},
+		{ExecuteCommandResponse{}, "workspace/executeCommand"},
+		{DidChangeConfigurationNotification{}, "workspace/didChangeConfiguration"},
+		{DidChangeWatchedFilesNotification{}, "workspace/didChangeWatchedFiles"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T", tt.actor), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.actor.Method().String())
+		})
+	}
+}
+
+// TestConstructedNotificationMethod tests that the notifications built by
+// constructors carry their method on the wire.
+func TestConstructedNotificationMethod(t *testing.T) {
+	notification := NewDidCloseTextDocumentParamsNotification("file:///tmp/main.go")
+	encoded, err := json.Marshal(notification)
+	assert.NoError(t, err)
+	var wire struct {
+		Method string `json:"method"`
+	}
+	assert.NoError(t, json.Unmarshal(encoded, &wire))
+	assert.Equal(t, notification.Method().String(), wire.Method)
+}
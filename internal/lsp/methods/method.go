@@ -6,6 +6,13 @@ import (
 )
 
 // Method represents an LSP method
+//
+// Every method the server handles or sends has a constant below, named
+// Method followed by the method name, such as MethodTextDocumentHover for
+// "textDocument/hover", and grouped with the requests or notifications of
+// its area of the protocol: general, text document, window and workspace
+// methods. Handlers are registered by constant so that no method name is
+// spelled twice.
 type Method string
 
 // String returns the string representation of the method
@@ -22,3 +29,362 @@ func (m Method) Prefix(input string) bool {
 func (m Method) Decode() ([]byte, error) {
 	return json.Marshal(m)
 }
+
+// General Request Methods
+const (
+	// MethodInitialize is the initialize request method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#initialize
+	MethodInitialize Method = "initialize"
+
+	// MethodShutdown is the shutdown request method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#shutdown
+	MethodShutdown Method = "shutdown"
+
+	// MethodClientRegisterCapability is the request method sent from the
+	// server to the client to register a capability dynamically.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#client_registerCapability
+	MethodClientRegisterCapability Method = "client/registerCapability"
+)
+
+// General Notification Methods
+const (
+	// MethodInitialized is the initialized notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#initialized
+	MethodInitialized Method = "initialized"
+
+	// MethodExit is the exit notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#exit
+	MethodExit Method = "exit"
+
+	// MethodCancelRequest is the cancel request notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#cancelRequest
+	MethodCancelRequest Method = "$/cancelRequest"
+
+	// MethodProgress is the progress notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#progress
+	MethodProgress Method = "$/progress"
+
+	// MethodSetTrace is the set trace notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#setTrace
+	MethodSetTrace Method = "$/setTrace"
+
+	// MethodLogTrace is the log trace notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#logTrace
+	MethodLogTrace Method = "$/logTrace"
+)
+
+// Text Document Request Methods
+const (
+	// MethodTextDocumentCompletion is the text document completion request
+	// method.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_completion
+	MethodTextDocumentCompletion Method = "textDocument/completion"
+
+	// MethodCompletionItemResolve is the completion item resolve request
+	// method.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#completionItem_resolve
+	MethodCompletionItemResolve Method = "completionItem/resolve"
+
+	// MethodTextDocumentHover is the text document hover request method.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_hover
+	MethodTextDocumentHover Method = "textDocument/hover"
+
+	// MethodTextDocumentSignatureHelp is the text document signature help
+	// request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_signatureHelp
+	MethodTextDocumentSignatureHelp Method = "textDocument/signatureHelp"
+
+	// MethodTextDocumentDefinition is the text document definition request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_definition
+	MethodTextDocumentDefinition Method = "textDocument/definition"
+
+	// MethodTextDocumentReferences is the text document references request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_references
+	MethodTextDocumentReferences Method = "textDocument/references"
+
+	// MethodTextDocumentDocumentHighlight is the text document document
+	// highlight request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_documentHighlight
+	MethodTextDocumentDocumentHighlight Method = "textDocument/documentHighlight"
+
+	// MethodTextDocumentDocumentSymbol is the text document symbol request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_documentSymbol
+	MethodTextDocumentDocumentSymbol Method = "textDocument/documentSymbol"
+
+	// MethodTextDocumentFormatting is the text document formatting request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_formatting
+	MethodTextDocumentFormatting Method = "textDocument/formatting"
+
+	// MethodTextDocumentRangeFormatting is the text document range
+	// formatting request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_rangeFormatting
+	MethodTextDocumentRangeFormatting Method = "textDocument/rangeFormatting"
+
+	// MethodTextDocumentOnTypeFormatting is the text document on type
+	// formatting request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_onTypeFormatting
+	MethodTextDocumentOnTypeFormatting Method = "textDocument/onTypeFormatting"
+
+	// MethodTextDocumentRename is the text document rename request method
+	// for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_rename
+	MethodTextDocumentRename Method = "textDocument/rename"
+
+	// MethodTextDocumentPrepareRename is the text document prepare rename
+	// request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_prepareRename
+	MethodTextDocumentPrepareRename Method = "textDocument/prepareRename"
+
+	// MethodTextDocumentCodeAction is the text document code action request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_codeAction
+	MethodTextDocumentCodeAction Method = "textDocument/codeAction"
+
+	// MethodTextDocumentCodeLens is the text document code lens request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_codeLens
+	MethodTextDocumentCodeLens Method = "textDocument/codeLens"
+
+	// MethodTextDocumentDocumentLink is the text document document link
+	// request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_documentLink
+	MethodTextDocumentDocumentLink Method = "textDocument/documentLink"
+
+	// MethodTextDocumentFoldingRange is the text document folding range
+	// request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_foldingRange
+	MethodTextDocumentFoldingRange Method = "textDocument/foldingRange"
+
+	// MethodTextDocumentSelectionRange is the text document selection
+	// range request method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_selectionRange
+	MethodTextDocumentSelectionRange Method = "textDocument/selectionRange"
+
+	// MethodTextDocumentInlayHint is the text document inlay hint request
+	// method for the LSP.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_inlayHint
+	MethodTextDocumentInlayHint Method = "textDocument/inlayHint"
+
+	// MethodTextDocumentWillSaveWaitUntil is the text document will save
+	// wait until request method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_willSaveWaitUntil
+	MethodTextDocumentWillSaveWaitUntil Method = "textDocument/willSaveWaitUntil"
+)
+
+// Text Document Notification Methods
+const (
+	// MethodTextDocumentDidOpen is the text document did open notification
+	// method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_didOpen
+	MethodTextDocumentDidOpen Method = "textDocument/didOpen"
+
+	// MethodTextDocumentDidChange is the text document did change
+	// notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_didChange
+	MethodTextDocumentDidChange Method = "textDocument/didChange"
+
+	// MethodTextDocumentWillSave is the text document will save
+	// notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_willSave
+	MethodTextDocumentWillSave Method = "textDocument/willSave"
+
+	// MethodTextDocumentDidSave is the text document did save notification
+	// method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_didSave
+	MethodTextDocumentDidSave Method = "textDocument/didSave"
+
+	// MethodTextDocumentDidClose is the text document did close
+	// notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_didClose
+	MethodTextDocumentDidClose Method = "textDocument/didClose"
+
+	// MethodTextDocumentPublishDiagnostics is the publish diagnostics
+	// notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_publishDiagnostics
+	MethodTextDocumentPublishDiagnostics Method = "textDocument/publishDiagnostics"
+)
+
+// Window Request Methods
+const (
+	// MethodWindowWorkDoneProgressCreate is the request method sent from
+	// the server to the client to create a work done progress.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#window_workDoneProgress_create
+	MethodWindowWorkDoneProgressCreate Method = "window/workDoneProgress/create"
+)
+
+// Window Notification Methods
+const (
+	// MethodWindowLogMessage is the log message notification method for
+	// the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#window_logMessage
+	MethodWindowLogMessage Method = "window/logMessage"
+
+	// MethodWindowShowMessage is the show message notification method for
+	// the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#window_showMessage
+	MethodWindowShowMessage Method = "window/showMessage"
+
+	// MethodWindowWorkDoneProgressCancel is the notification method sent
+	// from the client to the server to cancel a work done progress.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#window_workDoneProgress_cancel
+	MethodWindowWorkDoneProgressCancel Method = "window/workDoneProgress/cancel"
+
+	// MethodTelemetryEvent is the telemetry event notification method.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#telemetry_event
+	MethodTelemetryEvent Method = "telemetry/event"
+)
+
+// Workspace Methods
+//
+// The methods in this package are used by the LSP to interact with the
+// workspace.
+// Interacting with the workspace is defined in the LSP specification.
+//
+// It includes methods for interacting with the workspace such as
+// workspace/didChangeConfiguration, workspace/didChangeWatchedFiles, workspace/symbol, and workspace/executeCommand.
+const (
+	// MethodWorkspaceDidChangeConfiguration is the workspace did change
+	// configuration notification method for the language server protocol.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didChangeConfiguration
+	MethodWorkspaceDidChangeConfiguration Method = "workspace/didChangeConfiguration"
+
+	// MethodWorkspaceConfiguration is the workspace configuration request
+	// method, sent by the server to fetch the settings of the client.
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_configuration
+	MethodWorkspaceConfiguration Method = "workspace/configuration"
+
+	// MethodWorkspaceDidChangeWatchedFiles is the workspace did change
+	// watched files method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didChangeWatchedFiles
+	MethodWorkspaceDidChangeWatchedFiles Method = "workspace/didChangeWatchedFiles"
+
+	// MethodWorkspaceDidChangeWorkspaceFolders is the workspace did change
+	// workspace folders notification method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didChangeWorkspaceFolders
+	MethodWorkspaceDidChangeWorkspaceFolders Method = "workspace/didChangeWorkspaceFolders"
+
+	// MethodWorkspaceDidCreateFiles is the workspace did create files
+	// notification method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didCreateFiles
+	MethodWorkspaceDidCreateFiles Method = "workspace/didCreateFiles"
+
+	// MethodWorkspaceDidRenameFiles is the workspace did rename files
+	// notification method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didRenameFiles
+	MethodWorkspaceDidRenameFiles Method = "workspace/didRenameFiles"
+
+	// MethodWorkspaceDidDeleteFiles is the workspace did delete files
+	// notification method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didDeleteFiles
+	MethodWorkspaceDidDeleteFiles Method = "workspace/didDeleteFiles"
+
+	// MethodWorkspaceSymbol is the workspace symbol method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_symbol
+	MethodWorkspaceSymbol Method = "workspace/symbol"
+
+	// MethodWorkspaceExecuteCommand is the workspace execute command method for the LSP
+	//
+	// Microsoft LSP Docs:
+	// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_executeCommand
+	MethodWorkspaceExecuteCommand Method = "workspace/executeCommand"
+)
@@ -0,0 +1,81 @@
+package methods
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMethodConstants tests that every method constant names a distinct
+// method and round-trips through Method, its string and its JSON forms.
+func TestMethodConstants(t *testing.T) {
+	tests := []struct {
+		method Method
+		want   string
+	}{
+		{MethodInitialize, "initialize"},
+		{MethodInitialized, "initialized"},
+		{MethodExit, "exit"},
+		{MethodProgress, "$/progress"},
+		{MethodSetTrace, "$/setTrace"},
+		{MethodLogTrace, "$/logTrace"},
+		{MethodCancelRequest, "$/cancelRequest"},
+		{MethodShutdown, "shutdown"},
+		{MethodClientRegisterCapability, "client/registerCapability"},
+		{MethodTextDocumentDidOpen, "textDocument/didOpen"},
+		{MethodTextDocumentCompletion, "textDocument/completion"},
+		{MethodCompletionItemResolve, "completionItem/resolve"},
+		{MethodTextDocumentHover, "textDocument/hover"},
+		{MethodTextDocumentSignatureHelp, "textDocument/signatureHelp"},
+		{MethodTextDocumentDefinition, "textDocument/definition"},
+		{MethodTextDocumentReferences, "textDocument/references"},
+		{MethodTextDocumentDocumentHighlight, "textDocument/documentHighlight"},
+		{MethodTextDocumentDocumentSymbol, "textDocument/documentSymbol"},
+		{MethodTextDocumentFormatting, "textDocument/formatting"},
+		{MethodTextDocumentDidClose, "textDocument/didClose"},
+		{MethodTextDocumentRangeFormatting, "textDocument/rangeFormatting"},
+		{MethodTextDocumentOnTypeFormatting, "textDocument/onTypeFormatting"},
+		{MethodTextDocumentRename, "textDocument/rename"},
+		{MethodTextDocumentPrepareRename, "textDocument/prepareRename"},
+		{MethodTextDocumentCodeAction, "textDocument/codeAction"},
+		{MethodTextDocumentCodeLens, "textDocument/codeLens"},
+		{MethodTextDocumentDocumentLink, "textDocument/documentLink"},
+		{MethodTextDocumentFoldingRange, "textDocument/foldingRange"},
+		{MethodTextDocumentSelectionRange, "textDocument/selectionRange"},
+		{MethodTextDocumentInlayHint, "textDocument/inlayHint"},
+		{MethodTextDocumentPublishDiagnostics, "textDocument/publishDiagnostics"},
+		{MethodTextDocumentDidSave, "textDocument/didSave"},
+		{MethodTextDocumentWillSave, "textDocument/willSave"},
+		{MethodTextDocumentWillSaveWaitUntil, "textDocument/willSaveWaitUntil"},
+		{MethodTextDocumentDidChange, "textDocument/didChange"},
+		{MethodWindowLogMessage, "window/logMessage"},
+		{MethodWindowShowMessage, "window/showMessage"},
+		{MethodWindowWorkDoneProgressCreate, "window/workDoneProgress/create"},
+		{MethodWindowWorkDoneProgressCancel, "window/workDoneProgress/cancel"},
+		{MethodTelemetryEvent, "telemetry/event"},
+		{MethodWorkspaceDidChangeConfiguration, "workspace/didChangeConfiguration"},
+		{MethodWorkspaceConfiguration, "workspace/configuration"},
+		{MethodWorkspaceDidChangeWatchedFiles, "workspace/didChangeWatchedFiles"},
+		{MethodWorkspaceDidChangeWorkspaceFolders, "workspace/didChangeWorkspaceFolders"},
+		{MethodWorkspaceDidCreateFiles, "workspace/didCreateFiles"},
+		{MethodWorkspaceDidRenameFiles, "workspace/didRenameFiles"},
+		{MethodWorkspaceDidDeleteFiles, "workspace/didDeleteFiles"},
+		{MethodWorkspaceSymbol, "workspace/symbol"},
+		{MethodWorkspaceExecuteCommand, "workspace/executeCommand"},
+	}
+	seen := make(map[Method]bool)
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.False(t, seen[tt.method], "method declared twice")
+			seen[tt.method] = true
+			assert.Equal(t, tt.method, Method(tt.want))
+			assert.Equal(t, tt.want, tt.method.String())
+			encoded, err := tt.method.Decode()
+			assert.NoError(t, err)
+			var decoded Method
+			assert.NoError(t, json.Unmarshal(encoded, &decoded))
+			assert.Equal(t, tt.method, decoded)
+		})
+	}
+}
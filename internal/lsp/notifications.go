@@ -19,7 +19,7 @@ type NotificationDidOpenTextDocument struct {
 
 // Method returns the method for the did open text document params notification.
 func (r NotificationDidOpenTextDocument) Method() methods.Method {
-	return methods.MethodRequestTextDocumentDidOpen
+	return methods.MethodTextDocumentDidOpen
 }
 
 // PublishDiagnosticsNotification is the notification for publishing diagnostics.
@@ -32,7 +32,77 @@ type PublishDiagnosticsNotification struct {
 
 // Method returns the method for the publish diagnostics notification
 func (r PublishDiagnosticsNotification) Method() methods.Method {
-	return methods.NotificationPublishDiagnostics
+	return methods.MethodTextDocumentPublishDiagnostics
+}
+
+// DidChangeConfigurationNotification is the notification sent by the
+// client when its settings change.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didChangeConfiguration
+type DidChangeConfigurationNotification struct {
+	// DidChangeConfigurationNotification embeds the Notification struct
+	Notification
+	// Params are the parameters for the did change configuration
+	// notification.
+	Params protocol.DidChangeConfigurationParams `json:"params"`
+}
+
+// Method returns the method for the did change configuration notification
+func (r DidChangeConfigurationNotification) Method() methods.Method {
+	return methods.MethodWorkspaceDidChangeConfiguration
+}
+
+// DidChangeWatchedFilesNotification is the notification sent by the client
+// when files watched by the server change.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_didChangeWatchedFiles
+type DidChangeWatchedFilesNotification struct {
+	// DidChangeWatchedFilesNotification embeds the Notification struct
+	Notification
+	// Params are the parameters for the did change watched files
+	// notification.
+	Params protocol.DidChangeWatchedFilesParams `json:"params"`
+}
+
+// Method returns the method for the did change watched files notification
+func (r DidChangeWatchedFilesNotification) Method() methods.Method {
+	return methods.MethodWorkspaceDidChangeWatchedFiles
+}
+
+// ProgressNotification is the notification reporting the progress of a
+// long-running operation.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#progress
+type ProgressNotification struct {
+	// ProgressNotification embeds the Notification struct
+	Notification
+	// Params are the parameters for the progress notification.
+	Params protocol.ProgressParams `json:"params"`
+}
+
+// Method returns the method for the progress notification
+func (r ProgressNotification) Method() methods.Method {
+	return methods.MethodProgress
+}
+
+// SetTraceNotification is the notification sent by the client to change
+// the verbosity of the server's trace.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#setTrace
+type SetTraceNotification struct {
+	// SetTraceNotification embeds the Notification struct
+	Notification
+	// Params are the parameters for the set trace notification.
+	Params protocol.SetTraceParams `json:"params"`
+}
+
+// Method returns the method for the set trace notification
+func (r SetTraceNotification) Method() methods.Method {
+	return methods.MethodSetTrace
 }
 
 const (
@@ -61,9 +131,14 @@ type WillSaveTextDocumentNotification struct {
 	Params protocol.WillSaveTextDocumentParams `json:"params"`
 }
 
+// Method returns the method for the will save text document notification
+func (r WillSaveTextDocumentNotification) Method() methods.Method {
+	return methods.MethodTextDocumentWillSave
+}
+
 // Method returns the method for the did save text document params notification
 func (r DidSaveTextDocumentNotification) Method() methods.Method {
-	return methods.MethodNotificationTextDocumentDidSave
+	return methods.MethodTextDocumentDidSave
 }
 
 // DidCloseTextDocumentParamsNotification is a struct for the did close text document params notification
@@ -77,7 +152,7 @@ type DidCloseTextDocumentParamsNotification struct {
 
 // Method returns the method for the did close text document params notification
 func (r DidCloseTextDocumentParamsNotification) Method() methods.Method {
-	return methods.NotificationTextDocumentDidClose
+	return methods.MethodTextDocumentDidClose
 }
 
 // NewDidCloseTextDocumentParamsNotification returns a new did close text document params notification
@@ -86,7 +161,8 @@ func NewDidCloseTextDocumentParamsNotification(
 ) DidCloseTextDocumentParamsNotification {
 	return DidCloseTextDocumentParamsNotification{
 		Notification: Notification{
-			RPC: RPCVersion,
+			RPC:    RPCVersion,
+			Method: methods.MethodTextDocumentDidClose.String(),
 		},
 		Params: protocol.DidCloseTextDocumentParams{
 			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
@@ -94,6 +170,39 @@ func NewDidCloseTextDocumentParamsNotification(
 	}
 }
 
+// DidChangeTextDocumentParams are the parameters of a did change text
+// document notification.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#didChangeTextDocumentParams
+type DidChangeTextDocumentParams struct {
+	// TextDocument is the document that did change, with the version
+	// after all the content changes are applied.
+	TextDocument protocol.VersionedTextDocumentIdentifier `json:"textDocument"`
+	// ContentChanges are the changes of the content, to apply in order.
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// TextDocumentContentChangeEvent is a change of the content of a text
+// document, replacing its range when set or its whole text otherwise.
+//
+// The protocol package declares the range as a value, which cannot tell a
+// full change from an incremental change at the start of the document.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocumentContentChangeEvent
+type TextDocumentContentChangeEvent struct {
+	// Range is the range of the document that changed, nil when the text
+	// is the whole content of the document.
+	Range *protocol.Range `json:"range,omitempty"`
+	// RangeLength is the length of the range that got replaced.
+	//
+	// Deprecated: use Range instead.
+	RangeLength uint32 `json:"rangeLength,omitempty"`
+	// Text is the new text of the range or document.
+	Text string `json:"text"`
+}
+
 // TextDocumentDidChangeNotification is sent from the client to the server to signal
 // that the content of a text document has changed.
 //
@@ -103,10 +212,10 @@ type TextDocumentDidChangeNotification struct {
 	// TextDocumentDidChangeNotification embeds the Notification struct
 	Notification
 	// Params are the parameters for the notification.
-	Params protocol.DidChangeTextDocumentParams `json:"params"`
+	Params DidChangeTextDocumentParams `json:"params"`
 }
 
 // Method returns the method for the text document did change notification
 func (r TextDocumentDidChangeNotification) Method() methods.Method {
-	return methods.NotificationMethodTextDocumentDidChange
+	return methods.MethodTextDocumentDidChange
 }
@@ -18,7 +18,7 @@ type TextDocumentCompletionRequest struct {
 
 // Method returns the method for the completion request
 func (r TextDocumentCompletionRequest) Method() methods.Method {
-	return methods.MethodRequestTextDocumentCompletion
+	return methods.MethodTextDocumentCompletion
 }
 
 // TextDocumentCompletionResponse is a response for a completion to the language server
@@ -34,7 +34,41 @@ type TextDocumentCompletionResponse struct {
 
 // Method returns the method for the completion response
 func (r TextDocumentCompletionResponse) Method() methods.Method {
-	return methods.MethodRequestTextDocumentCompletion
+	return methods.MethodTextDocumentCompletion
+}
+
+// CompletionItemResolveRequest is a request to resolve the details of a
+// completion item, such as its documentation, once the user selects it.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#completionItem_resolve
+type CompletionItemResolveRequest struct {
+	// CompletionItemResolveRequest embeds the Request struct
+	Request
+	// Params is the completion item to resolve.
+	Params protocol.CompletionItem `json:"params"`
+}
+
+// Method returns the method for the completion item resolve request
+func (r CompletionItemResolveRequest) Method() methods.Method {
+	return methods.MethodCompletionItemResolve
+}
+
+// CompletionItemResolveResponse is a response for a completion item
+// resolve request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#completionItem_resolve
+type CompletionItemResolveResponse struct {
+	// CompletionItemResolveResponse embeds the Response struct
+	Response
+	// Result is the resolved completion item.
+	Result protocol.CompletionItem `json:"result"`
+}
+
+// Method returns the method for the completion item resolve response
+func (r CompletionItemResolveResponse) Method() methods.Method {
+	return methods.MethodCompletionItemResolve
 }
 
 // TextDocumentCodeActionRequest is a request for a code action to the language server.
@@ -50,7 +84,7 @@ type TextDocumentCodeActionRequest struct {
 
 // Method returns the method for the code action request
 func (r TextDocumentCodeActionRequest) Method() methods.Method {
-	return methods.MethodRequestTextDocumentCodeAction
+	return methods.MethodTextDocumentCodeAction
 }
 
 // HoverRequest is sent from the client to the server to request hover
@@ -67,7 +101,7 @@ type HoverRequest struct {
 
 // Method returns the method for the hover request
 func (r HoverRequest) Method() methods.Method {
-	return methods.MethodRequestTextDocumentHover
+	return methods.MethodTextDocumentHover
 }
 
 // InitializeRequest is a struct for the initialize request.
@@ -78,7 +112,7 @@ type InitializeRequest struct {
 	// InitializeRequest embeds the Request struct
 	Request
 	// Params are the parameters for the initialize request.
-	Params protocol.InitializeParams `json:"params"`
+	Params InitializeParams `json:"params"`
 }
 
 // Method returns the method for the initialize request.
@@ -91,13 +125,13 @@ func (r InitializeRequest) Method() methods.Method {
 // Microsoft LSP Docs:
 // https://microsoft.github.io/language-server-protocol/specifications/specification-current/#initialized
 type InitializedParamsRequest struct {
-	// InitializedParamsRequest embeds the Request struct
-	Response
+	// InitializedParamsRequest embeds the Notification struct
+	Notification
 }
 
 // Method returns the method for the initialized params request.
 func (r InitializedParamsRequest) Method() methods.Method {
-	return methods.MethodNotificationInitialized
+	return methods.MethodInitialized
 }
 
 // CancelRequest is sent from the client to the server to cancel a request.
@@ -127,3 +161,198 @@ type ShutdownRequest struct {
 func (r ShutdownRequest) Method() methods.Method {
 	return methods.MethodShutdown
 }
+
+// ExecuteCommandRequest is sent from the client to the server to trigger
+// the execution of a command on the server.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_executeCommand
+type ExecuteCommandRequest struct {
+	// ExecuteCommandRequest embeds the Request struct
+	Request
+	// Params are the parameters for the execute command request.
+	Params protocol.ExecuteCommandParams `json:"params"`
+}
+
+// Method returns the method for the execute command request
+func (r ExecuteCommandRequest) Method() methods.Method {
+	return methods.MethodWorkspaceExecuteCommand
+}
+
+// DocumentHighlightRequest is sent from the client to the server to
+// resolve the highlights for a given text document position.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_documentHighlight
+type DocumentHighlightRequest struct {
+	// DocumentHighlightRequest embeds the Request struct
+	Request
+	// Params are the parameters for the document highlight request.
+	Params protocol.DocumentHighlightParams `json:"params"`
+}
+
+// Method returns the method for the document highlight request
+func (r DocumentHighlightRequest) Method() methods.Method {
+	return methods.MethodTextDocumentDocumentHighlight
+}
+
+// FoldingRangeRequest is sent from the client to the server to return all
+// folding ranges found in a given text document.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_foldingRange
+type FoldingRangeRequest struct {
+	// FoldingRangeRequest embeds the Request struct
+	Request
+	// Params are the parameters for the folding range request.
+	Params protocol.FoldingRangeParams `json:"params"`
+}
+
+// Method returns the method for the folding range request
+func (r FoldingRangeRequest) Method() methods.Method {
+	return methods.MethodTextDocumentFoldingRange
+}
+
+// SelectionRangeParams are the parameters of a selection range request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#selectionRangeParams
+type SelectionRangeParams struct {
+	// TextDocument is the text document.
+	TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	// Positions are the positions inside the text document.
+	Positions []protocol.Position `json:"positions"`
+}
+
+// SelectionRangeRequest is sent from the client to the server to return
+// suggested selection ranges at an array of given positions.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_selectionRange
+type SelectionRangeRequest struct {
+	// SelectionRangeRequest embeds the Request struct
+	Request
+	// Params are the parameters for the selection range request.
+	Params SelectionRangeParams `json:"params"`
+}
+
+// Method returns the method for the selection range request
+func (r SelectionRangeRequest) Method() methods.Method {
+	return methods.MethodTextDocumentSelectionRange
+}
+
+// InlayHintParams are the parameters of an inlay hint request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#inlayHintParams
+type InlayHintParams struct {
+	// TextDocument is the text document.
+	TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	// Range is the visible document range for which inlay hints should be
+	// computed.
+	Range protocol.Range `json:"range"`
+}
+
+// InlayHintRequest is sent from the client to the server to compute inlay
+// hints for a given text document range.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_inlayHint
+type InlayHintRequest struct {
+	// InlayHintRequest embeds the Request struct
+	Request
+	// Params are the parameters for the inlay hint request.
+	Params InlayHintParams `json:"params"`
+}
+
+// Method returns the method for the inlay hint request
+func (r InlayHintRequest) Method() methods.Method {
+	return methods.MethodTextDocumentInlayHint
+}
+
+// RenameRequest is sent from the client to the server to ask the server to
+// compute a workspace change so that the client can perform a workspace-wide
+// rename of a symbol.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_rename
+type RenameRequest struct {
+	// RenameRequest embeds the Request struct
+	Request
+	// Params are the parameters for the rename request.
+	Params protocol.RenameParams `json:"params"`
+}
+
+// Method returns the method for the rename request
+func (r RenameRequest) Method() methods.Method {
+	return methods.MethodTextDocumentRename
+}
+
+// PrepareRenameRequest is sent from the client to the server to setup and
+// test the validity of a rename operation at a given location.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_prepareRename
+type PrepareRenameRequest struct {
+	// PrepareRenameRequest embeds the Request struct
+	Request
+	// Params are the parameters for the prepare rename request.
+	Params protocol.PrepareRenameParams `json:"params"`
+}
+
+// Method returns the method for the prepare rename request
+func (r PrepareRenameRequest) Method() methods.Method {
+	return methods.MethodTextDocumentPrepareRename
+}
+
+// SignatureHelpRequest is sent from the client to the server to request
+// signature information at a given cursor position.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_signatureHelp
+type SignatureHelpRequest struct {
+	// SignatureHelpRequest embeds the Request struct
+	Request
+	// Params are the parameters for the signature help request.
+	Params protocol.SignatureHelpParams `json:"params"`
+}
+
+// Method returns the method for the signature help request
+func (r SignatureHelpRequest) Method() methods.Method {
+	return methods.MethodTextDocumentSignatureHelp
+}
+
+// DocumentFormattingRequest is sent from the client to the server to format
+// a whole document.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_formatting
+type DocumentFormattingRequest struct {
+	// DocumentFormattingRequest embeds the Request struct
+	Request
+	// Params are the parameters for the formatting request.
+	Params protocol.DocumentFormattingParams `json:"params"`
+}
+
+// Method returns the method for the formatting request
+func (r DocumentFormattingRequest) Method() methods.Method {
+	return methods.MethodTextDocumentFormatting
+}
+
+// WillSaveWaitUntilRequest is sent from the client to the server before a
+// document is saved, letting the server return edits applied before the
+// save.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_willSaveWaitUntil
+type WillSaveWaitUntilRequest struct {
+	// WillSaveWaitUntilRequest embeds the Request struct
+	Request
+	// Params are the parameters for the will save wait until request.
+	Params protocol.WillSaveTextDocumentParams `json:"params"`
+}
+
+// Method returns the method for the will save wait until request
+func (r WillSaveWaitUntilRequest) Method() methods.Method {
+	return methods.MethodTextDocumentWillSaveWaitUntil
+}
@@ -8,15 +8,6 @@ import (
 	"go.lsp.dev/protocol"
 )
 
-// CancelResponse is the response for a cancel request.
-//
-// Microsoft LSP Docs:
-// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_cancel
-type CancelResponse struct {
-	RPC string `json:"jsonrpc"`
-	ID  int    `json:"id"`
-}
-
 // ParseCancelParams parses the CancelParams and returns the ID as an int.
 func ParseCancelParams(params protocol.CancelParams) (int, error) {
 	id := params.ID
@@ -39,11 +30,6 @@ func ParseCancelParams(params protocol.CancelParams) (int, error) {
 	return 0, fmt.Errorf("invalid id type: %T", id)
 }
 
-// Method returns the method for the cancel response
-func (r CancelResponse) Method() methods.Method {
-	return methods.MethodCancelRequest
-}
-
 // TextDocumentCodeActionResponse is the response for a code action request.
 type TextDocumentCodeActionResponse struct {
 	// TextDocumentCodeActionResponse embeds the Response struct
@@ -54,20 +40,21 @@ type TextDocumentCodeActionResponse struct {
 
 // Method returns the method for the code action response
 func (r TextDocumentCodeActionResponse) Method() methods.Method {
-	return methods.MethodRequestTextDocumentCodeAction
+	return methods.MethodTextDocumentCodeAction
 }
 
 // HoverResponse is the response from the server to a hover request.
 type HoverResponse struct {
 	// Response is the response for the hover request.
 	Response
-	// Result is the result for the hover request.
-	Result HoverResult `json:"result"`
+	// Result is the result for the hover request, null when there is
+	// nothing to show at the position.
+	Result *HoverResult `json:"result"`
 }
 
 // Method returns the method for the hover response
 func (r HoverResponse) Method() methods.Method {
-	return methods.MethodRequestTextDocumentHover
+	return methods.MethodTextDocumentHover
 }
 
 // HoverResult is a result from a hover request to the client from the
@@ -76,8 +63,9 @@ func (r HoverResponse) Method() methods.Method {
 // Microsoft LSP Docs:
 // https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_hover
 type HoverResult struct {
-	// Contents is the contents for the hover result.
-	Contents string `json:"contents"`
+	// Contents is the contents for the hover result, formatted in the
+	// markup kind supported by the client.
+	Contents protocol.MarkupContent `json:"contents"`
 }
 
 // InitializeResponse is a struct for the initialize response.
@@ -88,7 +76,7 @@ type HoverResult struct {
 type InitializeResponse struct {
 	Response
 	// Result is the result of the initialize request
-	Result protocol.InitializeResult `json:"result"`
+	Result InitializeResult `json:"result"`
 }
 
 // Method returns the method for the initialize response
@@ -97,56 +85,82 @@ func (r InitializeResponse) Method() methods.Method {
 }
 
 // NewInitializeResponse creates a new initialize response.
+//
+// The version is the version of the server build. The position encoding is
+// always UTF-16, which every client supports, as the server counts the
+// characters of every position it reads or sends in UTF-16 code units,
+// whatever other encoding the client offers.
 func NewInitializeResponse(
 	request *InitializeRequest,
+	version string,
 ) *InitializeResponse {
 	return &InitializeResponse{
 		Response: Response{
 			RPC: RPCVersion,
 			ID:  request.ID,
 		},
-		Result: protocol.InitializeResult{
-			Capabilities: protocol.ServerCapabilities{
-				TextDocumentSync: protocol.TextDocumentSyncOptions{
-					OpenClose: true,
-					Change:    protocol.TextDocumentSyncKindFull,
-					WillSave:  true,
-					Save: &protocol.SaveOptions{
-						IncludeText: true,
+		Result: InitializeResult{
+			Capabilities: ServerCapabilities{
+				PositionEncoding:  PositionEncodingUTF16,
+				InlayHintProvider: true,
+				ServerCapabilities: protocol.ServerCapabilities{
+					TextDocumentSync: protocol.TextDocumentSyncOptions{
+						OpenClose:         true,
+						Change:            protocol.TextDocumentSyncKindFull,
+						WillSave:          true,
+						WillSaveWaitUntil: true,
+						Save: &protocol.SaveOptions{
+							IncludeText: true,
+						},
+					},
+					CompletionProvider: &protocol.CompletionOptions{
+						ResolveProvider:   true,
+						TriggerCharacters: []string{"/", ".", `"`},
 					},
+					HoverProvider: true,
+					SignatureHelpProvider: &protocol.SignatureHelpOptions{
+						TriggerCharacters: []string{" "},
+					},
+					DeclarationProvider:       false,
+					DefinitionProvider:        false,
+					TypeDefinitionProvider:    false,
+					ImplementationProvider:    false,
+					ReferencesProvider:        false,
+					DocumentHighlightProvider: true,
+					DocumentSymbolProvider:    false,
+					CodeActionProvider: &protocol.CodeActionOptions{
+						CodeActionKinds: []protocol.CodeActionKind{
+							protocol.QuickFix,
+							protocol.RefactorRewrite,
+							protocol.Source,
+						},
+					},
+					ColorProvider:                   false,
+					WorkspaceSymbolProvider:         false,
+					DocumentFormattingProvider:      true,
+					DocumentRangeFormattingProvider: false,
+					RenameProvider: &protocol.RenameOptions{
+						PrepareProvider: true,
+					},
+					FoldingRangeProvider:             true,
+					SelectionRangeProvider:           true,
+					CallHierarchyProvider:            false,
+					LinkedEditingRangeProvider:       false,
+					SemanticTokensProvider:           false,
+					MonikerProvider:                  false,
+					Experimental:                     false,
+					CodeLensProvider:                 nil,
+					DocumentLinkProvider:             nil,
+					DocumentOnTypeFormattingProvider: nil,
+					ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
+						Commands: Commands(),
+					},
+					Workspace: nil,
 				},
-				CompletionProvider:               &protocol.CompletionOptions{},
-				HoverProvider:                    true,
-				SignatureHelpProvider:            &protocol.SignatureHelpOptions{},
-				DeclarationProvider:              false,
-				DefinitionProvider:               true,
-				TypeDefinitionProvider:           false,
-				ImplementationProvider:           false,
-				ReferencesProvider:               false,
-				DocumentHighlightProvider:        false,
-				DocumentSymbolProvider:           false,
-				CodeActionProvider:               false,
-				ColorProvider:                    false,
-				WorkspaceSymbolProvider:          false,
-				DocumentFormattingProvider:       false,
-				DocumentRangeFormattingProvider:  false,
-				RenameProvider:                   false,
-				FoldingRangeProvider:             false,
-				SelectionRangeProvider:           false,
-				CallHierarchyProvider:            false,
-				LinkedEditingRangeProvider:       false,
-				SemanticTokensProvider:           false,
-				MonikerProvider:                  false,
-				Experimental:                     false,
-				CodeLensProvider:                 nil,
-				DocumentLinkProvider:             nil,
-				DocumentOnTypeFormattingProvider: nil,
-				ExecuteCommandProvider:           nil,
-				Workspace:                        nil,
 			},
 			ServerInfo: &protocol.ServerInfo{
 				Name:    "embedpls",
-				Version: "0.1.0.5.0.0-beta1.final",
+				Version: version,
 			},
 		},
 	}
@@ -154,12 +168,14 @@ func NewInitializeResponse(
 
 // ShutdownResponse is the response to a ShutdownRequest.
 //
+// A failed shutdown is answered with an ErrorResponse instead.
+//
 // Microsoft LSP Docs:
 // https://microsoft.github.io/language-server-protocol/specifications/specification-current/#shutdown
 type ShutdownResponse struct {
 	Response
-	Result *bool  `json:"result"`
-	Error  *error `json:"error,omitempty"`
+	// Result is always null, as shutdown has no result.
+	Result *struct{} `json:"result"`
 }
 
 // Method returns the method for the shutdown response
@@ -167,19 +183,45 @@ func (r ShutdownResponse) Method() methods.Method {
 	return methods.MethodShutdown
 }
 
-// NewShutdownResponse creates a new shutdown response
-func NewShutdownResponse(
-	request ShutdownRequest,
-	err error,
-) (ShutdownResponse, error) {
+// NewShutdownResponse creates the response to a successful shutdown.
+func NewShutdownResponse(request ShutdownRequest) ShutdownResponse {
 	return ShutdownResponse{
 		Response: Response{
 			RPC: RPCVersion,
 			ID:  request.ID,
 		},
-		Result: nil,
-		Error:  &err,
-	}, nil
+	}
+}
+
+// NullResponse is the response to a request whose handler has no result,
+// such as a hover away from any directive.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#responseMessage
+type NullResponse struct {
+	// RPC is the rpc version of the response.
+	RPC string `json:"jsonrpc"`
+	// ID is the id of the request, kept even when 0.
+	ID int `json:"id"`
+	// Result is always null.
+	Result *struct{} `json:"result"`
+	// method is the method of the request.
+	method methods.Method
+}
+
+// Method returns the method of the request
+func (r NullResponse) Method() methods.Method {
+	return r.method
+}
+
+// NewNullResponse creates the null response to the request of the given id
+// and method.
+func NewNullResponse(id int, method methods.Method) NullResponse {
+	return NullResponse{
+		RPC:    RPCVersion,
+		ID:     id,
+		method: method,
+	}
 }
 
 // LogMessageNotification is a notification for a log message.
@@ -190,5 +232,291 @@ type LogMessageNotification struct {
 
 // Method returns the method for the log message notification.
 func (r LogMessageNotification) Method() methods.Method {
-	return methods.NotificationMethodLogMessage
+	return methods.MethodWindowLogMessage
+}
+
+// ShowMessageNotification is a notification asking the client to show a
+// message to the user.
+type ShowMessageNotification struct {
+	Notification
+	Params protocol.ShowMessageParams `json:"params"`
+}
+
+// Method returns the method for the show message notification.
+func (r ShowMessageNotification) Method() methods.Method {
+	return methods.MethodWindowShowMessage
+}
+
+// ExecuteCommandResponse is the response for an execute command request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspace_executeCommand
+type ExecuteCommandResponse struct {
+	// ExecuteCommandResponse embeds the Response struct
+	Response
+	// Result is the result of the executed command.
+	Result interface{} `json:"result"`
+}
+
+// Method returns the method for the execute command response
+func (r ExecuteCommandResponse) Method() methods.Method {
+	return methods.MethodWorkspaceExecuteCommand
+}
+
+// DocumentHighlightResponse is the response for a document highlight
+// request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_documentHighlight
+type DocumentHighlightResponse struct {
+	// DocumentHighlightResponse embeds the Response struct
+	Response
+	// Result are the highlights for the requested position.
+	Result []protocol.DocumentHighlight `json:"result"`
+}
+
+// Method returns the method for the document highlight response
+func (r DocumentHighlightResponse) Method() methods.Method {
+	return methods.MethodTextDocumentDocumentHighlight
+}
+
+// FoldingRangeResponse is the response for a folding range request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_foldingRange
+type FoldingRangeResponse struct {
+	// FoldingRangeResponse embeds the Response struct
+	Response
+	// Result are the folding ranges of the document.
+	Result []protocol.FoldingRange `json:"result"`
+}
+
+// Method returns the method for the folding range response
+func (r FoldingRangeResponse) Method() methods.Method {
+	return methods.MethodTextDocumentFoldingRange
+}
+
+// SelectionRange is a selection range, which is contained by its parent.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#selectionRange
+type SelectionRange struct {
+	// Range is the range of this selection range.
+	Range protocol.Range `json:"range"`
+	// Parent is the parent selection range containing this range, if any.
+	Parent *SelectionRange `json:"parent,omitempty"`
+}
+
+// SelectionRangeResponse is the response for a selection range request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_selectionRange
+type SelectionRangeResponse struct {
+	// SelectionRangeResponse embeds the Response struct
+	Response
+	// Result are the selection ranges of the requested positions, in the
+	// same order.
+	Result []SelectionRange `json:"result"`
+}
+
+// Method returns the method for the selection range response
+func (r SelectionRangeResponse) Method() methods.Method {
+	return methods.MethodTextDocumentSelectionRange
+}
+
+// InlayHint is a hint rendered inline in the source.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#inlayHint
+type InlayHint struct {
+	// Position is the position of the hint.
+	Position protocol.Position `json:"position"`
+	// Label is the label of the hint.
+	Label string `json:"label"`
+	// PaddingLeft is whether to render padding before the hint.
+	PaddingLeft bool `json:"paddingLeft,omitempty"`
+}
+
+// InlayHintResponse is the response for an inlay hint request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_inlayHint
+type InlayHintResponse struct {
+	// InlayHintResponse embeds the Response struct
+	Response
+	// Result are the inlay hints of the requested range.
+	Result []InlayHint `json:"result"`
+}
+
+// Method returns the method for the inlay hint response
+func (r InlayHintResponse) Method() methods.Method {
+	return methods.MethodTextDocumentInlayHint
+}
+
+// RenameResponse is the response for a rename request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_rename
+type RenameResponse struct {
+	// RenameResponse embeds the Response struct
+	Response
+	// Result is the edit performing the rename, if any.
+	Result *WorkspaceEdit `json:"result"`
+}
+
+// Method returns the method for the rename response
+func (r RenameResponse) Method() methods.Method {
+	return methods.MethodTextDocumentRename
+}
+
+// PrepareRenameResponse is the response for a prepare rename request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_prepareRename
+type PrepareRenameResponse struct {
+	// PrepareRenameResponse embeds the Response struct
+	Response
+	// Result is the range of the string to rename, or nil when the
+	// position cannot be renamed.
+	Result *protocol.Range `json:"result"`
+}
+
+// Method returns the method for the prepare rename response
+func (r PrepareRenameResponse) Method() methods.Method {
+	return methods.MethodTextDocumentPrepareRename
+}
+
+// SignatureHelpResponse is the response for a signature help request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_signatureHelp
+type SignatureHelpResponse struct {
+	// SignatureHelpResponse embeds the Response struct
+	Response
+	// Result is the signature help, or nil when there is none.
+	Result *protocol.SignatureHelp `json:"result"`
+}
+
+// Method returns the method for the signature help response
+func (r SignatureHelpResponse) Method() methods.Method {
+	return methods.MethodTextDocumentSignatureHelp
+}
+
+// WorkspaceEdit represents changes to many resources managed in the
+// workspace.
+//
+// Unlike protocol.WorkspaceEdit its document changes may mix text document
+// edits with resource operations such as file renames, which are applied
+// in order.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#workspaceEdit
+type WorkspaceEdit struct {
+	// DocumentChanges are protocol.TextDocumentEdit, protocol.CreateFile,
+	// protocol.RenameFile or protocol.DeleteFile operations.
+	DocumentChanges []interface{} `json:"documentChanges"`
+}
+
+// DocumentFormattingResponse is the response for a formatting request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_formatting
+type DocumentFormattingResponse struct {
+	// DocumentFormattingResponse embeds the Response struct
+	Response
+	// Result are the edits formatting the document, empty when it is
+	// already formatted.
+	Result []protocol.TextEdit `json:"result"`
+}
+
+// Method returns the method for the formatting response
+func (r DocumentFormattingResponse) Method() methods.Method {
+	return methods.MethodTextDocumentFormatting
+}
+
+// WillSaveWaitUntilResponse is the response for a will save wait until
+// request.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#textDocument_willSaveWaitUntil
+type WillSaveWaitUntilResponse struct {
+	// WillSaveWaitUntilResponse embeds the Response struct
+	Response
+	// Result are the edits applied to the document before it is saved.
+	Result []protocol.TextEdit `json:"result"`
+}
+
+// Method returns the method for the will save wait until response
+func (r WillSaveWaitUntilResponse) Method() methods.Method {
+	return methods.MethodTextDocumentWillSaveWaitUntil
+}
+
+// ErrorResponse is the response to a request that failed.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#responseMessage
+type ErrorResponse struct {
+	// ErrorResponse embeds the Response struct
+	Response
+	// Error is the error the request failed with.
+	Error Error `json:"error"`
+	// method is the method of the failed request.
+	method methods.Method
+}
+
+// Method returns the method of the failed request
+func (r ErrorResponse) Method() methods.Method {
+	return r.method
+}
+
+// ParseErrorResponse is the response to a message whose content is not
+// valid JSON, whose id is unknown and so null.
+//
+// Microsoft LSP Docs:
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/#responseMessage
+type ParseErrorResponse struct {
+	// RPC is the rpc version of the response.
+	RPC string `json:"jsonrpc"`
+	// ID is always null.
+	ID *int `json:"id"`
+	// Error is the parse error.
+	Error Error `json:"error"`
+}
+
+// Method returns no method, as the method of the message is unknown
+func (r ParseErrorResponse) Method() methods.Method {
+	return ""
+}
+
+// NewParseErrorResponse creates the response to a message whose content
+// failed to parse with the given message.
+func NewParseErrorResponse(message string) ParseErrorResponse {
+	return ParseErrorResponse{
+		RPC: RPCVersion,
+		Error: Error{
+			Code:    int(CodeParseError),
+			Message: message,
+		},
+	}
+}
+
+// NewErrorResponse creates the response to the request of the given id and
+// method failing with the given code and message.
+func NewErrorResponse(
+	id int,
+	method methods.Method,
+	code int,
+	message string,
+) ErrorResponse {
+	return ErrorResponse{
+		Response: Response{
+			RPC: RPCVersion,
+			ID:  id,
+		},
+		Error: Error{
+			Code:    code,
+			Message: message,
+		},
+		method: method,
+	}
 }
@@ -0,0 +1,22 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestShutdownResponseJSON tests that a successful shutdown is answered
+// with a null result and no error.
+func TestShutdownResponseJSON(t *testing.T) {
+	encoded, err := json.Marshal(NewShutdownResponse(ShutdownRequest{
+		Request: Request{ID: 4},
+	}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":4,"result":null}`, string(encoded))
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	assert.Equal(t, "null", string(fields["result"]))
+	assert.NotContains(t, fields, "error")
+}
@@ -0,0 +1,170 @@
+package parsers
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.lsp.dev/protocol"
+)
+
+// EmbedBlock is a variable declaration together with the go:embed
+// directives preceding it.
+type EmbedBlock struct {
+	// Directives are the directives embedding files into the variable.
+	Directives []Directive
+	// Var is the name of the variable.
+	Var string
+	// Type is the type of the variable as written in the source, such as
+	// "embed.FS", "string" or "[]byte".
+	Type string
+	// Line is the zero-based line of the variable declaration.
+	Line int
+	// Start is the byte offset of the start of the variable name in its
+	// line.
+	Start int
+	// End is the byte offset of the end of the variable name in its line.
+	End int
+}
+
+// IsFS reports whether the variable is an embed.FS, whose files are read
+// back by their path, allowing the embed package to be imported under
+// another name.
+func (b EmbedBlock) IsFS() bool {
+	return strings.HasSuffix(b.Type, ".FS")
+}
+
+// Patterns returns the patterns of every directive of the block.
+func (b EmbedBlock) Patterns() []string {
+	patterns := make([]string, 0)
+	for _, directive := range b.Directives {
+		for _, pattern := range directive.Patterns {
+			patterns = append(patterns, pattern.Value)
+		}
+	}
+	return patterns
+}
+
+// Contiguous reports whether the directives of the block are on
+// consecutive lines, with no blank line or comment between them.
+func (b EmbedBlock) Contiguous() bool {
+	for i := 1; i < len(b.Directives); i++ {
+		if b.Directives[i].Line != b.Directives[i-1].Line+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseEmbedBlocks parses the variables of a source string targeted by
+// go:embed directives.
+//
+// As required by the go command, only blank lines and line comments may
+// separate the directives from the declaration of their variable, which
+// may be part of a parenthesized var group. Directives not followed by a
+// variable declaration are dropped, and diagnosed as misplaced by Diagnose.
+func ParseEmbedBlocks(source string) []EmbedBlock {
+	return parseEmbedBlocks(strings.Split(source, "\n"), ParseDirectives(source))
+}
+
+// parseEmbedBlocks parses the variables targeted by the already parsed
+// directives of the lines of a source.
+func parseEmbedBlocks(lines []string, parsed []Directive) []EmbedBlock {
+	directives := make(map[int]Directive)
+	for _, directive := range parsed {
+		directives[directive.Line] = directive
+	}
+	blocks := make([]EmbedBlock, 0)
+	pending := make([]Directive, 0)
+	inGroup := false
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if directive, ok := directives[i]; ok {
+			pending = append(pending, directive)
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		spec := ""
+		switch {
+		case inGroup && strings.HasPrefix(trimmed, ")"):
+			inGroup = false
+		case strings.HasPrefix(trimmed, "var") &&
+			strings.TrimSpace(trimmed[len("var"):]) == "(":
+			inGroup = true
+		case hasKeyword(trimmed, "var"):
+			spec = strings.TrimLeft(trimmed[len("var"):], " \t")
+		case inGroup:
+			spec = trimmed
+		}
+		if spec != "" && len(pending) > 0 {
+			name, typ := splitVarSpec(spec)
+			if name != "" {
+				start := len(strings.TrimRight(line, " \t")) - len(spec)
+				blocks = append(blocks, EmbedBlock{
+					Directives: pending,
+					Var:        name,
+					Type:       typ,
+					Line:       i,
+					Start:      start,
+					End:        start + len(name),
+				})
+			}
+		}
+		pending = make([]Directive, 0)
+	}
+	return blocks
+}
+
+// EmbedBlockAt returns the block whose variable name is under the given
+// position of a source.
+//
+// The character of the position is counted in UTF-16 code units.
+func EmbedBlockAt(
+	source string,
+	position protocol.Position,
+) (EmbedBlock, bool) {
+	return NewIndex(source).EmbedBlockAt(position)
+}
+
+// hasKeyword reports whether a trimmed line starts with the given keyword
+// followed by whitespace.
+func hasKeyword(line, keyword string) bool {
+	if !strings.HasPrefix(line, keyword) || len(line) == len(keyword) {
+		return false
+	}
+	return line[len(keyword)] == ' ' || line[len(keyword)] == '\t'
+}
+
+// splitVarSpec splits a variable specification into the name of its
+// single variable and its type.
+//
+// An empty name is returned when the specification does not start with an
+// identifier or declares more than one variable.
+func splitVarSpec(spec string) (string, string) {
+	end := 0
+	for end < len(spec) {
+		r, size := utf8.DecodeRuneInString(spec[end:])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		end += size
+	}
+	name := spec[:end]
+	if name == "" {
+		return "", ""
+	}
+	typ := strings.TrimLeft(spec[end:], " \t")
+	if strings.HasPrefix(typ, ",") {
+		return "", ""
+	}
+	if i := strings.Index(typ, "//"); i >= 0 {
+		typ = typ[:i]
+	}
+	if i := strings.Index(typ, "="); i >= 0 {
+		typ = typ[:i]
+	}
+	return name, strings.TrimSpace(typ)
+}
@@ -0,0 +1,74 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestParseEmbedBlocks tests associating directives with their target
+// variable.
+func TestParseEmbedBlocks(t *testing.T) {
+	source := "package main\n\n" +
+		"import \"embed\"\n\n" +
+		"//go:embed static/*\n" +
+		"// a comment between the directives\n" +
+		"//go:embed templates\n" +
+		"\n" +
+		"var content embed.FS\n\n" +
+		"var (\n" +
+		"\t//go:embed version.txt\n" +
+		"\tversion string // the version\n" +
+		"\n" +
+		"\t//go:embed logo.png\n" +
+		"\tlogo []byte\n" +
+		")\n\n" +
+		"//go:embed dangling.txt\n" +
+		"func main() {}\n"
+	blocks := ParseEmbedBlocks(source)
+	if !assert.Len(t, blocks, 3) {
+		return
+	}
+
+	assert.Equal(t, "content", blocks[0].Var)
+	assert.Equal(t, "embed.FS", blocks[0].Type)
+	assert.Equal(t, []string{"static/*", "templates"}, blocks[0].Patterns())
+	assert.Equal(t, 4, blocks[0].Start)
+	assert.Equal(t, 11, blocks[0].End)
+
+	assert.Equal(t, "version", blocks[1].Var)
+	assert.Equal(t, "string", blocks[1].Type)
+	assert.Equal(t, []string{"version.txt"}, blocks[1].Patterns())
+	assert.Equal(t, 1, blocks[1].Start)
+
+	assert.Equal(t, "logo", blocks[2].Var)
+	assert.Equal(t, "[]byte", blocks[2].Type)
+	assert.Equal(t, []string{"logo.png"}, blocks[2].Patterns())
+}
+
+// TestEmbedBlockAt tests finding the block of the variable under a
+// position.
+func TestEmbedBlockAt(t *testing.T) {
+	source := "//go:embed a.txt\nvar a string\n"
+	tests := []struct {
+		name     string
+		position protocol.Position
+		want     bool
+	}{
+		{name: "on the name", position: protocol.Position{Line: 1, Character: 4}, want: true},
+		{name: "end of the name", position: protocol.Position{Line: 1, Character: 5}, want: true},
+		{name: "on the type", position: protocol.Position{Line: 1, Character: 8}},
+		{name: "on the directive", position: protocol.Position{Line: 0, Character: 12}},
+		{name: "past the end", position: protocol.Position{Line: 5, Character: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, ok := EmbedBlockAt(source, tt.position)
+			assert.Equal(t, tt.want, ok)
+			if ok {
+				assert.Equal(t, "a", block.Var)
+			}
+		})
+	}
+}
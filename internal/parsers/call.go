@@ -0,0 +1,71 @@
+package parsers
+
+import (
+	"regexp"
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+// readFileCallRegex matches the line up to a position in the string
+// literal argument of a ReadFile call, either fs.ReadFile(content, "...")
+// or content.ReadFile("..."), capturing the file system in either form and
+// the path typed so far.
+var readFileCallRegex = regexp.MustCompile(
+	`(?:\bfs\.ReadFile\(\s*(\w+)\s*,|\b(\w+)\.ReadFile\()\s*"([^"\\]*)$`,
+)
+
+// ReadFileCall is the string literal path argument of a call reading a
+// file of a file system, such as fs.ReadFile(content, "static/app.js").
+type ReadFileCall struct {
+	// FS is the name of the file system variable read from.
+	FS string
+	// Path is the content of the string literal up to the position.
+	Path string
+	// Range is the range of the content of the string literal, without
+	// its quotes, with its characters counted in UTF-16 code units.
+	Range protocol.Range
+}
+
+// ParseReadFileCall returns the ReadFile call whose string literal path
+// holds the position.
+//
+// Only calls whose path is on the line of the position and has no escape
+// sequence are found, which covers the paths being typed. The file system
+// may be any variable: it is up to the caller to check it is an embed.FS.
+//
+// The character of the position is counted in UTF-16 code units.
+func ParseReadFileCall(source string, position protocol.Position) (ReadFileCall, bool) {
+	lines := strings.Split(source, "\n")
+	if int(position.Line) >= len(lines) {
+		return ReadFileCall{}, false
+	}
+	line := strings.TrimSuffix(lines[position.Line], "\r")
+	cursor := utf16OffsetToByte(line, int(position.Character))
+	match := readFileCallRegex.FindStringSubmatchIndex(line[:cursor])
+	if match == nil {
+		return ReadFileCall{}, false
+	}
+	fsStart, fsEnd := match[2], match[3]
+	if fsStart < 0 {
+		fsStart, fsEnd = match[4], match[5]
+	}
+	start := match[6]
+	// the rest of the path after the position is replaced along, up to
+	// the closing quote when already typed
+	end := cursor + strings.IndexAny(line[cursor:]+`"`, `"\`)
+	return ReadFileCall{
+		FS:   line[fsStart:fsEnd],
+		Path: line[start:cursor],
+		Range: protocol.Range{
+			Start: protocol.Position{
+				Line:      position.Line,
+				Character: uint32(byteToUTF16Offset(line, start)),
+			},
+			End: protocol.Position{
+				Line:      position.Line,
+				Character: uint32(byteToUTF16Offset(line, end)),
+			},
+		},
+	}, true
+}
@@ -0,0 +1,72 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestParseReadFileCall tests finding the ReadFile call whose path holds a
+// position.
+func TestParseReadFileCall(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		cursor uint32
+		want   ReadFileCall
+		wantOk bool
+	}{
+		{
+			name:   "fs.ReadFile",
+			line:   `	data, err := fs.ReadFile(content, "st`,
+			cursor: 38,
+			want:   ReadFileCall{FS: "content", Path: "st", Range: callRange(36, 38)},
+			wantOk: true,
+		},
+		{
+			name:   "method",
+			line:   `	data, err := content.ReadFile("static/app.js")`,
+			cursor: 39,
+			want:   ReadFileCall{FS: "content", Path: "static/", Range: callRange(32, 45)},
+			wantOk: true,
+		},
+		{
+			name:   "empty path",
+			line:   `fs.ReadFile(assets,"")`,
+			cursor: 20,
+			want:   ReadFileCall{FS: "assets", Path: "", Range: callRange(20, 20)},
+			wantOk: true,
+		},
+		{
+			name:   "multi-byte characters",
+			line:   `fs.ReadFile(content, "ü/a`,
+			cursor: 25,
+			want:   ReadFileCall{FS: "content", Path: "ü/a", Range: callRange(22, 25)},
+			wantOk: true,
+		},
+		{name: "before the quote", line: `fs.ReadFile(content, "a")`, cursor: 21},
+		{name: "after the literal", line: `fs.ReadFile(content, "a")`, cursor: 25},
+		{name: "other function", line: `fs.Glob(content, "a`, cursor: 19},
+		{name: "escape sequence", line: `fs.ReadFile(content, "a\tb`, cursor: 26},
+		{name: "not a call", line: `x := "ReadFile(content, "`, cursor: 26},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := "package main\n\n" + tt.line + "\n"
+			got, ok := ParseReadFileCall(source, protocol.Position{Line: 2, Character: tt.cursor})
+			if ok != tt.wantOk {
+				t.Fatalf("ParseReadFileCall() ok = %v, want %v", ok, tt.wantOk)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// callRange returns the range between two characters of the third line.
+func callRange(start, end uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: 2, Character: start},
+		End:   protocol.Position{Line: 2, Character: end},
+	}
+}
@@ -0,0 +1,77 @@
+package parsers
+
+import (
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+// ParseBlockCommentDirectives returns the ranges of the go:embed directives
+// written in block comments, such as "/* go:embed a.txt */", from the
+// opening of the comment to the end of "go:embed".
+//
+// Go only honors directives in line comments, so these embed nothing. The
+// directive may start on a later line than the opening of the comment.
+// String and rune literals and line comments are skipped.
+func ParseBlockCommentDirectives(source string) []protocol.Range {
+	ranges := make([]protocol.Range, 0)
+	var positions *LineIndex
+	for i := 0; i < len(source); i++ {
+		switch {
+		case strings.HasPrefix(source[i:], "//"):
+			for i+1 < len(source) && source[i+1] != '\n' {
+				i++
+			}
+		case source[i] == '"' || source[i] == '\'' || source[i] == '`':
+			i = skipLiteral(source, i)
+		case strings.HasPrefix(source[i:], "/*"):
+			comment := source[i+len("/*"):]
+			end := strings.Index(comment, "*/")
+			closed := end >= 0
+			if closed {
+				comment = comment[:end]
+			}
+			body := strings.TrimLeft(comment, " \t\r\n")
+			if isEmbedWord(body) {
+				directiveEnd := i + len("/*") + len(comment) - len(body) + len("go:embed")
+				if positions == nil {
+					positions = NewLineIndex(source)
+				}
+				ranges = append(ranges, protocol.Range{
+					Start: positions.Position(i),
+					End:   positions.Position(directiveEnd),
+				})
+			}
+			i += len("/*") + len(comment) - 1
+			if closed {
+				i += len("*/")
+			}
+		}
+	}
+	return ranges
+}
+
+// isEmbedWord reports whether a comment body starts with the go:embed
+// word, followed by whitespace or nothing.
+func isEmbedWord(body string) bool {
+	rest, ok := strings.CutPrefix(body, "go:embed")
+	return ok && (rest == "" || strings.ContainsRune(" \t\r\n", rune(rest[0])))
+}
+
+// skipLiteral returns the offset of the closing quote of the string or rune
+// literal opened at the given offset of a source, or of the last character
+// of the line when an interpreted literal is not closed.
+func skipLiteral(source string, open int) int {
+	quote := source[open]
+	for i := open + 1; i < len(source); i++ {
+		switch {
+		case source[i] == quote:
+			return i
+		case source[i] == '\\' && quote != '`':
+			i++
+		case source[i] == '\n' && quote != '`':
+			return i - 1
+		}
+	}
+	return len(source) - 1
+}
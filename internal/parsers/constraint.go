@@ -0,0 +1,63 @@
+package parsers
+
+import (
+	"go/build/constraint"
+	"strings"
+)
+
+// BuildConstraint is the //go:build constraint of a file, which gates its
+// go:embed directives along with the rest of the file.
+type BuildConstraint struct {
+	// Expr is the expression of the constraint.
+	Expr constraint.Expr
+	// Line is the zero-based line of the constraint.
+	Line int
+}
+
+// String returns the expression of the constraint as written after
+// //go:build.
+func (c BuildConstraint) String() string {
+	return c.Expr.String()
+}
+
+// Ignored reports whether the constraint is the "ignore" tag, which by
+// convention excludes the file from every build.
+func (c BuildConstraint) Ignored() bool {
+	tag, ok := c.Expr.(*constraint.TagExpr)
+	return ok && tag.Tag == "ignore"
+}
+
+// Note returns the note telling how the constraint gates the directives
+// of its file.
+func (c BuildConstraint) Note() string {
+	if c.Ignored() {
+		return "excluded from builds by //go:build ignore unless the file is named on the command line"
+	}
+	return "gated by //go:build " + c.String()
+}
+
+// ParseBuildConstraint returns the //go:build constraint of a source,
+// reporting false when it has none.
+//
+// As for the go command, only the line comments before the package clause
+// are considered, and a constraint failing to parse is none.
+func ParseBuildConstraint(source string) (BuildConstraint, bool) {
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			return BuildConstraint{}, false
+		}
+		if !constraint.IsGoBuild(line) {
+			continue
+		}
+		expr, err := constraint.Parse(line)
+		if err != nil {
+			return BuildConstraint{}, false
+		}
+		return BuildConstraint{Expr: expr, Line: i}, true
+	}
+	return BuildConstraint{}, false
+}
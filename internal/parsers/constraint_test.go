@@ -0,0 +1,72 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseBuildConstraint tests finding the build constraint of a file
+// among the comments before its package clause.
+func TestParseBuildConstraint(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		want        string
+		wantLine    int
+		wantIgnored bool
+		wantOK      bool
+	}{
+		{
+			name:     "constraint",
+			source:   "//go:build linux && amd64\n\npackage main\n",
+			want:     "linux && amd64",
+			wantOK:   true,
+			wantLine: 0,
+		},
+		{
+			name:     "after comments",
+			source:   "// Copyright notice.\n\n//go:build !windows\r\n\npackage main\n",
+			want:     "!windows",
+			wantOK:   true,
+			wantLine: 2,
+		},
+		{
+			name:        "ignore",
+			source:      "//go:build ignore\n\npackage main\n",
+			want:        "ignore",
+			wantOK:      true,
+			wantIgnored: true,
+		},
+		{
+			name:   "ignore among other tags",
+			source: "//go:build ignore || tools\n\npackage main\n",
+			want:   "ignore || tools",
+			wantOK: true,
+		},
+		{
+			name:   "after the package clause",
+			source: "package main\n\n//go:build linux\n",
+		},
+		{
+			name:   "malformed",
+			source: "//go:build linux &&\n\npackage main\n",
+		},
+		{
+			name:   "none",
+			source: "package main\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseBuildConstraint(tt.source)
+			assert.Equal(t, tt.wantOK, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.wantLine, got.Line)
+			assert.Equal(t, tt.wantIgnored, got.Ignored())
+		})
+	}
+}
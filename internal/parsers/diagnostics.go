@@ -0,0 +1,250 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+const (
+	// DiagnosticSource is the source reported on every diagnostic.
+	DiagnosticSource = "embedpls"
+)
+
+// Codes of the diagnostics, stable across releases so that tools can
+// filter and suppress the findings of specific rules.
+const (
+	// CodeInvalidPattern is the code of malformed patterns.
+	CodeInvalidPattern = "embed/invalid-pattern"
+	// CodeAbsolutePath is the code of absolute patterns.
+	CodeAbsolutePath = "embed/absolute-path"
+	// CodePathTraversal is the code of patterns with a ".." element.
+	CodePathTraversal = "embed/path-traversal"
+	// CodeSpaceAfterSlashes is the code of directives spelled with a space
+	// after their slashes.
+	CodeSpaceAfterSlashes = "embed/space-after-slashes"
+	// CodeBlockComment is the code of directives in block comments.
+	CodeBlockComment = "embed/block-comment"
+	// CodeDuplicatePath is the code of patterns embedding a file at the
+	// same path of an embed.FS as another pattern.
+	CodeDuplicatePath = "embed/duplicate-path"
+	// CodeNestedModule is the code of patterns matching directories that
+	// hold a nested module, whose files are not embedded.
+	CodeNestedModule = "embed/nested-module"
+	// CodeSymlink is the code of patterns matching symbolic links, which
+	// cannot be embedded.
+	CodeSymlink = "embed/symlink"
+	// CodeCaseMismatch is the code of patterns spelled with another case
+	// than the names on disk.
+	CodeCaseMismatch = "embed/case-mismatch"
+	// CodeNoMatch is the code of the patterns of a string or []byte
+	// variable matching no file.
+	CodeNoMatch = "embed/no-match"
+	// CodeMultipleFiles is the code of the patterns of a string or []byte
+	// variable embedding more than one file.
+	CodeMultipleFiles = "embed/multiple-files"
+	// CodeMisplacedDirective is the code of directives not immediately
+	// preceding the declaration of a single variable.
+	CodeMisplacedDirective = "embed/misplaced-directive"
+	// CodeExportedFS is the code of the optional lint of exported embed.FS
+	// variables.
+	CodeExportedFS = "embed/exported-fs"
+)
+
+// DiagnosticData is the data attached to the diagnostics of a pattern.
+type DiagnosticData struct {
+	// Pattern is the offending pattern.
+	Pattern string `json:"pattern"`
+}
+
+var (
+	// errAbsolutePath is returned for patterns that are not relative.
+	errAbsolutePath = errors.New(
+		"absolute paths cannot be embedded, patterns must be relative to the package directory",
+	)
+	// errCurrentDir is returned for patterns containing a "." element.
+	errCurrentDir = errors.New("'.' path elements are not allowed by the go command")
+	// errPathTraversal is returned for patterns containing a ".." element.
+	errPathTraversal = errors.New(
+		"'..' path elements are not allowed, embedded files must be in the package directory or below",
+	)
+)
+
+// patternCheck is a check run against every pattern of a directive.
+type patternCheck struct {
+	// code is the code of the diagnostics of the check.
+	code string
+	// check returns the error of an offending pattern.
+	check func(pattern string) error
+}
+
+// patternChecks are the checks run against every pattern of a directive.
+var patternChecks = []patternCheck{
+	{code: CodeInvalidPattern, check: validatePattern},
+	{code: CodeAbsolutePath, check: checkAbsolutePath},
+	{code: CodePathTraversal, check: checkPathTraversal},
+	{code: CodeInvalidPattern, check: checkCurrentDir},
+}
+
+// Diagnose returns the diagnostics for the go:embed directives of a source.
+//
+// The returned slice is never nil so that it can be published as-is to
+// clear previously reported diagnostics.
+func Diagnose(source string) []protocol.Diagnostic {
+	return NewIndex(source).Diagnose()
+}
+
+// Diagnose returns the diagnostics of Diagnose for the source of the
+// index, without parsing its directives again.
+func (x *Index) Diagnose() []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	for _, directive := range x.Directives {
+		for _, pattern := range directive.Patterns {
+			for _, check := range patternChecks {
+				err := check.check(pattern.Glob)
+				if err == nil {
+					continue
+				}
+				diagnostics = append(diagnostics, newPatternDiagnostic(
+					directive,
+					pattern,
+					check.code,
+					fmt.Sprintf("invalid pattern %q: %s", pattern.Value, err),
+				))
+			}
+		}
+	}
+	for _, directive := range x.Misplaced() {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    x.DirectiveRange(directive),
+			Severity: protocol.DiagnosticSeverityError,
+			Code:     CodeMisplacedDirective,
+			Source:   DiagnosticSource,
+			Message: "misplaced go:embed directive: it must precede the declaration " +
+				"of a single variable, with only blank lines and // comments between them",
+		})
+	}
+	for _, misspelled := range ParseMisspelledDirectives(x.source) {
+		diagnostics = append(diagnostics, misspelled.Diagnostic())
+	}
+	for _, blockRange := range ParseBlockCommentDirectives(x.source) {
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    blockRange,
+			Severity: protocol.DiagnosticSeverityWarning,
+			Code:     CodeBlockComment,
+			Source:   DiagnosticSource,
+			Message: "go:embed in a block comment embeds nothing: " +
+				"Go only honors //go:embed line comments directly above a variable",
+		})
+	}
+	return diagnostics
+}
+
+// DiagnoseExportedFS returns the warnings of the exported embed.FS
+// variables of a source.
+//
+// Embedded assets are usually an implementation detail of a package, so
+// exporting them lets other packages depend on its layout. This style lint
+// is optional: unlike Diagnose, nothing in it breaks the build.
+func DiagnoseExportedFS(source string) []protocol.Diagnostic {
+	return NewIndex(source).DiagnoseExportedFS()
+}
+
+// DiagnoseExportedFS returns the warnings of DiagnoseExportedFS for the
+// source of the index, without parsing its directives again.
+func (x *Index) DiagnoseExportedFS() []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	for _, block := range x.Blocks() {
+		if !block.IsFS() || !token.IsExported(block.Var) {
+			continue
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    x.VarRange(block),
+			Severity: protocol.DiagnosticSeverityWarning,
+			Code:     CodeExportedFS,
+			Source:   DiagnosticSource,
+			Message: fmt.Sprintf(
+				"embed.FS variable %s is exported: embedded assets are usually "+
+					"an implementation detail of their package",
+				block.Var,
+			),
+		})
+	}
+	return diagnostics
+}
+
+// newPatternDiagnostic creates an error diagnostic ranging over a pattern,
+// carrying the pattern in its data.
+func newPatternDiagnostic(
+	directive Directive,
+	pattern PatternToken,
+	code string,
+	message string,
+) protocol.Diagnostic {
+	return protocol.Diagnostic{
+		Range:    directive.Range(pattern),
+		Severity: protocol.DiagnosticSeverityError,
+		Code:     code,
+		Source:   DiagnosticSource,
+		Message:  message,
+		Data:     DiagnosticData{Pattern: pattern.Value},
+	}
+}
+
+// checkAbsolutePath checks that a pattern is not an absolute path on any
+// platform.
+func checkAbsolutePath(pattern string) error {
+	if len(pattern) > 0 && pattern[0] == '/' {
+		return errAbsolutePath
+	}
+	if hasDriveLetter(pattern) {
+		return errAbsolutePath
+	}
+	return nil
+}
+
+// checkPathTraversal checks that a pattern does not contain a ".." path
+// element.
+//
+// Only whole elements are rejected, so names merely containing dots such as
+// "file..txt" are allowed.
+func checkPathTraversal(pattern string) error {
+	for _, element := range strings.Split(pattern, "/") {
+		if element == ".." {
+			return errPathTraversal
+		}
+	}
+	return nil
+}
+
+// checkCurrentDir checks that a pattern does not contain a "." path
+// element, such as in "./hello.txt", suggesting the pattern without it.
+//
+// The features of the server resolve such a pattern as its cleaned form,
+// but the go command rejects it.
+func checkCurrentDir(pattern string) error {
+	for _, element := range strings.Split(pattern, "/") {
+		if element != "." {
+			continue
+		}
+		if clean := path.Clean(pattern); clean != "." && checkPathTraversal(clean) == nil {
+			return fmt.Errorf("%w, use %q", errCurrentDir, clean)
+		}
+		return errCurrentDir
+	}
+	return nil
+}
+
+// hasDriveLetter reports whether a pattern starts with a Windows drive
+// letter such as "C:".
+func hasDriveLetter(pattern string) bool {
+	if len(pattern) < 2 || pattern[1] != ':' {
+		return false
+	}
+	c := pattern[0]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
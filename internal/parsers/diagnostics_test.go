@@ -0,0 +1,469 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestDiagnoseInvalidPattern tests that malformed patterns are diagnosed
+// with a range covering only the offending pattern.
+func TestDiagnoseInvalidPattern(t *testing.T) {
+	source := "package main\n\n//go:embed ok.txt [a-\nvar content string\n"
+	diagnostics := Diagnose(source)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, protocol.Range{
+		Start: protocol.Position{Line: 2, Character: 18},
+		End:   protocol.Position{Line: 2, Character: 21},
+	}, diagnostics[0].Range)
+	assert.Equal(t, protocol.DiagnosticSeverityError, diagnostics[0].Severity)
+	assert.Equal(t, DiagnosticSource, diagnostics[0].Source)
+}
+
+// TestDiagnoseValidPatterns tests that valid patterns produce no
+// diagnostics.
+func TestDiagnoseValidPatterns(t *testing.T) {
+	source := "package main\n\n//go:embed a.txt static/*.html [ab].md\nvar content embed.FS\n"
+	diagnostics := Diagnose(source)
+	assert.NotNil(t, diagnostics)
+	assert.Empty(t, diagnostics)
+}
+
+// TestDiagnoseAbsolutePath tests that absolute patterns are diagnosed on
+// every platform with a range covering only the absolute path.
+func TestDiagnoseAbsolutePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{name: "unix root", pattern: "/etc/hosts", wantErr: true},
+		{name: "unix glob", pattern: "/tmp/*.txt", wantErr: true},
+		{name: "windows forward slash", pattern: "C:/Windows/win.ini", wantErr: true},
+		{name: "windows backslash", pattern: `C:\Windows\win.ini`, wantErr: true},
+		{name: "windows lower drive", pattern: "d:/data", wantErr: true},
+		{name: "relative", pattern: "etc/hosts", wantErr: false},
+		{name: "colon in name", pattern: "ab:c.txt", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, checkAbsolutePath(tt.pattern) != nil)
+			source := "//go:embed ok.txt " + tt.pattern + "\nvar f embed.FS\n"
+			var found []protocol.Diagnostic
+			for _, diagnostic := range Diagnose(source) {
+				if diagnostic.Range.Start.Character == 18 {
+					found = append(found, diagnostic)
+				}
+			}
+			if !tt.wantErr {
+				for _, diagnostic := range found {
+					assert.NotContains(t, diagnostic.Message, "absolute")
+				}
+				return
+			}
+			if assert.NotEmpty(t, found) {
+				assert.Equal(t, uint32(18+len(tt.pattern)), found[0].Range.End.Character)
+				assert.Contains(t, found[0].Message, "absolute")
+			}
+		})
+	}
+}
+
+// TestDiagnosePathTraversal tests that ".." path elements are diagnosed
+// without flagging names that merely contain dots.
+func TestDiagnosePathTraversal(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{name: "leading parent", pattern: "../x", wantErr: true},
+		{name: "inner parent", pattern: "a/../b", wantErr: true},
+		{name: "trailing parent", pattern: "a/..", wantErr: true},
+		{name: "bare parent", pattern: "..", wantErr: true},
+		{name: "double dot in name", pattern: "file..txt", wantErr: false},
+		{name: "dotted directory", pattern: "..hidden/x", wantErr: false},
+		{name: "triple dot", pattern: ".../x", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, checkPathTraversal(tt.pattern) != nil)
+			diagnostics := Diagnose("//go:embed " + tt.pattern + "\nvar f embed.FS\n")
+			if !tt.wantErr {
+				assert.Empty(t, diagnostics)
+				return
+			}
+			if assert.Len(t, diagnostics, 1) {
+				assert.Contains(t, diagnostics[0].Message, "'..'")
+				assert.Equal(t, uint32(11), diagnostics[0].Range.Start.Character)
+			}
+		})
+	}
+}
+
+// TestDiagnoseCurrentDir tests that "." path elements, which the go
+// command rejects, are diagnosed with the pattern to use instead.
+func TestDiagnoseCurrentDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{
+			name:    "leading",
+			pattern: "./hello.txt",
+			want:    `invalid pattern "./hello.txt": '.' path elements are not allowed by the go command, use "hello.txt"`,
+		},
+		{
+			name:    "inner",
+			pattern: "static/./app.js",
+			want:    `invalid pattern "static/./app.js": '.' path elements are not allowed by the go command, use "static/app.js"`,
+		},
+		{
+			name:    "all prefix",
+			pattern: "all:./static",
+			want:    `invalid pattern "all:./static": '.' path elements are not allowed by the go command, use "static"`,
+		},
+		{
+			name:    "bare",
+			pattern: ".",
+			want:    `invalid pattern ".": '.' path elements are not allowed by the go command`,
+		},
+		{name: "hidden file", pattern: ".hidden"},
+		{name: "dotted name", pattern: "a.b/c.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := Diagnose("//go:embed " + tt.pattern + "\nvar f embed.FS\n")
+			if tt.want == "" {
+				assert.Empty(t, diagnostics)
+				return
+			}
+			if assert.Len(t, diagnostics, 1) {
+				assert.Equal(t, CodeInvalidPattern, diagnostics[0].Code)
+				assert.Equal(t, tt.want, diagnostics[0].Message)
+			}
+		})
+	}
+}
+
+// TestDiagnoseAllPrefix tests that patterns are checked without their
+// "all:" prefix, which is no part of the embedded path.
+func TestDiagnoseAllPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		wantCode string
+	}{
+		{name: "directory", pattern: "all:static"},
+		{name: "parent", pattern: "all:../x", wantCode: CodePathTraversal},
+		{name: "absolute", pattern: "all:/etc", wantCode: CodeAbsolutePath},
+		{name: "drive letter", pattern: "all:C:/x", wantCode: CodeAbsolutePath},
+		{name: "invalid", pattern: "all:[", wantCode: CodeInvalidPattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := Diagnose("//go:embed " + tt.pattern + "\nvar f embed.FS\n")
+			if tt.wantCode == "" {
+				assert.Empty(t, diagnostics)
+				return
+			}
+			if assert.NotEmpty(t, diagnostics) {
+				assert.Equal(t, tt.wantCode, diagnostics[0].Code)
+				assert.Equal(t, uint32(11), diagnostics[0].Range.Start.Character)
+			}
+		})
+	}
+}
+
+// TestDiagnoseMisspelledDirective tests that directives with a space after
+// their slashes are warned about while canonical ones are not.
+func TestDiagnoseMisspelledDirective(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantRange *protocol.Range
+	}{
+		{name: "canonical", line: "//go:embed hello.txt"},
+		{
+			name: "space after slashes",
+			line: "// go:embed hello.txt",
+			wantRange: &protocol.Range{
+				Start: protocol.Position{Line: 2, Character: 0},
+				End:   protocol.Position{Line: 2, Character: 11},
+			},
+		},
+		{
+			name: "indented tab after slashes",
+			line: "\t//\tgo:embed hello.txt",
+			wantRange: &protocol.Range{
+				Start: protocol.Position{Line: 2, Character: 1},
+				End:   protocol.Position{Line: 2, Character: 12},
+			},
+		},
+		{name: "other word", line: "// go:embedded hello.txt"},
+		{name: "plain comment", line: "// embeds hello.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := Diagnose("package main\n\n" + tt.line + "\nvar s string\n")
+			if tt.wantRange == nil {
+				assert.Empty(t, diagnostics)
+				return
+			}
+			if assert.Len(t, diagnostics, 1) {
+				assert.Equal(t, *tt.wantRange, diagnostics[0].Range)
+				assert.Equal(t, protocol.DiagnosticSeverityWarning, diagnostics[0].Severity)
+				assert.Contains(t, diagnostics[0].Message, "plain comment")
+			}
+		})
+	}
+}
+
+// TestDiagnoseBlockCommentDirective tests that go:embed directives in block
+// comments are flagged as ineffective, whether inline or spanning lines.
+func TestDiagnoseBlockCommentDirective(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		wantRange []protocol.Range
+	}{
+		{
+			name:   "inline",
+			source: "package main\n\n/* go:embed a.txt */\nvar s string\n",
+			wantRange: []protocol.Range{{
+				Start: protocol.Position{Line: 2, Character: 0},
+				End:   protocol.Position{Line: 2, Character: 11},
+			}},
+		},
+		{
+			name:   "multi-line",
+			source: "package main\n\n\t/*\n\t  go:embed\n\t  a.txt\n\t*/\nvar s string\n",
+			wantRange: []protocol.Range{{
+				Start: protocol.Position{Line: 2, Character: 1},
+				End:   protocol.Position{Line: 3, Character: 11},
+			}},
+		},
+		{
+			name:   "unterminated",
+			source: "package main\n\n/*go:embed",
+			wantRange: []protocol.Range{{
+				Start: protocol.Position{Line: 2, Character: 0},
+				End:   protocol.Position{Line: 2, Character: 10},
+			}},
+		},
+		{
+			name:   "after non-ASCII text",
+			source: "package main\n\nvar s = \"é\" /* go:embed a.txt */\n",
+			wantRange: []protocol.Range{{
+				Start: protocol.Position{Line: 2, Character: 12},
+				End:   protocol.Position{Line: 2, Character: 23},
+			}},
+		},
+		{
+			name:   "other block comment",
+			source: "package main\n\n/* embeds go:embed */\nvar s string\n",
+		},
+		{
+			name:   "in a string",
+			source: "package main\n\nvar s = \"/* go:embed a.txt */\"\nvar r = `\n/* go:embed */`\n",
+		},
+		{
+			name:   "in a line comment",
+			source: "package main\n\n// see /* go:embed a.txt */\nvar s string\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]protocol.Range, 0)
+			for _, diagnostic := range Diagnose(tt.source) {
+				assert.Equal(t, protocol.DiagnosticSeverityWarning, diagnostic.Severity)
+				assert.Contains(t, diagnostic.Message, "block comment")
+				got = append(got, diagnostic.Range)
+			}
+			if tt.wantRange == nil {
+				tt.wantRange = []protocol.Range{}
+			}
+			assert.Equal(t, tt.wantRange, got)
+		})
+	}
+}
+
+// TestDiagnoseMisplacedDirective tests that directives are diagnosed
+// unless only blank lines and line comments separate them from the
+// declaration of a single variable.
+func TestDiagnoseMisplacedDirective(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{
+			name:   "adjacent",
+			source: "//go:embed a.txt\nvar f string\n",
+			want:   false,
+		},
+		{
+			name:   "blank line between",
+			source: "//go:embed a.txt\n\nvar f string\n",
+			want:   false,
+		},
+		{
+			name:   "comment between",
+			source: "//go:embed a.txt\n// f is embedded\nvar f string\n",
+			want:   false,
+		},
+		{
+			name:   "in a var group",
+			source: "var (\n\t//go:embed a.txt\n\tf string\n)\n",
+			want:   false,
+		},
+		{
+			name:   "code between",
+			source: "//go:embed a.txt\n\nconst name = \"a.txt\"\n\nvar f string\n",
+			want:   true,
+		},
+		{
+			name:   "before a function",
+			source: "//go:embed a.txt\nfunc main() {}\n",
+			want:   true,
+		},
+		{
+			name:   "before a var group",
+			source: "//go:embed a.txt\nvar (\n\tf string\n)\n",
+			want:   true,
+		},
+		{
+			name:   "several variables",
+			source: "//go:embed a.txt\nvar f, g string\n",
+			want:   true,
+		},
+		{
+			name:   "end of source",
+			source: "var f string\n\n//go:embed a.txt\n",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := Diagnose(tt.source)
+			if !tt.want {
+				assert.Empty(t, diagnostics)
+				return
+			}
+			if !assert.Len(t, diagnostics, 1) {
+				return
+			}
+			assert.Equal(t, CodeMisplacedDirective, diagnostics[0].Code)
+			assert.Equal(t, protocol.DiagnosticSeverityError, diagnostics[0].Severity)
+			assert.Contains(t, diagnostics[0].Message, "misplaced go:embed directive")
+		})
+	}
+}
+
+// TestDiagnoseCodes tests that every diagnostic carries its stable code
+// and source, and the offending pattern in the data of pattern findings.
+func TestDiagnoseCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantCode string
+		wantData interface{}
+	}{
+		{
+			name:     "invalid pattern",
+			source:   "//go:embed [a-\nvar f embed.FS\n",
+			wantCode: "embed/invalid-pattern",
+			wantData: DiagnosticData{Pattern: "[a-"},
+		},
+		{
+			name:     "absolute path",
+			source:   "//go:embed /etc/hosts\nvar f embed.FS\n",
+			wantCode: "embed/absolute-path",
+			wantData: DiagnosticData{Pattern: "/etc/hosts"},
+		},
+		{
+			name:     "path traversal",
+			source:   "//go:embed ../secret.txt\nvar f embed.FS\n",
+			wantCode: "embed/path-traversal",
+			wantData: DiagnosticData{Pattern: "../secret.txt"},
+		},
+		{
+			name:     "space after slashes",
+			source:   "// go:embed hello.txt\nvar f embed.FS\n",
+			wantCode: "embed/space-after-slashes",
+		},
+		{
+			name:     "block comment",
+			source:   "/* go:embed hello.txt */\nvar f embed.FS\n",
+			wantCode: "embed/block-comment",
+		},
+		{
+			name:     "misplaced directive",
+			source:   "//go:embed hello.txt\nfunc main() {}\n",
+			wantCode: "embed/misplaced-directive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := Diagnose(tt.source)
+			if !assert.Len(t, diagnostics, 1) {
+				return
+			}
+			assert.Equal(t, tt.wantCode, diagnostics[0].Code)
+			assert.Equal(t, "embedpls", diagnostics[0].Source)
+			assert.Equal(t, tt.wantData, diagnostics[0].Data)
+		})
+	}
+}
+
+// TestDiagnoseExportedFS tests that only exported embed.FS variables are
+// flagged by the optional lint.
+func TestDiagnoseExportedFS(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []protocol.Range
+	}{
+		{
+			name:   "exported",
+			source: "package main\n\n//go:embed static\nvar Content embed.FS\n",
+			want: []protocol.Range{{
+				Start: protocol.Position{Line: 3, Character: 4},
+				End:   protocol.Position{Line: 3, Character: 11},
+			}},
+		},
+		{
+			name:   "unexported",
+			source: "package main\n\n//go:embed static\nvar content embed.FS\n",
+			want:   []protocol.Range{},
+		},
+		{
+			name:   "exported string",
+			source: "package main\n\n//go:embed hello.txt\nvar Hello string\n",
+			want:   []protocol.Range{},
+		},
+		{
+			name:   "renamed import in a var group",
+			source: "package main\n\nvar (\n\t//go:embed static\n\tAssets e.FS\n)\n",
+			want: []protocol.Range{{
+				Start: protocol.Position{Line: 4, Character: 1},
+				End:   protocol.Position{Line: 4, Character: 7},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges := make([]protocol.Range, 0)
+			for _, diagnostic := range DiagnoseExportedFS(tt.source) {
+				if diagnostic.Code != CodeExportedFS {
+					t.Errorf("unexpected code %v", diagnostic.Code)
+				}
+				assert.Equal(t, protocol.DiagnosticSeverityWarning, diagnostic.Severity)
+				ranges = append(ranges, diagnostic.Range)
+			}
+			assert.Equal(t, tt.want, ranges)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package parsers
+
+import (
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+const (
+	// embedDirective is the canonical prefix of a go:embed directive.
+	embedDirective = "//go:embed"
+)
+
+// Directive is a go:embed directive found in a source string.
+type Directive struct {
+	// Line is the zero-based line of the directive in the source.
+	Line int
+	// Patterns are the patterns of the directive in the order they appear.
+	Patterns []PatternToken
+}
+
+// PatternToken is a single pattern of a go:embed directive.
+type PatternToken struct {
+	// Value is the text of the pattern, unquoted.
+	Value string
+	// Glob is the value of the pattern without its "all:" prefix, which is
+	// the path actually matched by the pattern.
+	Glob string
+	// All is whether the pattern has the "all:" prefix, embedding the
+	// hidden files of the directories it matches.
+	All bool
+	// Start is the byte offset of the start of the pattern in its line.
+	Start int
+	// End is the byte offset of the end of the pattern in its line.
+	End int
+	// Range is the range of the pattern, including its quotes, with its
+	// characters counted in UTF-16 code units.
+	Range protocol.Range
+}
+
+// Range returns the range of one of the patterns of the directive.
+func (d Directive) Range(pattern PatternToken) protocol.Range {
+	return pattern.Range
+}
+
+// ParseDirectives parses the go:embed directives of a source string.
+func ParseDirectives(source string) []Directive {
+	directives := make([]Directive, 0)
+	for i, line := range strings.Split(source, "\n") {
+		patterns := TokenizeDirective(line, i)
+		if patterns == nil {
+			continue
+		}
+		directives = append(directives, Directive{
+			Line:     i,
+			Patterns: patterns,
+		})
+	}
+	return directives
+}
+
+// PatternAt returns the directive and the pattern under the given position
+// of a source.
+//
+// The character of the position is counted in UTF-16 code units.
+func PatternAt(
+	source string,
+	position protocol.Position,
+) (Directive, PatternToken, bool) {
+	return NewIndex(source).PatternAt(position)
+}
+
+// DirectiveAt returns the directive whose arguments contain the given
+// position of a source, that is a position past the whitespace following
+// "//go:embed".
+//
+// The character of the position is counted in UTF-16 code units.
+func DirectiveAt(source string, position protocol.Position) (Directive, bool) {
+	return NewIndex(source).DirectiveAt(position)
+}
+
+// MisspelledDirective is a go:embed directive spelled with whitespace
+// after its slashes, such as "// go:embed", which Go treats as a plain
+// comment embedding nothing.
+type MisspelledDirective struct {
+	// Line is the zero-based line of the directive in the source.
+	Line int
+	// Start is the byte offset of the slashes in the line.
+	Start int
+	// End is the byte offset of the end of "go:embed" in the line.
+	End int
+}
+
+// Range returns the range of the misspelled "// go:embed" prefix.
+func (d MisspelledDirective) Range() protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: uint32(d.Line), Character: uint32(d.Start)},
+		End:   protocol.Position{Line: uint32(d.Line), Character: uint32(d.End)},
+	}
+}
+
+// Diagnostic returns the warning reported for the misspelled directive.
+func (d MisspelledDirective) Diagnostic() protocol.Diagnostic {
+	return protocol.Diagnostic{
+		Range:    d.Range(),
+		Severity: protocol.DiagnosticSeverityWarning,
+		Code:     CodeSpaceAfterSlashes,
+		Source:   DiagnosticSource,
+		Message: "\"// go:embed\" is a plain comment and embeds nothing, " +
+			"remove the space after the slashes to make it a directive",
+	}
+}
+
+// Fix returns the edit respelling the directive as "//go:embed".
+func (d MisspelledDirective) Fix() protocol.TextEdit {
+	return protocol.TextEdit{Range: d.Range(), NewText: embedDirective}
+}
+
+// ParseMisspelledDirectives returns the go:embed directives of a source
+// spelled with whitespace after their slashes.
+func ParseMisspelledDirectives(source string) []MisspelledDirective {
+	misspelled := make([]MisspelledDirective, 0)
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		start, end, ok := directivePrefix(line)
+		if !ok || line[start:end] == embedDirective {
+			continue
+		}
+		misspelled = append(misspelled, MisspelledDirective{
+			Line:  i,
+			Start: start,
+			End:   end,
+		})
+	}
+	return misspelled
+}
+
+// directivePrefix returns the byte offsets of the "//go:embed" prefix of a
+// line, allowing whitespace after the slashes.
+//
+// It reports false when the line holds no directive.
+func directivePrefix(line string) (start, end int, ok bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	start = len(line) - len(trimmed)
+	if !strings.HasPrefix(trimmed, "//") {
+		return 0, 0, false
+	}
+	rest := strings.TrimLeft(trimmed[len("//"):], " \t")
+	if !strings.HasPrefix(rest, "go:embed") {
+		return 0, 0, false
+	}
+	end = len(line) - len(rest) + len("go:embed")
+	if end < len(line) && line[end] != ' ' && line[end] != '\t' {
+		return 0, 0, false
+	}
+	return start, end, true
+}
@@ -0,0 +1,38 @@
+package parsers
+
+import (
+	"testing"
+
+	"go.lsp.dev/protocol"
+)
+
+// TestDirectiveAt tests finding the directive whose arguments contain a
+// position.
+func TestDirectiveAt(t *testing.T) {
+	source := "package main\n\n" +
+		"//go:embed \n" +
+		"\t//go:embed a.txt b.txt\n" +
+		"// a comment\n"
+	tests := []struct {
+		name     string
+		position protocol.Position
+		want     bool
+	}{
+		{name: "after the directive", position: protocol.Position{Line: 2, Character: 11}, want: true},
+		{name: "on the directive", position: protocol.Position{Line: 2, Character: 5}},
+		{name: "right after go:embed", position: protocol.Position{Line: 2, Character: 10}},
+		{name: "indented on a pattern", position: protocol.Position{Line: 3, Character: 14}, want: true},
+		{name: "past the patterns", position: protocol.Position{Line: 3, Character: 30}, want: true},
+		{name: "plain comment", position: protocol.Position{Line: 4, Character: 5}},
+		{name: "code", position: protocol.Position{Line: 0, Character: 3}},
+		{name: "past the end", position: protocol.Position{Line: 9, Character: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := DirectiveAt(source, tt.position)
+			if got != tt.want {
+				t.Errorf("DirectiveAt(%v) = %v, want %v", tt.position, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"go.lsp.dev/protocol"
+)
+
+// DiagnoseDir returns the diagnostics of Diagnose along with those that
+// need the files of dir of fsys, the package directory the patterns of the
+// source are resolved in.
+//
+// Findings about the files for which ignored, when not nil, reports true
+// given their slash-separated name relative to dir are left out. The
+// findings needing the files are skipped once ctx is cancelled. The
+// messages of the findings of a file with a build constraint note it, as
+// the findings only matter to the builds the file is part of.
+func DiagnoseDir(
+	ctx context.Context,
+	fsys resolver.FS,
+	source string,
+	dir string,
+	ignored func(name string) bool,
+) []protocol.Diagnostic {
+	return DiagnoseDirProgress(ctx, fsys, NewIndex(source), dir, ignored, nil)
+}
+
+// DiagnoseDirProgress is like DiagnoseDir for the source of an index, but
+// calls report, when not nil, before resolving the patterns of each
+// variable with the number of variables already diagnosed.
+//
+// Each pattern is resolved once, whatever the number of findings needing
+// what it matches.
+func DiagnoseDirProgress(
+	ctx context.Context,
+	fsys resolver.FS,
+	index *Index,
+	dir string,
+	ignored func(name string) bool,
+	report func(variable string, done, total int),
+) []protocol.Diagnostic {
+	if ignored == nil {
+		ignored = func(string) bool { return false }
+	}
+	diagnostics := index.Diagnose()
+	resolutions := newResolutionCache(fsys, dir)
+	blocks := index.Blocks()
+	for i, block := range blocks {
+		if report != nil {
+			report(block.Var, i, len(blocks))
+		}
+		diagnostics = append(
+			diagnostics,
+			resolutionDiagnostics(ctx, resolutions, block, ignored)...,
+		)
+		if !block.IsFS() {
+			diagnostics = append(
+				diagnostics,
+				singleFileDiagnostics(ctx, resolutions, block)...,
+			)
+			continue
+		}
+		diagnostics = append(
+			diagnostics,
+			duplicatePathDiagnostics(ctx, resolutions, block, ignored)...,
+		)
+	}
+	if build, ok := ParseBuildConstraint(index.source); ok {
+		for i := range diagnostics {
+			diagnostics[i].Message += " (" + build.Note() + ")"
+		}
+	}
+	return diagnostics
+}
+
+// resolutionCache resolves the patterns of a diagnostics pass in a
+// package directory, each once.
+type resolutionCache struct {
+	fsys     resolver.FS
+	dir      string
+	resolved map[string]inspection
+}
+
+// inspection is what resolver.Inspect returns for a pattern.
+type inspection struct {
+	resolution resolver.Resolution
+	err        error
+}
+
+// newResolutionCache creates an empty cache of the resolutions of the
+// patterns of dir of fsys.
+func newResolutionCache(fsys resolver.FS, dir string) *resolutionCache {
+	return &resolutionCache{
+		fsys:     fsys,
+		dir:      dir,
+		resolved: make(map[string]inspection),
+	}
+}
+
+// inspect returns what resolver.Inspect returns for a pattern, inspecting
+// it only the first time.
+func (c *resolutionCache) inspect(ctx context.Context, pattern string) (resolver.Resolution, error) {
+	if cached, ok := c.resolved[pattern]; ok {
+		return cached.resolution, cached.err
+	}
+	resolution, err := resolver.Inspect(ctx, c.fsys, c.dir, pattern)
+	c.resolved[pattern] = inspection{resolution, err}
+	return resolution, err
+}
+
+// blockPattern is a pattern of a directive of an embedding variable.
+type blockPattern struct {
+	directive Directive
+	pattern   PatternToken
+}
+
+// duplicatePathDiagnostics returns the warnings of the patterns of an
+// embed.FS variable embedding files at the same virtual path as another
+// of its patterns.
+//
+// The go command embeds each path once, so the overlap usually means one
+// of the patterns does not embed what its author thinks it does. Both
+// patterns are flagged.
+func duplicatePathDiagnostics(
+	ctx context.Context,
+	resolutions *resolutionCache,
+	block EmbedBlock,
+	ignored func(name string) bool,
+) []protocol.Diagnostic {
+	patterns := make([]blockPattern, 0)
+	owners := make(map[string][]int)
+	for _, directive := range block.Directives {
+		for _, pattern := range directive.Patterns {
+			resolution, err := resolutions.inspect(ctx, pattern.Value)
+			if err != nil {
+				continue
+			}
+			for _, file := range resolution.Files {
+				if ignored(file) {
+					continue
+				}
+				owners[file] = append(owners[file], len(patterns))
+			}
+			patterns = append(patterns, blockPattern{directive, pattern})
+		}
+	}
+	paths := make([]string, 0, len(owners))
+	for path, owned := range owners {
+		if len(owned) > 1 {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	// the first shared path of each pattern, another pattern sharing it and
+	// the number of shared paths
+	first := make(map[int]string)
+	other := make(map[int]int)
+	shared := make(map[int]int)
+	for _, path := range paths {
+		owned := owners[path]
+		for i, owner := range owned {
+			shared[owner]++
+			if _, ok := first[owner]; ok {
+				continue
+			}
+			first[owner] = path
+			other[owner] = owned[(i+1)%len(owned)]
+		}
+	}
+	diagnostics := make([]protocol.Diagnostic, 0, len(first))
+	for i, p := range patterns {
+		path, ok := first[i]
+		if !ok {
+			continue
+		}
+		message := fmt.Sprintf(
+			"%q is embedded by both %q and %q",
+			path,
+			p.pattern.Value,
+			patterns[other[i]].pattern.Value,
+		)
+		if more := shared[i] - 1; more > 0 {
+			message += fmt.Sprintf(", along with %d more file(s)", more)
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    p.directive.Range(p.pattern),
+			Severity: protocol.DiagnosticSeverityWarning,
+			Code:     CodeDuplicatePath,
+			Source:   DiagnosticSource,
+			Message:  message,
+			Data:     DiagnosticData{Pattern: p.pattern.Value},
+		})
+	}
+	return diagnostics
+}
+
+// resolutionDiagnostics returns the findings about what the patterns of a
+// variable match without embedding it, directories holding a go.mod file
+// and symbolic links, and about patterns differing in case from the names
+// on disk.
+//
+// The go command silently leaves the files of nested modules out of the
+// directories it embeds, so the files of such a directory seem embedded
+// while they are not, and it fails to build a package whose patterns
+// match symbolic links. Case-insensitive file systems resolve a pattern
+// whatever its case, while the paths of the embedded files keep the case
+// of the pattern and are looked up case-sensitively at run time.
+func resolutionDiagnostics(
+	ctx context.Context,
+	resolutions *resolutionCache,
+	block EmbedBlock,
+	ignored func(name string) bool,
+) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	for _, directive := range block.Directives {
+		for _, pattern := range directive.Patterns {
+			resolution, _ := resolutions.inspect(ctx, pattern.Value)
+			if modules := kept(resolution.NestedModules, ignored); len(modules) > 0 {
+				message := fmt.Sprintf(
+					"%q is not embedded by %q as it holds a nested module (go.mod)",
+					modules[0],
+					pattern.Value,
+				)
+				if more := len(modules) - 1; more > 0 {
+					message += fmt.Sprintf(", along with %d more module(s)", more)
+				}
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Range:    directive.Range(pattern),
+					Severity: protocol.DiagnosticSeverityWarning,
+					Code:     CodeNestedModule,
+					Source:   DiagnosticSource,
+					Message:  message,
+					Data:     DiagnosticData{Pattern: pattern.Value},
+				})
+			}
+			if onDisk, ok := resolver.Miscased(resolutions.fsys, resolutions.dir, pattern.Value); ok {
+				diagnostics = append(diagnostics, protocol.Diagnostic{
+					Range:    directive.Range(pattern),
+					Severity: protocol.DiagnosticSeverityWarning,
+					Code:     CodeCaseMismatch,
+					Source:   DiagnosticSource,
+					Message: fmt.Sprintf(
+						"%q is spelled %q on disk, and embedded paths are case-sensitive",
+						pattern.Glob,
+						onDisk,
+					),
+					Data: DiagnosticData{Pattern: pattern.Value},
+				})
+			}
+			if links := kept(resolution.Symlinks, ignored); len(links) > 0 {
+				message := fmt.Sprintf(
+					"%q matched by %q is a symbolic link, which go:embed cannot embed",
+					links[0],
+					pattern.Value,
+				)
+				if more := len(links) - 1; more > 0 {
+					message += fmt.Sprintf(", along with %d more link(s)", more)
+				}
+				diagnostics = append(diagnostics, newPatternDiagnostic(
+					directive,
+					pattern,
+					CodeSymlink,
+					message,
+				))
+			}
+		}
+	}
+	return diagnostics
+}
+
+// singleFileDiagnostics returns the errors of the patterns of a string or
+// []byte variable when they do not embed exactly one file, which fails the
+// build.
+//
+// Patterns matching no file are flagged on their own, and every pattern
+// matching a file is flagged when they embed several files together.
+// Patterns already diagnosed as invalid are left out.
+func singleFileDiagnostics(
+	ctx context.Context,
+	resolutions *resolutionCache,
+	block EmbedBlock,
+) []protocol.Diagnostic {
+	diagnostics := make([]protocol.Diagnostic, 0)
+	matching := make([]blockPattern, 0)
+	firsts := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, directive := range block.Directives {
+		for _, pattern := range directive.Patterns {
+			if !validPattern(pattern.Glob) {
+				continue
+			}
+			resolution, _ := resolutions.inspect(ctx, pattern.Value)
+			if ctx.Err() != nil {
+				return diagnostics
+			}
+			if len(resolution.Files) == 0 {
+				diagnostics = append(diagnostics, newPatternDiagnostic(
+					directive,
+					pattern,
+					CodeNoMatch,
+					fmt.Sprintf(
+						"pattern %q matches no file, and the %s variable %s must embed exactly one",
+						pattern.Value,
+						block.Type,
+						block.Var,
+					),
+				))
+				continue
+			}
+			matching = append(matching, blockPattern{directive, pattern})
+			firsts = append(firsts, resolution.Files[0])
+			for _, file := range resolution.Files {
+				seen[file] = true
+			}
+		}
+	}
+	if len(seen) < 2 {
+		return diagnostics
+	}
+	for i, p := range matching {
+		diagnostics = append(diagnostics, newPatternDiagnostic(
+			p.directive,
+			p.pattern,
+			CodeMultipleFiles,
+			fmt.Sprintf(
+				"the %s variable %s embeds %d files, %q matched by %q among them, but must embed exactly one",
+				block.Type,
+				block.Var,
+				len(seen),
+				firsts[i],
+				p.pattern.Value,
+			),
+		))
+	}
+	return diagnostics
+}
+
+// validPattern reports whether a pattern passes every pattern check.
+func validPattern(pattern string) bool {
+	for _, check := range patternChecks {
+		if check.check(pattern) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// kept returns the names for which ignored reports false.
+func kept(names []string, ignored func(name string) bool) []string {
+	kept := make([]string, 0, len(names))
+	for _, name := range names {
+		if !ignored(name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,330 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestDiagnoseDuplicatePath tests that the patterns of an embed.FS
+// embedding files at the same virtual path are both flagged.
+func TestDiagnoseDuplicatePath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"static/app.js", "static/site.css", "hello.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "directory and file",
+			source: "//go:embed static hello.txt\n//go:embed static/app.js\nvar f embed.FS\n",
+			want: []string{
+				`"static/app.js" is embedded by both "static" and "static/app.js"`,
+				`"static/app.js" is embedded by both "static/app.js" and "static"`,
+			},
+		},
+		{
+			name:   "overlapping globs",
+			source: "//go:embed static/* static\nvar f embed.FS\n",
+			want: []string{
+				`"static/app.js" is embedded by both "static/*" and "static", along with 1 more file(s)`,
+				`"static/app.js" is embedded by both "static" and "static/*", along with 1 more file(s)`,
+			},
+		},
+		{
+			name:   "disjoint",
+			source: "//go:embed static/*.js hello.txt\nvar f embed.FS\n",
+			want:   []string{},
+		},
+		{
+			name:   "separate variables",
+			source: "//go:embed static\nvar a embed.FS\n\n//go:embed static\nvar b embed.FS\n",
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := make([]string, 0)
+			for _, diagnostic := range DiagnoseDir(context.Background(), resolver.OS, tt.source, dir, nil) {
+				assert.Equal(t, CodeDuplicatePath, diagnostic.Code)
+				messages = append(messages, diagnostic.Message)
+			}
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+}
+
+// TestDiagnoseNestedModule tests that the patterns embedding directories
+// holding a nested module are warned about.
+func TestDiagnoseNestedModule(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"static/app.js",
+		"static/plugin/go.mod",
+		"static/plugin/plugin.js",
+		"static/tools/go.mod",
+		"hello.txt",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	tests := []struct {
+		name    string
+		source  string
+		ignored func(string) bool
+		want    []string
+	}{
+		{
+			name:   "directory",
+			source: "//go:embed static hello.txt\nvar f embed.FS\n",
+			want: []string{
+				`"static/plugin" is not embedded by "static" as it holds a nested module (go.mod), along with 1 more module(s)`,
+			},
+		},
+		{
+			name:   "module directory",
+			source: "//go:embed static/plugin\nvar f embed.FS\n",
+			want: []string{
+				`"static/plugin" is not embedded by "static/plugin" as it holds a nested module (go.mod)`,
+			},
+		},
+		{
+			name:    "ignored module",
+			source:  "//go:embed static\nvar f embed.FS\n",
+			ignored: func(name string) bool { return name == "static/plugin" },
+			want: []string{
+				`"static/tools" is not embedded by "static" as it holds a nested module (go.mod)`,
+			},
+		},
+		{
+			name:   "files",
+			source: "//go:embed static/app.js hello.txt\nvar f embed.FS\n",
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := make([]string, 0)
+			for _, diagnostic := range DiagnoseDir(context.Background(), resolver.OS, tt.source, dir, tt.ignored) {
+				assert.Equal(t, CodeNestedModule, diagnostic.Code)
+				messages = append(messages, diagnostic.Message)
+			}
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+}
+
+// TestDiagnoseSymlink tests that the patterns matching symbolic links are
+// reported as errors.
+func TestDiagnoseSymlink(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"static/app.js", "target.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	if err := os.Symlink("../target.txt", filepath.Join(dir, "static", "link.txt")); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+	diagnostics := DiagnoseDir(
+		context.Background(),
+		resolver.OS,
+		"//go:embed static target.txt\nvar f embed.FS\n",
+		dir,
+		nil,
+	)
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, CodeSymlink, diagnostics[0].Code)
+		assert.Equal(t, protocol.DiagnosticSeverityError, diagnostics[0].Severity)
+		assert.Equal(t,
+			`"static/link.txt" matched by "static" is a symbolic link, which go:embed cannot embed`,
+			diagnostics[0].Message,
+		)
+	}
+	ignored := DiagnoseDir(
+		context.Background(),
+		resolver.OS,
+		"//go:embed static\nvar f embed.FS\n",
+		dir,
+		func(name string) bool { return name == "static/link.txt" },
+	)
+	assert.Empty(t, ignored)
+}
+
+// TestDiagnoseCaseMismatch tests that patterns spelled with another case
+// than the names on disk are warned about, whether the file system folds
+// case or not.
+func TestDiagnoseCaseMismatch(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Static/app.js", "hello.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	for _, fsys := range []resolver.FS{resolver.OS, resolver.FoldCase(resolver.OS)} {
+		t.Run(fmt.Sprintf("fold %v", fsys.FoldsCase()), func(t *testing.T) {
+			diagnostics := DiagnoseDir(
+				context.Background(),
+				fsys,
+				"//go:embed static/app.js Hello.txt\nvar f embed.FS\n\n//go:embed Static\nvar g embed.FS\n",
+				dir,
+				nil,
+			)
+			messages := make([]string, 0)
+			for _, diagnostic := range diagnostics {
+				assert.Equal(t, CodeCaseMismatch, diagnostic.Code)
+				messages = append(messages, diagnostic.Message)
+			}
+			assert.Equal(t, []string{
+				`"static/app.js" is spelled "Static/app.js" on disk, and embedded paths are case-sensitive`,
+				`"Hello.txt" is spelled "hello.txt" on disk, and embedded paths are case-sensitive`,
+			}, messages)
+		})
+	}
+}
+
+// TestDiagnoseSingleFile tests that the patterns of string and []byte
+// variables are flagged unless they embed exactly one file.
+func TestDiagnoseSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"hello.txt", "static/app.js", "static/site.css"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+	tests := []struct {
+		name     string
+		source   string
+		wantCode string
+		want     []string
+	}{
+		{
+			name:   "one file",
+			source: "//go:embed hello.txt\nvar s string\n",
+		},
+		{
+			name:   "one file matched twice",
+			source: "//go:embed hello.txt hell?.txt\nvar s string\n",
+		},
+		{
+			name:     "no file",
+			source:   "//go:embed missing.txt\nvar s string\n",
+			wantCode: CodeNoMatch,
+			want: []string{
+				`pattern "missing.txt" matches no file, and the string variable s must embed exactly one`,
+			},
+		},
+		{
+			name:     "several files of a glob",
+			source:   "//go:embed static/*\nvar b []byte\n",
+			wantCode: CodeMultipleFiles,
+			want: []string{
+				`the []byte variable b embeds 2 files, "static/app.js" matched by "static/*" among them, but must embed exactly one`,
+			},
+		},
+		{
+			name:     "several patterns",
+			source:   "//go:embed hello.txt\n//go:embed static/app.js\nvar s string\n",
+			wantCode: CodeMultipleFiles,
+			want: []string{
+				`the string variable s embeds 2 files, "hello.txt" matched by "hello.txt" among them, but must embed exactly one`,
+				`the string variable s embeds 2 files, "static/app.js" matched by "static/app.js" among them, but must embed exactly one`,
+			},
+		},
+		{
+			name:   "embed.FS",
+			source: "//go:embed static missing.txt\nvar f embed.FS\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := DiagnoseDir(context.Background(), resolver.OS, tt.source, dir, nil)
+			messages := make([]string, 0)
+			for _, diagnostic := range diagnostics {
+				assert.Equal(t, tt.wantCode, diagnostic.Code)
+				assert.Equal(t, protocol.DiagnosticSeverityError, diagnostic.Severity)
+				messages = append(messages, diagnostic.Message)
+			}
+			if tt.want == nil {
+				tt.want = []string{}
+			}
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+}
+
+// TestDiagnoseBuildConstraint tests that the findings of a file with a
+// build constraint note it.
+func TestDiagnoseBuildConstraint(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "no constraint",
+			source: "package main\n\n//go:embed ../secret.txt\nvar s string\n",
+			want:   `invalid pattern "../secret.txt": ` + errPathTraversal.Error(),
+		},
+		{
+			name:   "constraint",
+			source: "//go:build linux\n\npackage main\n\n//go:embed ../secret.txt\nvar s string\n",
+			want: `invalid pattern "../secret.txt": ` + errPathTraversal.Error() +
+				" (gated by //go:build linux)",
+		},
+		{
+			name:   "ignore",
+			source: "//go:build ignore\n\npackage main\n\n//go:embed ../secret.txt\nvar s string\n",
+			want: `invalid pattern "../secret.txt": ` + errPathTraversal.Error() +
+				" (excluded from builds by //go:build ignore unless the file is named on the command line)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := DiagnoseDir(context.Background(), resolver.OS, tt.source, t.TempDir(), nil)
+			if assert.Len(t, diagnostics, 1) {
+				assert.Equal(t, tt.want, diagnostics[0].Message)
+			}
+		})
+	}
+}
+
+// readDirCounter is a file system counting the reads of each directory.
+type readDirCounter struct {
+	resolver.FS
+	reads map[string]int
+}
+
+// ReadDir counts and reads the named directory.
+func (c readDirCounter) ReadDir(name string) ([]fs.DirEntry, error) {
+	c.reads[name]++
+	return c.FS.ReadDir(name)
+}
+
+// TestDiagnoseDirResolvesOnce tests that a pattern is resolved once per
+// diagnostics pass, whatever the number of findings and variables needing
+// what it matches.
+func TestDiagnoseDirResolvesOnce(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "static", "app.js")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte("app"), 0644))
+	fsys := readDirCounter{FS: resolver.OS, reads: make(map[string]int)}
+	source := "//go:embed static\nvar f embed.FS\n\n//go:embed static\nvar g embed.FS\n\n" +
+		"//go:embed static/app.js\nvar s string\n"
+	assert.Empty(t, DiagnoseDir(context.Background(), fsys, source, dir, nil))
+	assert.Equal(t, 1, fsys.reads[filepath.Join(dir, "static")])
+}
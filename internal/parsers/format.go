@@ -0,0 +1,139 @@
+package parsers
+
+import (
+	"sort"
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+// FormatDirectives returns the edits rewriting the go:embed directives of
+// a source into their canonical form, or no edits when they already are.
+//
+// A canonical directive is spelled "//go:embed", without a space after the
+// slashes, and separates its patterns, sorted, with single spaces. The
+// indentation of the directives is kept.
+//
+// Directives with quoted patterns only have their spelling fixed, as their
+// patterns may themselves contain spaces.
+func FormatDirectives(source string) []protocol.TextEdit {
+	edits := make([]protocol.TextEdit, 0)
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		formatted, ok := formatDirective(line)
+		if !ok || formatted == line {
+			continue
+		}
+		edits = append(edits, protocol.TextEdit{
+			Range:   lineRange(i, i, line),
+			NewText: formatted,
+		})
+	}
+	return edits
+}
+
+// formatDirective returns the canonical form of a line holding a go:embed
+// directive, possibly spelled with spaces after the slashes.
+//
+// It reports false when the line holds no directive.
+func formatDirective(line string) (string, bool) {
+	start, end, ok := directivePrefix(line)
+	if !ok {
+		return "", false
+	}
+	indent, args := line[:start], line[end:]
+	if strings.ContainsAny(args, "\"`") {
+		return indent + embedDirective + args, true
+	}
+	patterns := strings.Fields(args)
+	if len(patterns) == 0 {
+		return indent + embedDirective, true
+	}
+	sort.Strings(patterns)
+	return indent + embedDirective + " " + strings.Join(patterns, " "), true
+}
+
+// SplitDirective returns the edit rewriting a directive of several
+// patterns into consecutive directives of a single pattern each, keeping
+// the order of the patterns and the indentation of the directive.
+//
+// It reports false when the directive has fewer than two patterns or has
+// quoted patterns, which may themselves contain spaces.
+func SplitDirective(source string, directive Directive) (protocol.TextEdit, bool) {
+	lines := strings.Split(source, "\n")
+	if len(directive.Patterns) < 2 || directive.Line >= len(lines) {
+		return protocol.TextEdit{}, false
+	}
+	line, newline := lineEnding(lines[directive.Line])
+	if strings.ContainsAny(line, "\"`") {
+		return protocol.TextEdit{}, false
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	split := make([]string, 0, len(directive.Patterns))
+	for _, pattern := range directive.Patterns {
+		split = append(split, indent+embedDirective+" "+pattern.Value)
+	}
+	return protocol.TextEdit{
+		Range:   lineRange(directive.Line, directive.Line, line),
+		NewText: strings.Join(split, newline),
+	}, true
+}
+
+// lineEnding splits the carriage return of a CRLF line ending off a line,
+// returning the line and the line ending to use between new lines.
+func lineEnding(line string) (string, string) {
+	if strings.HasSuffix(line, "\r") {
+		return strings.TrimSuffix(line, "\r"), "\r\n"
+	}
+	return line, "\n"
+}
+
+// lineRange returns the range from the start of the first line to the end
+// of the last line, whose content without line ending is given.
+func lineRange(first, last int, lastLine string) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: uint32(first)},
+		End: protocol.Position{
+			Line:      uint32(last),
+			Character: uint32(byteToUTF16Offset(lastLine, len(lastLine))),
+		},
+	}
+}
+
+// MergeDirectives returns the edit rewriting the directives of a block
+// into a single directive listing all their patterns in order, with the
+// indentation of the first directive.
+//
+// It reports false when the block has fewer than two directives or when
+// blank lines or comments separate them, as merging would drop those.
+func MergeDirectives(source string, block EmbedBlock) (protocol.TextEdit, bool) {
+	if len(block.Directives) < 2 || !block.Contiguous() {
+		return protocol.TextEdit{}, false
+	}
+	lines := strings.Split(source, "\n")
+	args := make([]string, 0, len(block.Directives))
+	indent, last := "", ""
+	for i, directive := range block.Directives {
+		line, _ := lineEnding(lines[directive.Line])
+		start, end, ok := directivePrefix(line)
+		if !ok {
+			return protocol.TextEdit{}, false
+		}
+		if i == 0 {
+			indent = line[:start]
+		}
+		if arg := strings.TrimSpace(line[end:]); arg != "" {
+			args = append(args, arg)
+		}
+		last = line
+	}
+	first := block.Directives[0].Line
+	return protocol.TextEdit{
+		Range: lineRange(
+			first,
+			block.Directives[len(block.Directives)-1].Line,
+			last,
+		),
+		NewText: indent + embedDirective + " " + strings.Join(args, " "),
+	}, true
+}
@@ -0,0 +1,186 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestFormatDirectives tests rewriting directives into their canonical
+// form.
+func TestFormatDirectives(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []protocol.TextEdit
+	}{
+		{
+			name:   "canonical",
+			source: "package main\n\n//go:embed a.txt b.txt\nvar s string\n",
+			want:   []protocol.TextEdit{},
+		},
+		{
+			name:   "space after slashes",
+			source: "package main\n\n// go:embed hello.txt\nvar s string\n",
+			want: []protocol.TextEdit{{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2},
+					End:   protocol.Position{Line: 2, Character: 21},
+				},
+				NewText: "//go:embed hello.txt",
+			}},
+		},
+		{
+			name:   "sorted patterns and collapsed spaces",
+			source: "\t//go:embed  static/*.js\ta.txt   b.txt\r\nvar s string\n",
+			want: []protocol.TextEdit{{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 0},
+					End:   protocol.Position{Line: 0, Character: 38},
+				},
+				NewText: "\t//go:embed a.txt b.txt static/*.js",
+			}},
+		},
+		{
+			name:   "quoted patterns keep their spacing",
+			source: "//  go:embed \"my file.txt\"  a.txt\n",
+			want: []protocol.TextEdit{{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 0},
+					End:   protocol.Position{Line: 0, Character: 33},
+				},
+				NewText: "//go:embed \"my file.txt\"  a.txt",
+			}},
+		},
+		{
+			name:   "utf-16 line length",
+			source: "//go:embed é.txt a.txt\n",
+			want: []protocol.TextEdit{{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 0},
+					End:   protocol.Position{Line: 0, Character: 22},
+				},
+				NewText: "//go:embed a.txt é.txt",
+			}},
+		},
+		{
+			name:   "not a directive",
+			source: "//go:embedded a.txt\n// go:generate b\n",
+			want:   []protocol.TextEdit{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatDirectives(tt.source))
+		})
+	}
+}
+
+// TestSplitDirective tests splitting a directive into one directive per
+// pattern.
+func TestSplitDirective(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+		ok     bool
+	}{
+		{
+			name:   "three patterns",
+			source: "//go:embed a.txt static/*.js  templates\nvar fs embed.FS\n",
+			want:   "//go:embed a.txt\n//go:embed static/*.js\n//go:embed templates",
+			ok:     true,
+		},
+		{
+			name:   "indented crlf",
+			source: "var (\r\n\t//go:embed a.txt b.txt\r\n\tfs embed.FS\r\n)\r\n",
+			want:   "\t//go:embed a.txt\r\n\t//go:embed b.txt",
+			ok:     true,
+		},
+		{
+			name:   "single pattern",
+			source: "//go:embed a.txt\nvar s string\n",
+		},
+		{
+			name:   "quoted pattern",
+			source: "//go:embed \"a b.txt\" c.txt\nvar fs embed.FS\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directives := ParseDirectives(tt.source)
+			if !assert.Len(t, directives, 1) {
+				return
+			}
+			edit, ok := SplitDirective(tt.source, directives[0])
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.want, edit.NewText)
+			assert.Equal(t, uint32(directives[0].Line), edit.Range.Start.Line)
+			assert.Equal(t, uint32(directives[0].Line), edit.Range.End.Line)
+			assert.Equal(t, len(directives[0].Patterns), len(ParseDirectives(edit.NewText)))
+		})
+	}
+}
+
+// TestMergeDirectives tests merging the directives of a block into one.
+func TestMergeDirectives(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   protocol.TextEdit
+		ok     bool
+	}{
+		{
+			name: "three directives",
+			source: "//go:embed a.txt\n" +
+				"//go:embed static/*.js  b.txt\n" +
+				"//go:embed templates\n" +
+				"var fs embed.FS\n",
+			want: protocol.TextEdit{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 0},
+					End:   protocol.Position{Line: 2, Character: 20},
+				},
+				NewText: "//go:embed a.txt static/*.js  b.txt templates",
+			},
+			ok: true,
+		},
+		{
+			name:   "indented crlf",
+			source: "var (\r\n\t//go:embed a.txt\r\n\t//go:embed b.txt\r\n\tfs embed.FS\r\n)\r\n",
+			want: protocol.TextEdit{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 1},
+					End:   protocol.Position{Line: 2, Character: 17},
+				},
+				NewText: "\t//go:embed a.txt b.txt",
+			},
+			ok: true,
+		},
+		{
+			name:   "single directive",
+			source: "//go:embed a.txt b.txt\nvar fs embed.FS\n",
+		},
+		{
+			name:   "separated by a comment",
+			source: "//go:embed a.txt\n// the templates\n//go:embed templates\nvar fs embed.FS\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := ParseEmbedBlocks(tt.source)
+			if !assert.Len(t, blocks, 1) {
+				return
+			}
+			edit, ok := MergeDirectives(tt.source, blocks[0])
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.want, edit)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package parsers
+
+import (
+	"strings"
+	"sync"
+
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// Index is the parsed representation of the go:embed directives of a
+// source, answering position queries without parsing the source again.
+type Index struct {
+	// Directives are the directives of the source in order.
+	Directives []Directive
+	source     string
+	lines      []string
+	// positions converts the byte offsets of the source to positions.
+	positions  *LineIndex
+	blocksOnce sync.Once
+	blocks     []EmbedBlock
+}
+
+// NewIndex parses the directives of a source into an index.
+func NewIndex(source string) *Index {
+	return &Index{
+		Directives: ParseDirectives(source),
+		source:     source,
+		lines:      strings.Split(source, "\n"),
+		positions:  NewLineIndex(source),
+	}
+}
+
+// Blocks returns the variables targeted by the directives of the source.
+//
+// They are parsed on first use, as most queries only need the directives.
+func (x *Index) Blocks() []EmbedBlock {
+	x.blocksOnce.Do(func() {
+		x.blocks = parseEmbedBlocks(x.lines, x.Directives)
+	})
+	return x.blocks
+}
+
+// Misplaced returns the directives of the source embedding files into no
+// variable, because code or the end of the source comes before the
+// declaration of a single variable.
+func (x *Index) Misplaced() []Directive {
+	placed := make(map[int]bool)
+	for _, block := range x.Blocks() {
+		for _, directive := range block.Directives {
+			placed[directive.Line] = true
+		}
+	}
+	misplaced := make([]Directive, 0)
+	for _, directive := range x.Directives {
+		if !placed[directive.Line] {
+			misplaced = append(misplaced, directive)
+		}
+	}
+	return misplaced
+}
+
+// PatternAt returns the directive and the pattern under the given position.
+//
+// The character of the position is counted in UTF-16 code units.
+func (x *Index) PatternAt(position protocol.Position) (Directive, PatternToken, bool) {
+	if int(position.Line) >= len(x.lines) {
+		return Directive{}, PatternToken{}, false
+	}
+	cursor := utf16OffsetToByte(x.lines[position.Line], int(position.Character))
+	for _, directive := range x.Directives {
+		if directive.Line != int(position.Line) {
+			continue
+		}
+		for _, pattern := range directive.Patterns {
+			if pattern.Start <= cursor && cursor <= pattern.End {
+				return directive, pattern, true
+			}
+		}
+	}
+	return Directive{}, PatternToken{}, false
+}
+
+// DirectiveAt returns the directive whose arguments contain the given
+// position, that is a position past the whitespace following "//go:embed".
+//
+// The character of the position is counted in UTF-16 code units.
+func (x *Index) DirectiveAt(position protocol.Position) (Directive, bool) {
+	if int(position.Line) >= len(x.lines) {
+		return Directive{}, false
+	}
+	line := strings.TrimSuffix(x.lines[position.Line], "\r")
+	cursor := utf16OffsetToByte(line, int(position.Character))
+	offset := len(line) - len(strings.TrimLeft(line, " \t")) + len(embedDirective)
+	for _, directive := range x.Directives {
+		if directive.Line == int(position.Line) && cursor > offset {
+			return directive, true
+		}
+	}
+	return Directive{}, false
+}
+
+// EmbedBlockAt returns the block whose variable name is under the given
+// position.
+//
+// The character of the position is counted in UTF-16 code units.
+func (x *Index) EmbedBlockAt(position protocol.Position) (EmbedBlock, bool) {
+	if int(position.Line) >= len(x.lines) {
+		return EmbedBlock{}, false
+	}
+	cursor := utf16OffsetToByte(x.lines[position.Line], int(position.Character))
+	for _, block := range x.Blocks() {
+		if block.Line == int(position.Line) &&
+			block.Start <= cursor && cursor <= block.End {
+			return block, true
+		}
+	}
+	return EmbedBlock{}, false
+}
+
+// EmbedBlockOf returns the block a directive embeds files into.
+func (x *Index) EmbedBlockOf(directive Directive) (EmbedBlock, bool) {
+	for _, block := range x.Blocks() {
+		for _, d := range block.Directives {
+			if d.Line == directive.Line {
+				return block, true
+			}
+		}
+	}
+	return EmbedBlock{}, false
+}
+
+// DirectiveRange returns the range of a directive of the index, from its
+// leading slashes to the end of its last pattern.
+func (x *Index) DirectiveRange(directive Directive) protocol.Range {
+	line := strings.TrimRight(x.lines[directive.Line], " \t\r")
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	return protocol.Range{
+		Start: x.positions.column(directive.Line, start),
+		End:   x.positions.column(directive.Line, len(line)),
+	}
+}
+
+// VarRange returns the range of the variable name of a block of the index,
+// its characters counted in UTF-16 code units.
+func (x *Index) VarRange(block EmbedBlock) protocol.Range {
+	return protocol.Range{
+		Start: x.positions.column(block.Line, block.Start),
+		End:   x.positions.column(block.Line, block.End),
+	}
+}
+
+// BlockRange returns the range of a block of the index, from its first
+// directive to the end of its variable declaration.
+func (x *Index) BlockRange(block EmbedBlock) protocol.Range {
+	line := strings.TrimRight(x.lines[block.Line], " \t\r")
+	return protocol.Range{
+		Start: x.DirectiveRange(block.Directives[0]).Start,
+		End:   x.positions.column(block.Line, len(line)),
+	}
+}
+
+// DocIndex caches the indexes of documents by URI and version, so that
+// repeated queries on an unchanged document parse it once.
+//
+// It is safe for concurrent use.
+type DocIndex struct {
+	mu      sync.Mutex
+	entries map[uri.URI]docIndexEntry
+	// parses counts the indexes built, for benchmarks and tests.
+	parses int
+	// hits counts the indexes returned from the cache.
+	hits int
+}
+
+// docIndexEntry is the index of a single version of a document.
+type docIndexEntry struct {
+	version int32
+	source  string
+	index   *Index
+}
+
+// NewDocIndex creates an empty DocIndex.
+func NewDocIndex() *DocIndex {
+	return &DocIndex{entries: make(map[uri.URI]docIndexEntry)}
+}
+
+// Get returns the index of the given version of a document, parsing the
+// source only when the cached index is of another version or text.
+func (d *DocIndex) Get(document uri.URI, version int32, source string) *Index {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, ok := d.entries[document]
+	if ok && entry.version == version && entry.source == source {
+		d.hits++
+		return entry.index
+	}
+	entry = docIndexEntry{
+		version: version,
+		source:  source,
+		index:   NewIndex(source),
+	}
+	d.parses++
+	d.entries[document] = entry
+	return entry.index
+}
+
+// Invalidate drops the cached index of a document.
+func (d *DocIndex) Invalidate(document uri.URI) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.entries, document)
+}
+
+// Parses returns the number of indexes the cache has built.
+func (d *DocIndex) Parses() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.parses
+}
+
+// Hits returns the number of indexes the cache has returned without
+// parsing.
+func (d *DocIndex) Hits() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.hits
+}
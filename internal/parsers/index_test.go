@@ -0,0 +1,108 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestIndex tests that the queries of an index match the parsing
+// functions.
+func TestIndex(t *testing.T) {
+	source := "package main\n\n" +
+		"//go:embed a.txt b.txt\n" +
+		"var ab embed.FS\n"
+	index := NewIndex(source)
+	assert.Equal(t, ParseDirectives(source), index.Directives)
+	assert.Equal(t, ParseEmbedBlocks(source), index.Blocks())
+
+	_, pattern, ok := index.PatternAt(protocol.Position{Line: 2, Character: 18})
+	assert.True(t, ok)
+	assert.Equal(t, "b.txt", pattern.Value)
+	_, ok = index.DirectiveAt(protocol.Position{Line: 2, Character: 11})
+	assert.True(t, ok)
+	block, ok := index.EmbedBlockAt(protocol.Position{Line: 3, Character: 5})
+	assert.True(t, ok)
+	assert.Equal(t, "ab", block.Var)
+	_, ok = index.EmbedBlockAt(protocol.Position{Line: 10, Character: 5})
+	assert.False(t, ok)
+}
+
+// TestVarRange tests that the ranges of variable names count their
+// characters in UTF-16 code units.
+func TestVarRange(t *testing.T) {
+	index := NewIndex("//go:embed a.txt\nvar café string\n\n//go:embed b.txt\nvar 𝒙 string\n")
+	blocks := index.Blocks()
+	if !assert.Len(t, blocks, 2) {
+		return
+	}
+	assert.Equal(t, protocol.Range{
+		Start: protocol.Position{Line: 1, Character: 4},
+		End:   protocol.Position{Line: 1, Character: 8},
+	}, index.VarRange(blocks[0]))
+	assert.Equal(t, protocol.Range{
+		Start: protocol.Position{Line: 4, Character: 4},
+		End:   protocol.Position{Line: 4, Character: 6},
+	}, index.VarRange(blocks[1]))
+}
+
+// TestDocIndex tests that documents are parsed again only when their
+// version or text changes.
+func TestDocIndex(t *testing.T) {
+	cache := NewDocIndex()
+	docURI := uri.File("/tmp/main.go")
+	source := "//go:embed a.txt\nvar a string\n"
+
+	first := cache.Get(docURI, 1, source)
+	assert.Same(t, first, cache.Get(docURI, 1, source))
+	assert.Equal(t, 1, cache.Parses())
+
+	second := cache.Get(docURI, 2, source)
+	assert.NotSame(t, first, second)
+	assert.Equal(t, 2, cache.Parses())
+
+	changed := cache.Get(docURI, 2, "//go:embed b.txt\nvar b string\n")
+	assert.Equal(t, "b.txt", changed.Directives[0].Patterns[0].Value)
+	assert.Equal(t, 3, cache.Parses())
+
+	cache.Invalidate(docURI)
+	cache.Get(docURI, 2, "//go:embed b.txt\nvar b string\n")
+	assert.Equal(t, 4, cache.Parses())
+}
+
+// BenchmarkEmbedBlockAt benchmarks repeated hovers of an embedding
+// variable, reporting the number of parses of the document per hover.
+func BenchmarkEmbedBlockAt(b *testing.B) {
+	var builder strings.Builder
+	builder.WriteString("package main\n\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&builder, "//go:embed static/%d/*.html\nvar v%d embed.FS\n\n", i, i)
+	}
+	source := builder.String()
+	position := protocol.Position{Line: 3, Character: 5}
+	docURI := uri.File("/tmp/main.go")
+
+	b.Run("uncached", func(b *testing.B) {
+		parses := 0
+		for i := 0; i < b.N; i++ {
+			if _, ok := NewIndex(source).EmbedBlockAt(position); !ok {
+				b.Fatal("no block found")
+			}
+			parses++
+		}
+		b.ReportMetric(float64(parses)/float64(b.N), "parses/op")
+	})
+	b.Run("cached", func(b *testing.B) {
+		cache := NewDocIndex()
+		for i := 0; i < b.N; i++ {
+			if _, ok := cache.Get(docURI, 1, source).EmbedBlockAt(position); !ok {
+				b.Fatal("no block found")
+			}
+		}
+		b.ReportMetric(float64(cache.Parses())/float64(b.N), "parses/op")
+	})
+}
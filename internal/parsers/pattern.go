@@ -0,0 +1,146 @@
+package parsers
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+)
+
+var (
+	// errTrailingEscape is returned for a pattern ending in a lone escape.
+	errTrailingEscape = errors.New("trailing escape character")
+	// errUnterminatedClass is returned for a bracket expression without a
+	// closing bracket.
+	errUnterminatedClass = errors.New("unterminated character class")
+	// errEmptyClass is returned for a bracket expression without characters.
+	errEmptyClass = errors.New("empty character class")
+	// errMissingRangeEnd is returned for a character range without an end.
+	errMissingRangeEnd = errors.New("character range is missing its end")
+	// errUnescapedDash is returned for a dash that does not form a range.
+	errUnescapedDash = errors.New("unescaped '-' in character class")
+)
+
+// validatePattern checks the syntax of a go:embed pattern.
+//
+// The syntax is the one accepted by path.Match, so a pattern that passes
+// validation can be globbed without error.
+func validatePattern(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+			if i >= len(pattern) {
+				return errTrailingEscape
+			}
+		case '[':
+			end, err := scanClass(pattern[i+1:])
+			if err != nil {
+				return err
+			}
+			i += end + 1
+		}
+	}
+	return nil
+}
+
+// scanClass scans the body of a bracket expression, the text following
+// its opening bracket, and returns the index of the closing bracket.
+func scanClass(class string) (int, error) {
+	i := 0
+	if i < len(class) && class[i] == '^' {
+		i++
+	}
+	first := true
+	for {
+		if i >= len(class) {
+			return 0, errUnterminatedClass
+		}
+		if class[i] == ']' {
+			if first {
+				return 0, errEmptyClass
+			}
+			return i, nil
+		}
+		first = false
+		n, err := scanClassChar(class[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+		if i < len(class) && class[i] == '-' {
+			i++
+			if i >= len(class) {
+				return 0, errUnterminatedClass
+			}
+			if class[i] == ']' {
+				return 0, errMissingRangeEnd
+			}
+			n, err = scanClassChar(class[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+}
+
+// scanClassChar returns the length of the, possibly escaped, character at
+// the start of a bracket expression body.
+func scanClassChar(s string) (int, error) {
+	n := 0
+	if s[0] == '-' {
+		return 0, errUnescapedDash
+	}
+	if s[0] == '\\' {
+		n++
+		if n >= len(s) {
+			return 0, errTrailingEscape
+		}
+	}
+	_, size := utf8.DecodeRuneInString(s[n:])
+	return n + size, nil
+}
+
+// MatchPattern reports whether a pattern embeds the file with the given
+// slash-separated name, relative to the directory of the embedding file.
+//
+// A pattern matching a directory embeds every file below it, except those
+// under an element beginning with '.' or '_' unless the pattern has the
+// "all:" prefix.
+func MatchPattern(pattern, name string) bool {
+	glob, all := resolver.SplitAllPrefix(pattern)
+	below := ""
+	for name != "." && name != "/" {
+		matched, err := path.Match(glob, name)
+		if err != nil {
+			return false
+		}
+		if matched {
+			return all || !hasHiddenElement(below)
+		}
+		below = path.Join(path.Base(name), below)
+		name = path.Dir(name)
+	}
+	return false
+}
+
+// hasHiddenElement reports whether an element of a slash-separated path
+// begins with '.' or '_', which the go command skips when embedding a
+// directory.
+func hasHiddenElement(name string) bool {
+	for _, element := range strings.Split(name, "/") {
+		if strings.HasPrefix(element, ".") || strings.HasPrefix(element, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLiteralPattern reports whether a pattern contains no glob
+// metacharacters and therefore names a single path.
+func IsLiteralPattern(pattern string) bool {
+	return !strings.ContainsAny(pattern, "*?[\\")
+}
@@ -0,0 +1,78 @@
+package parsers
+
+import (
+	"path"
+	"testing"
+)
+
+// TestValidatePattern tests the validatePattern function.
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr error
+	}{
+		{name: "literal", pattern: "file.txt", wantErr: nil},
+		{name: "star", pattern: "static/*.html", wantErr: nil},
+		{name: "question mark", pattern: "image?.png", wantErr: nil},
+		{name: "class", pattern: "[abc].txt", wantErr: nil},
+		{name: "negated class", pattern: "[^abc].txt", wantErr: nil},
+		{name: "range", pattern: "file[0-9].txt", wantErr: nil},
+		{name: "escaped star", pattern: "file\\*.txt", wantErr: nil},
+		{name: "escaped bracket in class", pattern: "[\\]].txt", wantErr: nil},
+		{name: "unicode range", pattern: "[α-ω].txt", wantErr: nil},
+		{name: "open range", pattern: "[a-", wantErr: errUnterminatedClass},
+		{name: "unterminated class", pattern: "[abc", wantErr: errUnterminatedClass},
+		{name: "lone escape", pattern: "\\", wantErr: errTrailingEscape},
+		{name: "trailing escape", pattern: "file.txt\\", wantErr: errTrailingEscape},
+		{name: "escape in class", pattern: "[\\", wantErr: errTrailingEscape},
+		{name: "empty class", pattern: "[]", wantErr: errEmptyClass},
+		{name: "missing range end", pattern: "[a-]", wantErr: errMissingRangeEnd},
+		{name: "leading dash", pattern: "[-a]", wantErr: errUnescapedDash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePattern(tt.pattern)
+			if err != tt.wantErr {
+				t.Fatalf("validatePattern(%q) = %v, want %v", tt.pattern, err, tt.wantErr)
+			}
+			// validatePattern must agree with path.Match on what is
+			// malformed.
+			_, matchErr := path.Match(tt.pattern, "")
+			if (matchErr != nil) != (tt.wantErr != nil) {
+				t.Errorf("path.Match(%q) error = %v, validatePattern error = %v", tt.pattern, matchErr, err)
+			}
+		})
+	}
+}
+
+// TestMatchPattern tests which files are embedded by a pattern, with the
+// hidden files of a matched directory only embedded with the "all:"
+// prefix.
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		file    string
+		want    bool
+	}{
+		{name: "file", pattern: "a.txt", file: "a.txt", want: true},
+		{name: "other file", pattern: "a.txt", file: "b.txt", want: false},
+		{name: "glob", pattern: "*.txt", file: "b.txt", want: true},
+		{name: "directory", pattern: "dir", file: "dir/a.txt", want: true},
+		{name: "directory hidden file", pattern: "dir", file: "dir/.hidden", want: false},
+		{name: "directory underscore file", pattern: "dir", file: "dir/_a.txt", want: false},
+		{name: "directory hidden subdirectory", pattern: "dir", file: "dir/.git/config", want: false},
+		{name: "all directory hidden file", pattern: "all:dir", file: "dir/.hidden", want: true},
+		{name: "all directory hidden subdirectory", pattern: "all:dir", file: "dir/.git/config", want: true},
+		{name: "all file", pattern: "all:a.txt", file: "a.txt", want: true},
+		{name: "explicit hidden file", pattern: "dir/.hidden", file: "dir/.hidden", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchPattern(tt.pattern, tt.file); got != tt.want {
+				t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package parsers
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.lsp.dev/protocol"
+)
+
+// ApplyChange returns the text with a range, whose characters are counted
+// in UTF-16 code units, replaced by newText.
+//
+// Positions past the end of their line are clamped to the end of the line
+// and lines past the end of the text to the end of the text.
+func ApplyChange(text string, changed protocol.Range, newText string) string {
+	lines := NewLineIndex(text)
+	start := lines.Offset(changed.Start)
+	end := lines.Offset(changed.End)
+	if end < start {
+		end = start
+	}
+	return text[:start] + newText + text[end:]
+}
+
+// LineIndex converts between the positions of a document and byte offsets
+// into it.
+//
+// It records where the lines of the document start, so that converting
+// many positions of a large document does not scan it each time.
+type LineIndex struct {
+	text string
+	// starts are the byte offsets of the starts of the lines.
+	starts []int
+}
+
+// NewLineIndex indexes the lines of a document.
+func NewLineIndex(text string) *LineIndex {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &LineIndex{text: text, starts: starts}
+}
+
+// line returns the text of a line of the document without its line
+// ending.
+func (x *LineIndex) line(n int) string {
+	end := len(x.text)
+	if n+1 < len(x.starts) {
+		end = x.starts[n+1] - 1
+	}
+	return strings.TrimSuffix(x.text[x.starts[n]:end], "\r")
+}
+
+// Offset returns the byte offset of a position whose character is counted
+// in UTF-16 code units.
+//
+// Positions past the end of their line are clamped to the end of the line
+// and lines past the end of the document to the end of the document.
+func (x *LineIndex) Offset(position protocol.Position) int {
+	n := int(position.Line)
+	if n >= len(x.starts) {
+		return len(x.text)
+	}
+	return x.starts[n] + utf16OffsetToByte(x.line(n), int(position.Character))
+}
+
+// Position returns the position of a byte offset, its character counted in
+// UTF-16 code units.
+//
+// Offsets are clamped to the document, and offsets inside a rune resolve
+// to the start of the rune.
+func (x *LineIndex) Position(offset int) protocol.Position {
+	offset = max(0, min(offset, len(x.text)))
+	for offset > 0 && offset < len(x.text) && !utf8.RuneStart(x.text[offset]) {
+		offset--
+	}
+	n := sort.Search(len(x.starts), func(i int) bool {
+		return x.starts[i] > offset
+	}) - 1
+	return protocol.Position{
+		Line:      uint32(n),
+		Character: uint32(byteToUTF16Offset(x.line(n), offset-x.starts[n])),
+	}
+}
+
+// column returns the position of a byte offset into a line.
+func (x *LineIndex) column(line int, offset int) protocol.Position {
+	return x.Position(x.starts[line] + offset)
+}
+
+// utf16OffsetToByte converts a character offset counted in UTF-16 code
+// units, as sent by LSP clients, to a byte offset into the given line.
+//
+// Offsets past the end of the line are clamped to the length of the line
+// and offsets falling inside a surrogate pair resolve to the start of the
+// rune.
+func utf16OffsetToByte(line string, utf16Col int) int {
+	units := 0
+	for i, r := range line {
+		if units >= utf16Col {
+			return i
+		}
+		units += utf16Len(r)
+		if units > utf16Col {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode a rune.
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+// byteToUTF16Offset converts a byte offset into the given line to a
+// character offset counted in UTF-16 code units, as expected by LSP
+// clients.
+func byteToUTF16Offset(line string, offset int) int {
+	units := 0
+	for i, r := range line {
+		if i >= offset {
+			break
+		}
+		units += utf16Len(r)
+	}
+	return units
+}
@@ -0,0 +1,146 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestUTF16OffsetToByte tests the utf16OffsetToByte function.
+func TestUTF16OffsetToByte(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		utf16Col int
+		want     int
+	}{
+		{name: "ascii", line: "//go:embed a.txt", utf16Col: 11, want: 11},
+		{name: "start", line: "é", utf16Col: 0, want: 0},
+		{name: "accented", line: "café.txt", utf16Col: 4, want: 5},
+		{name: "emoji surrogate pair", line: "🎉.txt", utf16Col: 2, want: 4},
+		{name: "inside surrogate pair", line: "🎉.txt", utf16Col: 1, want: 0},
+		{name: "past end", line: "ab", utf16Col: 10, want: 2},
+		{name: "cjk", line: "日本.txt", utf16Col: 2, want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := utf16OffsetToByte(tt.line, tt.utf16Col)
+			if got != tt.want {
+				t.Errorf("utf16OffsetToByte(%q, %d) = %d, want %d", tt.line, tt.utf16Col, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestByteToUTF16Offset tests the byteToUTF16Offset function.
+func TestByteToUTF16Offset(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		offset int
+		want   int
+	}{
+		{name: "ascii", line: "//go:embed a.txt", offset: 11, want: 11},
+		{name: "accented", line: "café.txt", offset: 5, want: 4},
+		{name: "emoji surrogate pair", line: "🎉.txt", offset: 4, want: 2},
+		{name: "end of line", line: "日本.txt", offset: 10, want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := byteToUTF16Offset(tt.line, tt.offset)
+			if got != tt.want {
+				t.Errorf("byteToUTF16Offset(%q, %d) = %d, want %d", tt.line, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyChange tests replacing ranges of multi-line, multi-byte texts.
+func TestApplyChange(t *testing.T) {
+	rng := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+	tests := []struct {
+		name    string
+		text    string
+		changed protocol.Range
+		newText string
+		want    string
+	}{
+		{name: "insert", text: "ab\ncd\n", changed: rng(1, 1, 1, 1), newText: "x", want: "ab\ncxd\n"},
+		{name: "replace across lines", text: "ab\ncd\n", changed: rng(0, 1, 1, 1), newText: "-", want: "a-d\n"},
+		{name: "start of text", text: "ab\n", changed: rng(0, 0, 0, 0), newText: "x", want: "xab\n"},
+		{name: "multi-byte", text: "//🎉 é.txt\n", changed: rng(0, 5, 0, 6), newText: "e", want: "//🎉 e.txt\n"},
+		{name: "past end of line", text: "ab\ncd", changed: rng(0, 9, 0, 9), newText: "!", want: "ab!\ncd"},
+		{name: "crlf", text: "ab\r\ncd", changed: rng(0, 9, 0, 9), newText: "!", want: "ab!\r\ncd"},
+		{name: "past end of text", text: "ab", changed: rng(5, 0, 5, 0), newText: "\ncd", want: "ab\ncd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyChange(tt.text, tt.changed, tt.newText); got != tt.want {
+				t.Errorf("ApplyChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLineIndex tests converting positions of multi-line, multi-byte
+// documents to byte offsets and back.
+func TestLineIndex(t *testing.T) {
+	doc := "package main\r\n\n//go:embed é/🎉.txt\nvar s string"
+	tests := []struct {
+		name     string
+		position protocol.Position
+		offset   int
+		// back is the position of the offset when the position is not
+		// the canonical one of the offset.
+		back *protocol.Position
+	}{
+		{name: "start", position: protocol.Position{Line: 0, Character: 0}, offset: 0},
+		{name: "end of crlf line", position: protocol.Position{Line: 0, Character: 12}, offset: 12},
+		{
+			name:     "past end of crlf line",
+			position: protocol.Position{Line: 0, Character: 40},
+			offset:   12,
+			back:     &protocol.Position{Line: 0, Character: 12},
+		},
+		{name: "empty line", position: protocol.Position{Line: 1, Character: 0}, offset: 14},
+		{name: "two-byte rune", position: protocol.Position{Line: 2, Character: 11}, offset: 26},
+		{name: "after two-byte rune", position: protocol.Position{Line: 2, Character: 12}, offset: 28},
+		{name: "after surrogate pair", position: protocol.Position{Line: 2, Character: 15}, offset: 33},
+		{
+			name:     "inside surrogate pair",
+			position: protocol.Position{Line: 2, Character: 14},
+			offset:   29,
+			back:     &protocol.Position{Line: 2, Character: 13},
+		},
+		{name: "last line", position: protocol.Position{Line: 3, Character: 4}, offset: 42},
+		{name: "end of document", position: protocol.Position{Line: 3, Character: 12}, offset: 50},
+		{
+			name:     "past end of document",
+			position: protocol.Position{Line: 9, Character: 0},
+			offset:   50,
+			back:     &protocol.Position{Line: 3, Character: 12},
+		},
+	}
+	lines := NewLineIndex(doc)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.offset, lines.Offset(tt.position))
+			back := tt.position
+			if tt.back != nil {
+				back = *tt.back
+			}
+			assert.Equal(t, back, lines.Position(tt.offset))
+		})
+	}
+
+	// offsets inside a rune resolve to its start
+	assert.Equal(t, protocol.Position{Line: 2, Character: 11}, lines.Position(27))
+	assert.Equal(t, protocol.Position{Line: 2, Character: 13}, lines.Position(31))
+	assert.Equal(t, protocol.Position{Line: 0, Character: 0}, lines.Position(-3))
+}
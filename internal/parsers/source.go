@@ -24,6 +24,12 @@ var (
 )
 
 // ParseSourcePosition parses a source position from a string.
+//
+// When the position is on a go:embed directive it returns the pattern under
+// the position, or the first pattern of the directive when the position is
+// on the directive itself.
+//
+// The character of the position is counted in UTF-16 code units.
 func ParseSourcePosition(
 	source *string,
 	position protocol.Position,
@@ -33,14 +39,39 @@ func ParseSourcePosition(
 	}
 	// split the source string into lines
 	lines := strings.Split(*source, "\n")
-	line := lines[position.Line]
+	if int(position.Line) >= len(lines) {
+		// stale positions may point past the end of the document
+		return "", StateUnknown, nil
+	}
+	line := strings.TrimSuffix(lines[position.Line], "\r")
 	log.Debugf("current line: %s", line)
 	if len(line) == 0 {
 		return "", StateUnknown, nil
 	}
-	if strings.HasPrefix(line, "//g") {
-		log.Debugf("found //go")
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, "//") && !strings.HasPrefix(trimmed, "/*") {
+		return "", StateUnknown, nil
+	}
+	match := embedRegex.FindStringSubmatchIndex(line)
+	if match == nil {
 		return "", StateInComment, nil
 	}
-	return "", StateUnknown, nil
+	start, end := match[2], match[3]
+	if start < 0 {
+		start, end = match[4], match[5]
+	}
+	patterns := tokenizeArgs(line, start, end, int(position.Line))
+	if len(patterns) == 0 {
+		return "", StateInComment, nil
+	}
+	cursor := utf16OffsetToByte(line, int(position.Character))
+	if cursor < patterns[0].Start {
+		return patterns[0].Value, StateInComment, nil
+	}
+	for _, pattern := range patterns {
+		if pattern.Start <= cursor && cursor <= pattern.End {
+			return pattern.Value, StateInComment, nil
+		}
+	}
+	return "", StateInComment, nil
 }
@@ -19,7 +19,15 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "nil source",
 			source:    nil,
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
+			wantStr:   "",
+			wantState: StateUnknown,
+			wantErr:   false,
+		},
+		{
+			name:      "line past end of source",
+			source:    ptrToStr("//go:embed file.txt\nvar f string"),
+			position:  protocol.Position{Line: 5, Character: 0},
 			wantStr:   "",
 			wantState: StateUnknown,
 			wantErr:   false,
@@ -27,7 +35,7 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "empty line",
 			source:    ptrToStr(""),
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
 			wantStr:   "",
 			wantState: StateUnknown,
 			wantErr:   false,
@@ -35,7 +43,7 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "line is a comment with go:embed directive",
 			source:    ptrToStr("// go:embed file.txt"),
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
 			wantStr:   "file.txt",
 			wantState: StateInComment,
 			wantErr:   false,
@@ -43,7 +51,7 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "line is a comment without go:embed directive",
 			source:    ptrToStr("// This is a comment"),
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
 			wantStr:   "",
 			wantState: StateInComment,
 			wantErr:   false,
@@ -51,7 +59,7 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "line is a comment with go:embed in block comment",
 			source:    ptrToStr("/* go:embed file.txt */"),
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
 			wantStr:   "file.txt",
 			wantState: StateInComment,
 			wantErr:   false,
@@ -59,7 +67,47 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "line is a comment block without go:embed directive",
 			source:    ptrToStr("/* This is a comment */"),
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
+			wantStr:   "",
+			wantState: StateInComment,
+			wantErr:   false,
+		},
+		{
+			name:      "cursor on second pattern",
+			source:    ptrToStr("//go:embed a.txt b.txt"),
+			position:  protocol.Position{Line: 0, Character: 19},
+			wantStr:   "b.txt",
+			wantState: StateInComment,
+			wantErr:   false,
+		},
+		{
+			name:      "cursor on later line",
+			source:    ptrToStr("package main\n\n//go:embed a.txt\nvar a string"),
+			position:  protocol.Position{Line: 2, Character: 13},
+			wantStr:   "a.txt",
+			wantState: StateInComment,
+			wantErr:   false,
+		},
+		{
+			name:      "accented characters before pattern",
+			source:    ptrToStr("//go:embed café.txt b.txt"),
+			position:  protocol.Position{Line: 0, Character: 21},
+			wantStr:   "b.txt",
+			wantState: StateInComment,
+			wantErr:   false,
+		},
+		{
+			name:      "emoji before pattern",
+			source:    ptrToStr("//go:embed 🎉.txt b.txt"),
+			position:  protocol.Position{Line: 0, Character: 18},
+			wantStr:   "b.txt",
+			wantState: StateInComment,
+			wantErr:   false,
+		},
+		{
+			name:      "emoji before cursor between patterns",
+			source:    ptrToStr("//go:embed 🎉.txt  b.txt"),
+			position:  protocol.Position{Line: 0, Character: 18},
 			wantStr:   "",
 			wantState: StateInComment,
 			wantErr:   false,
@@ -67,7 +115,7 @@ func TestParseSourcePosition(t *testing.T) {
 		{
 			name:      "line is code, not a comment",
 			source:    ptrToStr("fmt.Println(\"Hello, world!\")"),
-			position:  protocol.Position{Line: 1, Character: 0},
+			position:  protocol.Position{Line: 0, Character: 0},
 			wantStr:   "",
 			wantState: StateUnknown,
 			wantErr:   false,
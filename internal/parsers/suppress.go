@@ -0,0 +1,112 @@
+package parsers
+
+import (
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+const (
+	// ignoreComment is the comment suppressing diagnostics on its line and
+	// the line below it.
+	ignoreComment = "//embedpls:ignore"
+	// codePrefix is the prefix of the diagnostic codes, which may be left
+	// out of the codes of an ignore comment.
+	codePrefix = "embed/"
+)
+
+// Suppressions are the diagnostic codes suppressed on the lines of a
+// source, keyed by zero-based line. A line with a nil list of codes has
+// all its diagnostics suppressed.
+type Suppressions map[int][]string
+
+// ParseSuppressions returns the diagnostics suppressed by the
+// "//embedpls:ignore" comments of a source.
+//
+// The comment lists the codes it suppresses, separated by spaces or
+// commas, with or without their "embed/" prefix, as in
+// "//embedpls:ignore path-traversal", and suppresses every diagnostic
+// when it lists none. It applies to its own line, when trailing other
+// code, and to the line below it.
+//
+// The go tool reads whatever follows a //go:embed directive as patterns,
+// so the suppressions of a directive belong on the line above it.
+func ParseSuppressions(source string) Suppressions {
+	suppressions := make(Suppressions)
+	for i, line := range strings.Split(source, "\n") {
+		start := strings.Index(line, ignoreComment)
+		if start < 0 {
+			continue
+		}
+		rest := strings.TrimSuffix(line[start+len(ignoreComment):], "\r")
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != ',' {
+			// another comment such as //embedpls:ignored
+			continue
+		}
+		codes := ignoredCodes(rest)
+		suppressions.add(i, codes)
+		suppressions.add(i+1, codes)
+	}
+	return suppressions
+}
+
+// ignoredCodes returns the codes listed by an ignore comment, with their
+// prefix, or nil when it lists none.
+func ignoredCodes(list string) []string {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+	codes := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !strings.HasPrefix(field, codePrefix) {
+			field = codePrefix + field
+		}
+		codes = append(codes, field)
+	}
+	return codes
+}
+
+// add suppresses codes on a line, nil codes suppressing every diagnostic.
+func (s Suppressions) add(line int, codes []string) {
+	current, ok := s[line]
+	switch {
+	case !ok:
+		s[line] = codes
+	case current == nil || codes == nil:
+		s[line] = nil
+	default:
+		s[line] = append(append([]string{}, current...), codes...)
+	}
+}
+
+// Suppressed reports whether a diagnostic is suppressed.
+func (s Suppressions) Suppressed(diagnostic protocol.Diagnostic) bool {
+	codes, ok := s[int(diagnostic.Range.Start.Line)]
+	if !ok {
+		return false
+	}
+	if codes == nil {
+		return true
+	}
+	for _, code := range codes {
+		if code == diagnostic.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the diagnostics that are not suppressed. The returned
+// slice is never nil.
+func (s Suppressions) Filter(diagnostics []protocol.Diagnostic) []protocol.Diagnostic {
+	filtered := make([]protocol.Diagnostic, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		if !s.Suppressed(diagnostic) {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,68 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSuppressions tests that ignore comments suppress the diagnostics of
+// the codes they list on their own line and the line below.
+func TestSuppressions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "matching code on the line above",
+			source: "//embedpls:ignore path-traversal\n//go:embed ../secret.txt\nvar f embed.FS\n",
+			want:   []string{},
+		},
+		{
+			name:   "matching prefixed code",
+			source: "//embedpls:ignore embed/path-traversal\n//go:embed ../secret.txt\nvar f embed.FS\n",
+			want:   []string{},
+		},
+		{
+			name:   "non-matching code",
+			source: "//embedpls:ignore absolute-path\n//go:embed ../secret.txt\nvar f embed.FS\n",
+			want:   []string{CodePathTraversal},
+		},
+		{
+			name:   "one of several codes",
+			source: "//embedpls:ignore absolute-path, path-traversal\n//go:embed ../secret.txt /etc/hosts [a-\nvar f embed.FS\n",
+			want:   []string{CodeInvalidPattern},
+		},
+		{
+			name:   "every code",
+			source: "//embedpls:ignore\n//go:embed ../secret.txt [a-\nvar f embed.FS\n",
+			want:   []string{},
+		},
+		{
+			name:   "trailing comment",
+			source: "/* go:embed hello.txt */ //embedpls:ignore block-comment\nvar f embed.FS\n",
+			want:   []string{},
+		},
+		{
+			name:   "too far above",
+			source: "//embedpls:ignore path-traversal\n\n//go:embed ../secret.txt\nvar f embed.FS\n",
+			want:   []string{CodePathTraversal},
+		},
+		{
+			name:   "other comment",
+			source: "//embedpls:ignored path-traversal\n//go:embed ../secret.txt\nvar f embed.FS\n",
+			want:   []string{CodePathTraversal},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := ParseSuppressions(tt.source).Filter(Diagnose(tt.source))
+			codes := make([]string, 0, len(diagnostics))
+			for _, diagnostic := range diagnostics {
+				codes = append(codes, diagnostic.Code.(string))
+			}
+			assert.ElementsMatch(t, tt.want, codes)
+		})
+	}
+}
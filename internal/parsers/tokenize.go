@@ -0,0 +1,112 @@
+package parsers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"go.lsp.dev/protocol"
+)
+
+// TokenizeDirective returns the patterns of the go:embed directive held
+// by a line, the lineNum-th zero-based line of its source, or nil when the
+// line holds no directive.
+//
+// Patterns are separated by spaces and may be quoted, with double quotes
+// allowing the escapes of Go string literals or with backquotes, so they
+// can contain spaces themselves. The value of a quoted pattern is
+// unquoted while its offsets and range cover its quotes. The "all:"
+// prefix of a pattern is kept in its value but stripped from its glob.
+//
+// Every feature ranging over patterns relies on this tokenizer so that
+// they all agree on the columns of a pattern, counted in UTF-16 code
+// units in its range.
+func TokenizeDirective(line string, lineNum int) []PatternToken {
+	line = strings.TrimSuffix(line, "\r")
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, embedDirective) {
+		return nil
+	}
+	offset := len(line) - len(trimmed) + len(embedDirective)
+	if offset < len(line) && line[offset] != ' ' && line[offset] != '\t' {
+		return nil
+	}
+	return tokenizeArgs(line, offset, len(line), lineNum)
+}
+
+// QuotePattern returns a pattern as written in a directive, quoted when it
+// holds spaces or quotes that would otherwise split or quote it.
+func QuotePattern(pattern string) string {
+	if strings.ContainsAny(pattern, " \t\"`") {
+		return strconv.Quote(pattern)
+	}
+	return pattern
+}
+
+// tokenizeArgs splits the arguments of a directive spanning the bytes
+// from start to end of a line into pattern tokens.
+func tokenizeArgs(line string, start, end, lineNum int) []PatternToken {
+	tokens := make([]PatternToken, 0)
+	for i := start; i < end; {
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+		tokenEnd := scanToken(line[:end], i)
+		value := line[i:tokenEnd]
+		if unquoted, err := strconv.Unquote(value); err == nil &&
+			(value[0] == '"' || value[0] == '`') {
+			value = unquoted
+		}
+		glob, all := resolver.SplitAllPrefix(value)
+		tokens = append(tokens, PatternToken{
+			Value: value,
+			Glob:  glob,
+			All:   all,
+			Start: i,
+			End:   tokenEnd,
+			Range: protocol.Range{
+				Start: protocol.Position{
+					Line:      uint32(lineNum),
+					Character: uint32(byteToUTF16Offset(line, i)),
+				},
+				End: protocol.Position{
+					Line:      uint32(lineNum),
+					Character: uint32(byteToUTF16Offset(line, tokenEnd)),
+				},
+			},
+		})
+		i = tokenEnd
+	}
+	return tokens
+}
+
+// scanToken returns the end of the token starting at the given byte of
+// the arguments of a directive.
+//
+// A quoted token ends after its closing quote, an unterminated one at the
+// end of the arguments, and a bare token before the next space.
+func scanToken(args string, start int) int {
+	switch args[start] {
+	case '`':
+		if end := strings.IndexByte(args[start+1:], '`'); end >= 0 {
+			return start + 1 + end + 1
+		}
+		return len(args)
+	case '"':
+		for i := start + 1; i < len(args); i++ {
+			switch args[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return len(args)
+	}
+	end := strings.IndexAny(args[start:], " \t")
+	if end < 0 {
+		return len(args)
+	}
+	return start + end
+}
@@ -0,0 +1,91 @@
+package parsers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenizeDirective tests the values and ranges of the patterns of
+// directives, with columns counted in UTF-16 code units.
+func TestTokenizeDirective(t *testing.T) {
+	type token struct {
+		value      string
+		start, end uint32
+	}
+	tests := []struct {
+		name string
+		line string
+		want []token
+	}{
+		{
+			name: "bare",
+			line: "//go:embed hello.txt",
+			want: []token{{"hello.txt", 11, 20}},
+		},
+		{
+			name: "multiple patterns",
+			line: "\t//go:embed a.txt  static/*.html\tb",
+			want: []token{{"a.txt", 12, 17}, {"static/*.html", 19, 32}, {"b", 33, 34}},
+		},
+		{
+			name: "double quoted",
+			line: `//go:embed "my file.txt" "tab\t.txt" b.txt`,
+			want: []token{{"my file.txt", 11, 24}, {"tab\t.txt", 25, 36}, {"b.txt", 37, 42}},
+		},
+		{
+			name: "backquoted",
+			line: "//go:embed `my file.txt` b.txt",
+			want: []token{{"my file.txt", 11, 24}, {"b.txt", 25, 30}},
+		},
+		{
+			name: "non-ascii",
+			line: "//go:embed café.txt 😀.txt b.txt",
+			want: []token{{"café.txt", 11, 19}, {"😀.txt", 20, 26}, {"b.txt", 27, 32}},
+		},
+		{
+			name: "carriage return",
+			line: "//go:embed a.txt\r",
+			want: []token{{"a.txt", 11, 16}},
+		},
+		{
+			name: "unterminated quote",
+			line: `//go:embed "a.txt`,
+			want: []token{{`"a.txt`, 11, 17}},
+		},
+		{
+			name: "no patterns",
+			line: "//go:embed",
+			want: []token{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]token, 0)
+			for _, pattern := range TokenizeDirective(tt.line, 7) {
+				assert.Equal(t, uint32(7), pattern.Range.Start.Line)
+				assert.Equal(t, uint32(7), pattern.Range.End.Line)
+				got = append(got, token{
+					pattern.Value,
+					pattern.Range.Start.Character,
+					pattern.Range.End.Character,
+				})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	all := TokenizeDirective(`//go:embed all:static "all:my dir" static`, 0)
+	if assert.Len(t, all, 3) {
+		assert.Equal(t, []string{"all:static", "static", "true"},
+			[]string{all[0].Value, all[0].Glob, fmt.Sprint(all[0].All)})
+		assert.Equal(t, []string{"all:my dir", "my dir", "true"},
+			[]string{all[1].Value, all[1].Glob, fmt.Sprint(all[1].All)})
+		assert.Equal(t, []string{"static", "static", "false"},
+			[]string{all[2].Value, all[2].Glob, fmt.Sprint(all[2].All)})
+	}
+	assert.Equal(t, "a.txt", QuotePattern("a.txt"))
+	assert.Equal(t, `"my file.txt"`, QuotePattern("my file.txt"))
+	assert.Nil(t, TokenizeDirective("// go:embed a.txt", 0))
+	assert.Nil(t, TokenizeDirective("//go:embedded a.txt", 0))
+}
@@ -0,0 +1,154 @@
+package resolver
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FoldCase returns a file system looking up the names of fsys whatever
+// their case, as the default file systems of macOS and Windows do, for
+// the file systems standing for such a system without folding case
+// themselves.
+//
+// Names are looked up as given first, and by their spelling on disk only
+// when missing.
+func FoldCase(fsys FS) FS {
+	return foldFS{fsys: fsys}
+}
+
+// foldFS is a file system looking up the names of another one whatever
+// their case.
+type foldFS struct {
+	fsys FS
+}
+
+// FoldsCase reports that names are looked up whatever their case.
+func (f foldFS) FoldsCase() bool { return true }
+
+// Open opens the named file, whatever its case.
+func (f foldFS) Open(name string) (fs.File, error) {
+	return fold(f.fsys, name, f.fsys.Open)
+}
+
+// Stat returns the information of the named file, whatever its case.
+func (f foldFS) Stat(name string) (fs.FileInfo, error) {
+	return fold(f.fsys, name, f.fsys.Stat)
+}
+
+// Lstat returns the information of the named file without following
+// symbolic links, whatever its case.
+func (f foldFS) Lstat(name string) (fs.FileInfo, error) {
+	return fold(f.fsys, name, f.fsys.Lstat)
+}
+
+// ReadDir returns the entries of the named directory, whatever its case.
+func (f foldFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fold(f.fsys, name, f.fsys.ReadDir)
+}
+
+// ReadFile returns the content of the named file, whatever its case.
+func (f foldFS) ReadFile(name string) ([]byte, error) {
+	return fold(f.fsys, name, f.fsys.ReadFile)
+}
+
+// fold runs a lookup of the named file of fsys, running it again with the
+// spelling on disk of the name when the name is missing.
+func fold[T any](fsys FS, name string, lookup func(string) (T, error)) (T, error) {
+	result, err := lookup(name)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return result, err
+	}
+	root := filepath.VolumeName(name) + string(filepath.Separator)
+	rel, relErr := filepath.Rel(root, name)
+	if relErr != nil {
+		return result, err
+	}
+	onDisk, ok := diskPath(fsys, root, filepath.ToSlash(rel))
+	if !ok {
+		return result, err
+	}
+	return lookup(filepath.Join(root, filepath.FromSlash(onDisk)))
+}
+
+// diskPath returns the spelling on disk of a slash-separated path relative
+// to dir of fsys, whose elements may differ in case from the names on disk.
+//
+// It reports false when an element matches no name of its directory, even
+// ignoring case. Elements equal to "." or ".." are kept as they are.
+func diskPath(fsys FS, dir string, name string) (string, bool) {
+	elements := strings.Split(name, "/")
+	current := dir
+	for i, element := range elements {
+		if element == "" || element == "." || element == ".." {
+			current = filepath.Join(current, element)
+			continue
+		}
+		entries, err := fsys.ReadDir(current)
+		if err != nil {
+			return "", false
+		}
+		found := ""
+		for _, entry := range entries {
+			if entry.Name() == element {
+				found = element
+				break
+			}
+			if found == "" && strings.EqualFold(entry.Name(), element) {
+				found = entry.Name()
+			}
+		}
+		if found == "" {
+			return "", false
+		}
+		elements[i] = found
+		current = filepath.Join(current, found)
+	}
+	return strings.Join(elements, "/"), true
+}
+
+// Miscased returns the spelling on disk of the leading literal elements of
+// a pattern, relative to the package directory dir, reporting false
+// unless it differs from the pattern by case only.
+//
+// Embedded paths keep the spelling of the pattern, so a pattern resolving
+// on a case-insensitive file system still fails to build on others, and
+// its files are missing at run time when read by their name on disk.
+//
+// File systems that do not fold case find a path spelled as on disk, so
+// its directories are only listed when a lookup fails, while those folding
+// case are listed whatever the lookup.
+func Miscased(fsys FS, dir string, pattern string) (string, bool) {
+	glob, _ := SplitAllPrefix(pattern)
+	if glob == "" {
+		return "", false
+	}
+	prefix := literalPrefix(path.Clean(glob))
+	if prefix == "" || prefix == "." {
+		return "", false
+	}
+	if !fsys.FoldsCase() {
+		if _, err := fsys.Lstat(filepath.Join(dir, filepath.FromSlash(prefix))); err == nil {
+			return "", false
+		}
+	}
+	onDisk, ok := diskPath(fsys, dir, prefix)
+	if !ok || onDisk == prefix {
+		return "", false
+	}
+	return onDisk, true
+}
+
+// literalPrefix returns the leading elements of a glob holding no
+// metacharacters, which name a path rather than match names.
+func literalPrefix(glob string) string {
+	elements := strings.Split(glob, "/")
+	for i, element := range elements {
+		if !isLiteral(element) {
+			return strings.Join(elements[:i], "/")
+		}
+	}
+	return glob
+}
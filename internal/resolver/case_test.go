@@ -0,0 +1,138 @@
+package resolver
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiskPath tests finding the spelling on disk of paths differing in
+// case.
+func TestDiskPath(t *testing.T) {
+	dir := writeFiles(t, "Static/App.js", "hello.txt")
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{name: "exact", path: "Static/App.js", want: "Static/App.js", wantOK: true},
+		{name: "lower case", path: "static/app.js", want: "Static/App.js", wantOK: true},
+		{name: "upper case", path: "HELLO.TXT", want: "hello.txt", wantOK: true},
+		{name: "missing", path: "static/missing.js", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := diskPath(OS, dir, tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestFoldCase tests looking files up whatever their case.
+func TestFoldCase(t *testing.T) {
+	dir := writeFiles(t, "Static/App.js")
+	fsys := FoldCase(OS)
+	assert.True(t, fsys.FoldsCase())
+
+	data, err := fsys.ReadFile(filepath.Join(dir, "static", "APP.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Static/App.js", string(data))
+
+	_, err = fsys.Stat(filepath.Join(dir, "static", "missing.js"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+// TestMiscased tests finding the patterns whose case differs from the
+// names on disk with and without case folding.
+func TestMiscased(t *testing.T) {
+	dir := writeFiles(t, "Static/App.js", "Static/site.css", "hello.txt")
+	tests := []struct {
+		name    string
+		fsys    FS
+		pattern string
+		want    string
+		wantOK  bool
+	}{
+		{name: "exact case", fsys: OS, pattern: "Static/*.js"},
+		{name: "exact case folded", fsys: FoldCase(OS), pattern: "Static/*.js"},
+		{name: "file", fsys: OS, pattern: "Hello.txt", want: "hello.txt", wantOK: true},
+		{
+			name:    "folded directory of a glob",
+			fsys:    FoldCase(OS),
+			pattern: "static/*.css",
+			want:    "Static",
+			wantOK:  true,
+		},
+		{name: "all: directory", fsys: OS, pattern: "all:static", want: "Static", wantOK: true},
+		{name: "missing", fsys: OS, pattern: "missing.txt"},
+		{name: "glob only", fsys: OS, pattern: "*.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Miscased(tt.fsys, dir, tt.pattern)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestInspectCase tests that patterns whose case differs from the names on
+// disk resolve only where case is folded, their files keeping the spelling
+// of the pattern.
+func TestInspectCase(t *testing.T) {
+	dir := writeFiles(t, "Static/App.js", "Static/site.css", "hello.txt")
+	tests := []struct {
+		name      string
+		fsys      FS
+		pattern   string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:      "exact case",
+			fsys:      OS,
+			pattern:   "Static/*.js",
+			wantFiles: []string{"Static/App.js"},
+		},
+		{
+			name:      "folded file",
+			fsys:      FoldCase(OS),
+			pattern:   "Hello.txt",
+			wantFiles: []string{"Hello.txt"},
+		},
+		{
+			name:      "folded directory of a glob",
+			fsys:      FoldCase(OS),
+			pattern:   "static/*.css",
+			wantFiles: []string{"static/site.css"},
+		},
+		{
+			name:      "case-sensitive file",
+			fsys:      OS,
+			pattern:   "Hello.txt",
+			wantFiles: []string{},
+			wantErr:   true,
+		},
+		{
+			name:      "case-sensitive directory",
+			fsys:      OS,
+			pattern:   "all:static",
+			wantFiles: []string{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution, err := Inspect(context.Background(), tt.fsys, dir, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inspect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantFiles, resolution.Files)
+		})
+	}
+}
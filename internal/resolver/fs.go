@@ -0,0 +1,183 @@
+package resolver
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FS is the file system patterns are resolved against, naming files by
+// their path on the host.
+//
+// It lets the features reading the files of a package run against an
+// in-memory file system, such as an fstest.MapFS wrapped by FromFS.
+type FS interface {
+	// Open opens the named file for reading.
+	Open(name string) (fs.File, error)
+	// Stat returns the information of the named file, following symbolic
+	// links.
+	Stat(name string) (fs.FileInfo, error)
+	// Lstat returns the information of the named file, describing
+	// symbolic links rather than their target.
+	Lstat(name string) (fs.FileInfo, error)
+	// ReadDir returns the entries of the named directory, sorted by name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// ReadFile returns the content of the named file.
+	ReadFile(name string) ([]byte, error)
+	// FoldsCase reports whether names are looked up whatever their case,
+	// as on the default file systems of macOS and Windows, where patterns
+	// resolve even when their case differs from the names on disk.
+	FoldsCase() bool
+}
+
+// OS is the file system of the host.
+var OS FS = osFS{}
+
+// osFS is the file system of the host.
+type osFS struct{}
+
+// Open opens the named file of the host.
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+// Stat returns the information of the named file of the host.
+func (osFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+// Lstat returns the information of the named file of the host without
+// following symbolic links.
+func (osFS) Lstat(name string) (fs.FileInfo, error) { return os.Lstat(name) }
+
+// ReadDir returns the entries of the named directory of the host.
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+// ReadFile returns the content of the named file of the host.
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// FoldsCase reports whether the host is macOS or Windows, whose default
+// file systems look names up whatever their case.
+func (osFS) FoldsCase() bool {
+	return runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+}
+
+// FromFS returns the file system holding each file of fsys at its name
+// made absolute, so that "static/app.js" of fsys is "/static/app.js".
+//
+// The files of fsys are never symbolic links, so Lstat is Stat, and their
+// names are case-sensitive; FoldCase makes them case-insensitive.
+func FromFS(fsys fs.FS) FS {
+	return mappedFS{fsys: fsys}
+}
+
+// mappedFS is a file system whose files are those of an fs.FS.
+type mappedFS struct {
+	fsys fs.FS
+}
+
+// name returns the name in the fs.FS of an absolute path of the host.
+func (m mappedFS) name(op, path string) (string, error) {
+	name := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	return name, nil
+}
+
+// Open opens the named file of the fs.FS.
+func (m mappedFS) Open(path string) (fs.File, error) {
+	name, err := m.name("open", path)
+	if err != nil {
+		return nil, err
+	}
+	return m.fsys.Open(name)
+}
+
+// Stat returns the information of the named file of the fs.FS.
+func (m mappedFS) Stat(path string) (fs.FileInfo, error) {
+	name, err := m.name("stat", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(m.fsys, name)
+}
+
+// Lstat returns the information of the named file of the fs.FS.
+func (m mappedFS) Lstat(path string) (fs.FileInfo, error) {
+	return m.Stat(path)
+}
+
+// ReadDir returns the entries of the named directory of the fs.FS.
+func (m mappedFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	name, err := m.name("readdir", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(m.fsys, name)
+}
+
+// ReadFile returns the content of the named file of the fs.FS.
+func (m mappedFS) ReadFile(path string) ([]byte, error) {
+	name, err := m.name("readfile", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(m.fsys, name)
+}
+
+// FoldsCase reports that the names of the fs.FS are case-sensitive.
+func (m mappedFS) FoldsCase() bool { return false }
+
+// packageFS is the fs.FS of the files of a package directory, named by
+// their slash-separated path relative to it as patterns name them.
+type packageFS struct {
+	fsys FS
+	dir  string
+}
+
+// path returns the path on the host of a name of the package.
+func (p packageFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(p.dir, filepath.FromSlash(name)), nil
+}
+
+// Open opens the named file of the package.
+func (p packageFS) Open(name string) (fs.File, error) {
+	path, err := p.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return p.fsys.Open(path)
+}
+
+// Stat returns the information of the named file of the package.
+func (p packageFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := p.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return p.fsys.Stat(path)
+}
+
+// ReadDir returns the entries of the named directory of the package.
+func (p packageFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := p.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return p.fsys.ReadDir(path)
+}
+
+// lstat returns the information of the named file of the package without
+// following symbolic links.
+func (p packageFS) lstat(name string) (fs.FileInfo, error) {
+	path, err := p.path("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return p.fsys.Lstat(path)
+}
@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInspectFromFS tests resolving patterns against an in-memory file
+// system, whose files are at their name made absolute.
+func TestInspectFromFS(t *testing.T) {
+	fsys := FromFS(fstest.MapFS{
+		"pkg/main.go":             {Data: []byte("package main\n")},
+		"pkg/hello.txt":           {Data: []byte("hello")},
+		"pkg/static/app.js":       {Data: []byte("app")},
+		"pkg/static/.hidden":      {Data: []byte("hidden")},
+		"pkg/static/css/site.css": {Data: []byte("site")},
+		"pkg/static/link.txt":     {Data: []byte("hello.txt"), Mode: fs.ModeSymlink},
+		"pkg/static/plugin/go.mod": {
+			Data: []byte("module example.com/plugin\n"),
+		},
+	})
+	tests := []struct {
+		name         string
+		pattern      string
+		wantFiles    []string
+		wantModules  []string
+		wantSymlinks []string
+		wantErr      bool
+	}{
+		{
+			name:         "file",
+			pattern:      "hello.txt",
+			wantFiles:    []string{"hello.txt"},
+			wantModules:  []string{},
+			wantSymlinks: []string{},
+		},
+		{
+			name:         "glob",
+			pattern:      "*.txt",
+			wantFiles:    []string{"hello.txt"},
+			wantModules:  []string{},
+			wantSymlinks: []string{},
+		},
+		{
+			name:         "directory",
+			pattern:      "static",
+			wantFiles:    []string{"static/app.js", "static/css/site.css"},
+			wantModules:  []string{"static/plugin"},
+			wantSymlinks: []string{"static/link.txt"},
+		},
+		{
+			name:         "all prefix directory",
+			pattern:      "all:static",
+			wantFiles:    []string{"static/.hidden", "static/app.js", "static/css/site.css"},
+			wantModules:  []string{"static/plugin"},
+			wantSymlinks: []string{"static/link.txt"},
+		},
+		{
+			name:         "missing",
+			pattern:      "missing.txt",
+			wantFiles:    []string{},
+			wantModules:  []string{},
+			wantSymlinks: []string{},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution, err := Inspect(context.Background(), fsys, "/pkg", tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inspect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantFiles, resolution.Files)
+			assert.Equal(t, tt.wantModules, resolution.NestedModules)
+			assert.Equal(t, tt.wantSymlinks, resolution.Symlinks)
+		})
+	}
+}
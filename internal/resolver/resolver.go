@@ -0,0 +1,228 @@
+// Package resolver resolves go:embed patterns to the files they embed.
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// AllPrefix is the pattern prefix including the hidden files of the
+	// directories matched by the pattern.
+	AllPrefix = "all:"
+)
+
+// Resolve returns the slash-separated names, relative to dir of fsys, of
+// the files embedded by the patterns, sorted and without duplicates.
+//
+// It follows the rules of the go command: the files of a matched directory
+// are embedded recursively, except those whose name begins with '.' or '_'
+// unless the pattern has the "all:" prefix, those of nested modules and
+// symbolic links, which are never followed.
+func Resolve(ctx context.Context, fsys FS, dir string, patterns []string) ([]string, error) {
+	return ResolveProgress(ctx, fsys, dir, patterns, nil)
+}
+
+// ResolveProgress is like Resolve but calls report, when not nil, before
+// resolving each pattern with the number of patterns already resolved.
+func ResolveProgress(
+	ctx context.Context,
+	fsys FS,
+	dir string,
+	patterns []string,
+	report func(pattern string, done, total int),
+) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	for i, pattern := range patterns {
+		if report != nil {
+			report(pattern, i, len(patterns))
+		}
+		matched, err := ResolvePattern(ctx, fsys, dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matched {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SplitAllPrefix returns a pattern without its "all:" prefix, reporting
+// whether it had one.
+//
+// The prefix is not part of the path matched by the pattern, so it must be
+// stripped before globbing or checking the pattern, while whether it was
+// present decides if the hidden files of matched directories are embedded.
+func SplitAllPrefix(pattern string) (string, bool) {
+	if strings.HasPrefix(pattern, AllPrefix) {
+		return strings.TrimPrefix(pattern, AllPrefix), true
+	}
+	return pattern, false
+}
+
+// ResolvePattern returns the slash-separated names, relative to dir, of
+// the files embedded by a single pattern, sorted.
+//
+// An error is returned when the pattern matches no file, as the go command
+// would, or when ctx is cancelled while walking the matched directories.
+func ResolvePattern(ctx context.Context, fsys FS, dir string, pattern string) ([]string, error) {
+	resolution, err := Inspect(ctx, fsys, dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return resolution.Files, nil
+}
+
+// Resolution is what a pattern resolves to, with the slash-separated
+// names relative to the package directory, sorted.
+type Resolution struct {
+	// Files are the files embedded by the pattern.
+	Files []string
+	// NestedModules are the directories holding a go.mod file that the
+	// pattern matches or crosses into, whose files the go command leaves
+	// out as they belong to another module.
+	NestedModules []string
+	// Symlinks are the symbolic links that the pattern matches or crosses,
+	// which the go command refuses to embed.
+	Symlinks []string
+}
+
+// Inspect resolves a pattern like ResolvePattern, also reporting what the
+// pattern matches without embedding it.
+//
+// When the pattern embeds no file, the error comes with the resolution of
+// what it matched nonetheless.
+func Inspect(ctx context.Context, fsys FS, dir string, pattern string) (Resolution, error) {
+	resolution := Resolution{
+		Files:         make([]string, 0),
+		NestedModules: make([]string, 0),
+		Symlinks:      make([]string, 0),
+	}
+	if err := ctx.Err(); err != nil {
+		return Resolution{}, fmt.Errorf("pattern %s: %w", pattern, err)
+	}
+	pkg := packageFS{fsys: fsys, dir: dir}
+	glob, all := SplitAllPrefix(pattern)
+	if glob != "" {
+		// "./static" names what "static" names, and the names of the
+		// embedded files are those of the cleaned pattern
+		glob = path.Clean(glob)
+	}
+	if isLiteral(glob) {
+		// most patterns name a single file or directory, which a stat
+		// finds without globbing; missing files take the glob path
+		if info, err := pkg.lstat(glob); err == nil {
+			switch {
+			case info.Mode().IsRegular():
+				resolution.Files = append(resolution.Files, glob)
+				return resolution, nil
+			case info.IsDir():
+				if err := resolution.walk(ctx, pkg, glob, all); err != nil {
+					return Resolution{}, fmt.Errorf("pattern %s: %w", pattern, err)
+				}
+				return resolution.sorted(pattern)
+			}
+		}
+	}
+	matches, err := fs.Glob(pkg, glob)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("pattern %s: %w", pattern, err)
+	}
+	for _, match := range matches {
+		info, err := pkg.lstat(match)
+		if err != nil {
+			return Resolution{}, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			resolution.Symlinks = append(resolution.Symlinks, match)
+			continue
+		}
+		if !info.IsDir() {
+			if info.Mode().IsRegular() {
+				resolution.Files = append(resolution.Files, match)
+			}
+			continue
+		}
+		if err := resolution.walk(ctx, pkg, match, all); err != nil {
+			return Resolution{}, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+	}
+	return resolution.sorted(pattern)
+}
+
+// walk adds the files of a matched directory of the package to the
+// resolution, recursively, leaving out hidden files unless all is set.
+func (r *Resolution) walk(ctx context.Context, pkg packageFS, match string, all bool) error {
+	return fs.WalkDir(pkg, match, func(
+		name string,
+		entry fs.DirEntry,
+		err error,
+	) error {
+		if err != nil {
+			return err
+		}
+		// large trees take a while to walk, so cancellation is
+		// checked at every entry
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if name != match && !all && isHidden(entry.Name()) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case entry.IsDir() && isModule(pkg, name):
+			r.NestedModules = append(r.NestedModules, name)
+			return fs.SkipDir
+		case entry.Type()&fs.ModeSymlink != 0:
+			r.Symlinks = append(r.Symlinks, name)
+		case entry.Type().IsRegular():
+			r.Files = append(r.Files, name)
+		}
+		return nil
+	})
+}
+
+// sorted returns the resolution with its names sorted, with an error when
+// the pattern embeds no file.
+func (r *Resolution) sorted(pattern string) (Resolution, error) {
+	sort.Strings(r.NestedModules)
+	sort.Strings(r.Symlinks)
+	if len(r.Files) == 0 {
+		return *r, fmt.Errorf("pattern %s: no matching files found", pattern)
+	}
+	sort.Strings(r.Files)
+	return *r, nil
+}
+
+// isModule reports whether a directory of the package holds a go.mod file,
+// making it the root of another module.
+func isModule(pkg packageFS, name string) bool {
+	info, err := pkg.Stat(path.Join(name, "go.mod"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// isLiteral reports whether a pattern has no glob metacharacters or
+// escapes, so that it names a single file or directory.
+func isLiteral(pattern string) bool {
+	return !strings.ContainsAny(pattern, `*?[\`)
+}
+
+// isHidden reports whether a file is left out of the directories embedded
+// by patterns without the "all:" prefix.
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
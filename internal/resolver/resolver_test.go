@@ -0,0 +1,297 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles writes files, given by their slash-separated names, into a
+// temporary directory and returns it.
+func writeFiles(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestResolve tests resolving patterns to the files they embed.
+func TestResolve(t *testing.T) {
+	dir := writeFiles(t,
+		"hello.txt",
+		"logo.png",
+		"static/app.js",
+		"static/.hidden",
+		"static/_draft.css",
+		"static/css/site.css",
+		"static/.git/config",
+		"templates/index.html",
+		"templates/_partial.html",
+		"templates/layouts/base.html",
+		"templates/layouts/.swp",
+	)
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "single file",
+			patterns: []string{"hello.txt"},
+			want:     []string{"hello.txt"},
+		},
+		{
+			name:     "current directory prefix",
+			patterns: []string{"./hello.txt", "./static/css"},
+			want:     []string{"hello.txt", "static/css/site.css"},
+		},
+		{
+			name:     "glob",
+			patterns: []string{"*.png", "*.txt"},
+			want:     []string{"hello.txt", "logo.png"},
+		},
+		{
+			name:     "directory skips hidden files",
+			patterns: []string{"static"},
+			want:     []string{"static/app.js", "static/css/site.css"},
+		},
+		{
+			name:     "whole templates directory",
+			patterns: []string{"templates"},
+			want:     []string{"templates/index.html", "templates/layouts/base.html"},
+		},
+		{
+			name:     "all prefix includes hidden files",
+			patterns: []string{"all:static"},
+			want: []string{
+				"static/.git/config",
+				"static/.hidden",
+				"static/_draft.css",
+				"static/app.js",
+				"static/css/site.css",
+			},
+		},
+		{
+			name:     "explicit hidden file",
+			patterns: []string{"static/.hidden"},
+			want:     []string{"static/.hidden"},
+		},
+		{
+			name:     "duplicates are removed",
+			patterns: []string{"static/app.js", "static/*.js"},
+			want:     []string{"static/app.js"},
+		},
+		{
+			name:     "no matching files",
+			patterns: []string{"*.html"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid pattern",
+			patterns: []string{"[a-"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), OS, dir, tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestNestedModules tests that the files of nested modules are left out of
+// the embedded directories and reported as nested modules.
+func TestNestedModules(t *testing.T) {
+	dir := writeFiles(t,
+		"static/app.js",
+		"static/plugin/go.mod",
+		"static/plugin/plugin.js",
+		"static/vendor/lib/go.mod",
+		"static/vendor/lib/lib.js",
+		"static/.tools/go.mod",
+		"module/go.mod",
+		"module/main.go",
+	)
+	tests := []struct {
+		name        string
+		pattern     string
+		wantFiles   []string
+		wantModules []string
+	}{
+		{
+			name:        "directory",
+			pattern:     "static",
+			wantFiles:   []string{"static/app.js"},
+			wantModules: []string{"static/plugin", "static/vendor/lib"},
+		},
+		{
+			name:        "all prefix",
+			pattern:     "all:static",
+			wantFiles:   []string{"static/app.js"},
+			wantModules: []string{"static/.tools", "static/plugin", "static/vendor/lib"},
+		},
+		{
+			name:        "module directory",
+			pattern:     "module",
+			wantModules: []string{"module"},
+		},
+		{
+			name:        "file",
+			pattern:     "static/app.js",
+			wantFiles:   []string{"static/app.js"},
+			wantModules: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ResolvePattern(context.Background(), OS, dir, tt.pattern)
+			if tt.wantFiles == nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantFiles, files)
+			resolution, _ := Inspect(context.Background(), OS, dir, tt.pattern)
+			assert.Equal(t, tt.wantModules, resolution.NestedModules)
+		})
+	}
+}
+
+// TestSymlinks tests that symbolic links are neither followed nor
+// embedded but reported, whether matched directly or inside a directory.
+func TestSymlinks(t *testing.T) {
+	dir := writeFiles(t,
+		"static/app.js",
+		"target.txt",
+		"other/file.txt",
+	)
+	for link, target := range map[string]string{
+		"static/link.txt": "../target.txt",
+		"static/linkdir":  "../other",
+		"link.txt":        "target.txt",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symbolic links are not supported: %v", err)
+		}
+	}
+	tests := []struct {
+		name         string
+		pattern      string
+		wantFiles    []string
+		wantSymlinks []string
+		wantErr      bool
+	}{
+		{
+			name:         "directory",
+			pattern:      "static",
+			wantFiles:    []string{"static/app.js"},
+			wantSymlinks: []string{"static/link.txt", "static/linkdir"},
+		},
+		{
+			name:         "literal link",
+			pattern:      "link.txt",
+			wantFiles:    []string{},
+			wantSymlinks: []string{"link.txt"},
+			wantErr:      true,
+		},
+		{
+			name:         "glob",
+			pattern:      "*.txt",
+			wantFiles:    []string{"target.txt"},
+			wantSymlinks: []string{"link.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution, err := Inspect(context.Background(), OS, dir, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inspect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantFiles, resolution.Files)
+			assert.Equal(t, tt.wantSymlinks, resolution.Symlinks)
+		})
+	}
+}
+
+// cancelAfter is a context cancelled after its error has been checked a
+// given number of times, standing for a cancellation arriving mid-walk.
+type cancelAfter struct {
+	context.Context
+	checks int
+	limit  int
+}
+
+// Err returns context.Canceled once the limit of checks is reached.
+func (c *cancelAfter) Err() error {
+	c.checks++
+	if c.checks > c.limit {
+		return context.Canceled
+	}
+	return nil
+}
+
+// TestResolvePatternCancelled tests that a cancelled walk stops promptly
+// with the error of its context.
+func TestResolvePatternCancelled(t *testing.T) {
+	names := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		names = append(names, fmt.Sprintf("static/%02d/file%03d.txt", i%10, i))
+	}
+	dir := writeFiles(t, names...)
+
+	ctx := &cancelAfter{Context: context.Background(), limit: 10}
+	files, err := ResolvePattern(ctx, OS, dir, "static")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, files)
+	assert.Equal(t, ctx.limit+1, ctx.checks, "the walk went on after the cancellation")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Resolve(cancelled, OS, dir, []string{"static/00/file000.txt"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// BenchmarkResolvePattern compares resolving a literal file name, which
+// takes the stat fast path, with resolving a glob matching the same file
+// in a directory of many files.
+func BenchmarkResolvePattern(b *testing.B) {
+	dir := b.TempDir()
+	for i := 0; i < 1000; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("file%04d.txt", i))
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for _, bm := range []struct {
+		name    string
+		pattern string
+	}{
+		{name: "literal", pattern: "file0500.txt"},
+		{name: "glob", pattern: "file050?.txt"},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := ResolvePattern(context.Background(), OS, dir, bm.pattern); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -4,44 +4,86 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strconv"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/rpc/errors"
 )
 
 // BaseMessage is the base message for a rpc message
 type BaseMessage struct {
+	RPC     string `json:"jsonrpc"`
 	ID      int    `json:"id"`
 	Method  string `json:"method"`
 	Content []byte `json:"-"`
 	Header  string `json:"-"`
+	// hasID is whether the message carries an id, as ID is zero both
+	// when the id is 0 and when there is none.
+	hasID bool
+}
+
+// IsNotification reports whether the message is a notification, which
+// has a method but no id and must never be answered.
+func (m *BaseMessage) IsNotification() bool {
+	return m.Method != "" && !m.hasID
 }
 
 // DecodeMessage decodes a rpc message
 // returns the method, content, and error
+//
+// A content that is not a JSON object fails with a ParseError, to be
+// answered, while a malformed header fails with a plain error.
 func DecodeMessage(msg []byte) (*BaseMessage, error) {
 	// Split the message into header and content
 	header, content, found := bytes.Cut(msg, []byte{'\r', '\n', '\r', '\n'})
 	if !found {
 		return nil, fmt.Errorf("no header found")
 	}
-	// Content-Length: <number>
-	contentLengthBytes := header[len("Content-Length: "):]
-	contentLength, err := strconv.Atoi(string(contentLengthBytes))
+	contentLength, err := parseContentLength(header)
 	if err != nil {
+		return nil, err
+	}
+	if len(content) < contentLength {
 		return nil, fmt.Errorf(
-			"failed to parse content length %s: %w",
-			string(contentLengthBytes),
-			err,
+			"content of %d bytes shorter than its length %d",
+			len(content),
+			contentLength,
 		)
 	}
-	var baseMessage BaseMessage
-	err = json.Unmarshal(content[:contentLength], &baseMessage)
+	var wire struct {
+		BaseMessage
+		// ID shadows the id of the base message to tell a missing id
+		// from an id of 0.
+		ID *int `json:"id"`
+	}
+	err = json.Unmarshal(content[:contentLength], &wire)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to unmarshal base message: %w",
-			err,
+		return nil, errors.New(
+			errors.CodeParseError,
+			fmt.Sprintf("failed to unmarshal base message: %s", err),
 		)
 	}
+	baseMessage := wire.BaseMessage
+	if wire.ID != nil {
+		baseMessage.ID, baseMessage.hasID = *wire.ID, true
+	}
 	baseMessage.Content = content[:contentLength]
 	baseMessage.Header = string(header)
 	return &baseMessage, nil
 }
+
+// Validate checks that the message is a JSON-RPC 2.0 message.
+//
+// It returns an InvalidRequest error when the version is missing or wrong.
+func (m *BaseMessage) Validate() error {
+	if m.RPC != lsp.RPCVersion {
+		return errors.New(
+			errors.CodeInvalidRequest,
+			fmt.Sprintf(
+				"unsupported jsonrpc version %q, expected %q",
+				m.RPC,
+				lsp.RPCVersion,
+			),
+		)
+	}
+	return nil
+}
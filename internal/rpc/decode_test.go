@@ -1,7 +1,11 @@
 package rpc
 
 import (
+	stderrors "errors"
+	"fmt"
 	"testing"
+
+	"github.com/conneroisu/embedpls/internal/rpc/errors"
 )
 
 // TestDecode tests the decode function
@@ -21,3 +25,72 @@ func TestDecode(t *testing.T) {
 		t.Fatalf("Expected: 'hi', Got: %s", message.Method)
 	}
 }
+
+// TestValidate tests the validation of the jsonrpc version of messages.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "2.0", content: `{"jsonrpc":"2.0","id":1,"method":"initialize"}`},
+		{name: "1.0", content: `{"jsonrpc":"1.0","id":1,"method":"initialize"}`, wantErr: true},
+		{name: "missing", content: `{"id":1,"method":"initialize"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := DecodeMessage([]byte(fmt.Sprintf(
+				"Content-Length: %d\r\n\r\n%s",
+				len(tt.content),
+				tt.content,
+			)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = message.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var rpcErr *errors.Error
+			if !stderrors.As(err, &rpcErr) {
+				t.Fatalf("Validate() error = %T, want *errors.Error", err)
+			}
+			if rpcErr.Code != errors.CodeInvalidRequest {
+				t.Errorf("Validate() code = %d, want %d", rpcErr.Code, errors.CodeInvalidRequest)
+			}
+		})
+	}
+}
+
+// TestIsNotification tests telling notifications from requests and
+// replies, including requests with an id of 0.
+func TestIsNotification(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "request", content: `{"jsonrpc":"2.0","id":1,"method":"shutdown"}`},
+		{name: "request with id 0", content: `{"jsonrpc":"2.0","id":0,"method":"initialize"}`},
+		{name: "notification", content: `{"jsonrpc":"2.0","method":"initialized"}`, want: true},
+		{name: "reply", content: `{"jsonrpc":"2.0","id":1,"result":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := DecodeMessage([]byte(fmt.Sprintf(
+				"Content-Length: %d\r\n\r\n%s",
+				len(tt.content),
+				tt.content,
+			)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := message.IsNotification(); got != tt.want {
+				t.Errorf("IsNotification() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
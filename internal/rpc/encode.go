@@ -5,6 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/charmbracelet/log"
 	"github.com/conneroisu/embedpls/internal/lsp"
@@ -27,41 +31,104 @@ func Encode(
 	ctx context.Context,
 	msg MethodActor,
 ) (string, error) {
+	builder := &strings.Builder{}
+	if err := EncodeTo(ctx, builder, msg); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// maxPooledBuffer is the capacity above which a buffer is dropped rather
+// than pooled, so a single huge message does not pin its memory.
+const maxPooledBuffer = 8 << 20
+
+// buffers are the buffers reused to encode the bodies of messages.
+var buffers = sync.Pool{
+	New: func() interface{} { return &bytes.Buffer{} },
+}
+
+// EncodeTo encodes a message with its Content-Length header and writes it
+// to w.
+//
+// The body is encoded into a pooled buffer, as its length must be known
+// before writing the header, and written from there, so large messages
+// are neither copied nor converted into a string on their way out.
+func EncodeTo(
+	ctx context.Context,
+	w io.Writer,
+	msg MethodActor,
+) error {
 	select {
 	case <-ctx.Done():
-		return "", fmt.Errorf("context cancelled: %w", ctx.Err())
+		return fmt.Errorf("context cancelled: %w", ctx.Err())
 	default:
-		buffer := &bytes.Buffer{}
-		encoder := json.NewEncoder(buffer)
-		encoder.SetEscapeHTML(false)
-		err := encoder.Encode(msg)
-		if err != nil {
-			return "", err
+	}
+	buffer := buffers.Get().(*bytes.Buffer)
+	defer func() {
+		if buffer.Cap() > maxPooledBuffer {
+			return
 		}
-		body := buffer.Bytes()
-		// body := bytes.TrimSuffix(
-		//         buffer.Bytes(),
-		//         []byte("\n"),
-		// )
+		buffer.Reset()
+		buffers.Put(buffer)
+	}()
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(msg); err != nil {
+		return err
+	}
+	body := buffer.Bytes()
+	if log.GetLevel() <= log.DebugLevel {
 		log.Debugf(
 			"wrote msg [%d] (%s): %s",
 			len(body),
 			msg.Method(),
-			string(body),
-		)
-		result := fmt.Sprintf(
-			"Content-Length: %d\r\n\r\n%s",
-			len(body),
-			string(body),
+			body,
 		)
-		return result, nil
 	}
+	header := make([]byte, 0, 32)
+	header = append(header, "Content-Length: "...)
+	header = strconv.AppendInt(header, int64(len(body)), 10)
+	header = append(header, "\r\n\r\n"...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// Decodable is the set of lsp requests and notifications that can be
+// decoded from an incoming message.
+type Decodable interface {
+	lsp.InitializeRequest |
+		lsp.InitializedParamsRequest |
+		lsp.ShutdownRequest |
+		lsp.CancelRequest |
+		lsp.SetTraceNotification |
+		lsp.DidChangeConfigurationNotification |
+		lsp.DidChangeWatchedFilesNotification |
+		lsp.NotificationDidOpenTextDocument |
+		lsp.TextDocumentDidChangeNotification |
+		lsp.WillSaveTextDocumentNotification |
+		lsp.WillSaveWaitUntilRequest |
+		lsp.DidSaveTextDocumentNotification |
+		lsp.DidCloseTextDocumentParamsNotification |
+		lsp.TextDocumentCompletionRequest |
+		lsp.CompletionItemResolveRequest |
+		lsp.HoverRequest |
+		lsp.SignatureHelpRequest |
+		lsp.TextDocumentCodeActionRequest |
+		lsp.DocumentHighlightRequest |
+		lsp.FoldingRangeRequest |
+		lsp.SelectionRangeRequest |
+		lsp.InlayHintRequest |
+		lsp.RenameRequest |
+		lsp.PrepareRenameRequest |
+		lsp.DocumentFormattingRequest |
+		lsp.ExecuteCommandRequest
 }
 
 // Decode decodes a message into lsp request.
-func Decode[
-	T lsp.InitializeRequest | lsp.NotificationDidOpenTextDocument | lsp.TextDocumentCompletionRequest | lsp.HoverRequest | lsp.TextDocumentCodeActionRequest | lsp.ShutdownRequest | lsp.CancelRequest | lsp.DidSaveTextDocumentNotification | lsp.DidCloseTextDocumentParamsNotification | lsp.TextDocumentDidChangeNotification,
-](msg *BaseMessage) (T, error) {
+func Decode[T Decodable](msg *BaseMessage) (T, error) {
 	var request T
 	err := json.Unmarshal([]byte(msg.Content), &request)
 	if err != nil {
@@ -2,12 +2,15 @@ package rpc_test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
 	"github.com/conneroisu/embedpls/internal/rpc"
 	"github.com/stretchr/testify/assert"
 	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
 )
 
 // var (
@@ -64,3 +67,258 @@ func TestEncode(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, actual)
 }
+
+// assertDecodeRoundTrip asserts that a message encoded from want decodes
+// back into want.
+func assertDecodeRoundTrip[T rpc.Decodable](t *testing.T, want T) {
+	t.Helper()
+	content, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rpc.Decode[T](&rpc.BaseMessage{Content: content})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+// TestDecodeRoundTrip tests that every decodable type round-trips through
+// Decode.
+func TestDecodeRoundTrip(t *testing.T) {
+	docURI := uri.File("/tmp/main.go")
+	request := func(method methods.Method) lsp.Request {
+		return lsp.Request{RPC: lsp.RPCVersion, ID: 1, Method: string(method)}
+	}
+	notification := func(method methods.Method) lsp.Notification {
+		return lsp.Notification{RPC: lsp.RPCVersion, Method: string(method)}
+	}
+	document := protocol.TextDocumentIdentifier{URI: docURI}
+	position := protocol.TextDocumentPositionParams{
+		TextDocument: document,
+		Position:     protocol.Position{Line: 1, Character: 2},
+	}
+	t.Run("initialize", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.InitializeRequest{
+			Request: request(methods.MethodInitialize),
+		})
+	})
+	t.Run("initialized", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.InitializedParamsRequest{
+			Notification: notification(methods.MethodInitialized),
+		})
+	})
+	t.Run("shutdown", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.ShutdownRequest{
+			Request: request(methods.MethodShutdown),
+		})
+	})
+	t.Run("cancel", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.CancelRequest{
+			Request: lsp.Request{
+				RPC:    lsp.RPCVersion,
+				Method: string(methods.MethodCancelRequest),
+			},
+			ID:     1,
+			Params: protocol.CancelParams{ID: "2"},
+		})
+	})
+	t.Run("setTrace", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.SetTraceNotification{
+			Notification: notification(methods.MethodSetTrace),
+			Params:       protocol.SetTraceParams{Value: protocol.TraceVerbose},
+		})
+	})
+	t.Run("didOpen", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.NotificationDidOpenTextDocument{
+			Notification: notification(methods.MethodTextDocumentDidOpen),
+			Params: protocol.DidOpenTextDocumentParams{
+				TextDocument: protocol.TextDocumentItem{
+					URI:        docURI,
+					LanguageID: "go",
+					Version:    1,
+					Text:       "package main",
+				},
+			},
+		})
+	})
+	t.Run("didChange", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.TextDocumentDidChangeNotification{
+			Notification: notification(methods.MethodTextDocumentDidChange),
+			Params: lsp.DidChangeTextDocumentParams{
+				TextDocument: protocol.VersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: document,
+					Version:                2,
+				},
+				ContentChanges: []lsp.TextDocumentContentChangeEvent{
+					{Text: "package main"},
+				},
+			},
+		})
+	})
+	t.Run("willSave", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.WillSaveTextDocumentNotification{
+			Notification: notification(methods.MethodTextDocumentWillSave),
+			Params: protocol.WillSaveTextDocumentParams{
+				TextDocument: document,
+				Reason:       protocol.TextDocumentSaveReasonManual,
+			},
+		})
+	})
+	t.Run("didSave", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.DidSaveTextDocumentNotification{
+			Notification: notification(methods.MethodTextDocumentDidSave),
+			Params:       protocol.DidSaveTextDocumentParams{TextDocument: document},
+		})
+	})
+	t.Run("didClose", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.DidCloseTextDocumentParamsNotification{
+			Notification: notification(methods.MethodTextDocumentDidClose),
+			Params:       protocol.DidCloseTextDocumentParams{TextDocument: document},
+		})
+	})
+	t.Run("completion", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.TextDocumentCompletionRequest{
+			Request: request(methods.MethodTextDocumentCompletion),
+			Params: protocol.CompletionParams{
+				TextDocumentPositionParams: position,
+			},
+		})
+	})
+	t.Run("hover", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.HoverRequest{
+			Request: request(methods.MethodTextDocumentHover),
+			Params: protocol.HoverParams{
+				TextDocumentPositionParams: position,
+			},
+		})
+	})
+	t.Run("signatureHelp", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.SignatureHelpRequest{
+			Request: request(methods.MethodTextDocumentSignatureHelp),
+			Params: protocol.SignatureHelpParams{
+				TextDocumentPositionParams: position,
+			},
+		})
+	})
+	t.Run("codeAction", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.TextDocumentCodeActionRequest{
+			Request: request(methods.MethodTextDocumentCodeAction),
+			Params: protocol.CodeActionParams{
+				TextDocument: document,
+			},
+		})
+	})
+	t.Run("documentHighlight", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.DocumentHighlightRequest{
+			Request: request(methods.MethodTextDocumentDocumentHighlight),
+			Params: protocol.DocumentHighlightParams{
+				TextDocumentPositionParams: position,
+			},
+		})
+	})
+	t.Run("foldingRange", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.FoldingRangeRequest{
+			Request: request(methods.MethodTextDocumentFoldingRange),
+			Params: protocol.FoldingRangeParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: position.TextDocument,
+				},
+			},
+		})
+	})
+	t.Run("selectionRange", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.SelectionRangeRequest{
+			Request: request(methods.MethodTextDocumentSelectionRange),
+			Params: lsp.SelectionRangeParams{
+				TextDocument: position.TextDocument,
+				Positions:    []protocol.Position{position.Position},
+			},
+		})
+	})
+	t.Run("inlayHint", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.InlayHintRequest{
+			Request: request(methods.MethodTextDocumentInlayHint),
+			Params: lsp.InlayHintParams{
+				TextDocument: position.TextDocument,
+				Range: protocol.Range{
+					End: protocol.Position{Line: 10},
+				},
+			},
+		})
+	})
+	t.Run("rename", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.RenameRequest{
+			Request: request(methods.MethodTextDocumentRename),
+			Params: protocol.RenameParams{
+				TextDocumentPositionParams: position,
+				NewName:                    "new.txt",
+			},
+		})
+	})
+	t.Run("prepareRename", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.PrepareRenameRequest{
+			Request: request(methods.MethodTextDocumentPrepareRename),
+			Params: protocol.PrepareRenameParams{
+				TextDocumentPositionParams: position,
+			},
+		})
+	})
+	t.Run("willSaveWaitUntil", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.WillSaveWaitUntilRequest{
+			Request: request(methods.MethodTextDocumentWillSaveWaitUntil),
+			Params: protocol.WillSaveTextDocumentParams{
+				TextDocument: position.TextDocument,
+				Reason:       protocol.TextDocumentSaveReasonManual,
+			},
+		})
+	})
+	t.Run("completionItemResolve", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.CompletionItemResolveRequest{
+			Request: request(methods.MethodCompletionItemResolve),
+			Params: protocol.CompletionItem{
+				Label: "static/hello.txt",
+				Kind:  protocol.CompletionItemKindFile,
+			},
+		})
+	})
+	t.Run("didChangeConfiguration", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.DidChangeConfigurationNotification{
+			Notification: notification(methods.MethodWorkspaceDidChangeConfiguration),
+			Params: protocol.DidChangeConfigurationParams{
+				Settings: map[string]interface{}{
+					"embedpls": map[string]interface{}{"ignore": []interface{}{"*.tmp"}},
+				},
+			},
+		})
+	})
+	t.Run("didChangeWatchedFiles", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.DidChangeWatchedFilesNotification{
+			Notification: notification(methods.MethodWorkspaceDidChangeWatchedFiles),
+			Params: protocol.DidChangeWatchedFilesParams{
+				Changes: []*protocol.FileEvent{
+					{URI: "file:///tmp/static/app.js", Type: protocol.FileChangeTypeCreated},
+				},
+			},
+		})
+	})
+	t.Run("formatting", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.DocumentFormattingRequest{
+			Request: request(methods.MethodTextDocumentFormatting),
+			Params: protocol.DocumentFormattingParams{
+				TextDocument: position.TextDocument,
+				Options: protocol.FormattingOptions{
+					TabSize:      4,
+					InsertSpaces: false,
+				},
+			},
+		})
+	})
+	t.Run("executeCommand", func(t *testing.T) {
+		assertDecodeRoundTrip(t, lsp.ExecuteCommandRequest{
+			Request: request(methods.MethodWorkspaceExecuteCommand),
+			Params: protocol.ExecuteCommandParams{
+				Command:   lsp.CommandFindEmbedders,
+				Arguments: []interface{}{"hello.txt"},
+			},
+		})
+	})
+}
@@ -1,6 +1,8 @@
 // Package errors provides the error codes for the RPC protocol.
 package errors
 
+import "fmt"
+
 // ErrorCode represents the error codes defined by JSON-RPC and LSP.
 type ErrorCode int
 
@@ -43,3 +45,24 @@ const (
 	// CodeLspReservedErrorRangeEnd is the end of the LSP error codes
 	CodeLspReservedErrorRangeEnd ErrorCode = -32800
 )
+
+// Error is a JSON-RPC error carrying one of the error codes.
+type Error struct {
+	// Code is the code of the error.
+	Code ErrorCode `json:"code"`
+	// Message is the description of the error.
+	Message string `json:"message"`
+}
+
+// New creates a JSON-RPC error with the given code and message.
+func New(code ErrorCode, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error returns the message of the error along with its code.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
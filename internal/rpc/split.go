@@ -1,36 +1,138 @@
 package rpc
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 )
 
+const (
+	// DefaultMaxMessageSize is the default maximum size, in bytes, of the
+	// content of a message.
+	DefaultMaxMessageSize = 16 << 20
+	// maxHeaderSize is the room left for the header of a message on top of
+	// its content when buffering it.
+	maxHeaderSize = 4 << 10
+)
+
+// ErrMessageTooLarge is returned for messages declaring a content larger
+// than the maximum message size.
+var ErrMessageTooLarge = errors.New("message too large")
+
 // Split splits a byte slice into a header and content.
 //
 // It returns the advance, token, and error.
-func Split(data []byte, _ bool) (int, []byte, error) {
-	var err error
-	var advance int
-	var token []byte
-	var header, content []byte
-	var found bool
-	var contentLengthBytes []byte
-	var contentLength int
-	header, content, found = bytes.Cut(data, []byte{'\r', '\n', '\r', '\n'})
+//
+// Messages larger than DefaultMaxMessageSize are rejected with
+// ErrMessageTooLarge.
+func Split(data []byte, atEOF bool) (int, []byte, error) {
+	return SplitWithLimit(DefaultMaxMessageSize)(data, atEOF)
+}
+
+// SplitWithLimit returns a split function like Split that rejects
+// messages whose declared content is larger than maxSize bytes.
+//
+// The limit is checked against the Content-Length header before the
+// content is buffered, so an oversized message is never allocated.
+func SplitWithLimit(maxSize int) bufio.SplitFunc {
+	return func(data []byte, _ bool) (int, []byte, error) {
+		return split(data, maxSize)
+	}
+}
+
+// contentLengthPrefix is the prefix of the header declaring the length of
+// the content of a message.
+var contentLengthPrefix = []byte("Content-Length: ")
+
+// split splits the first message off a byte slice.
+//
+// A frame whose header declares no valid content length is split off up to
+// the next Content-Length header, or up to the end of its header when it
+// holds none, so that decoding it fails and the messages following it are
+// still read.
+func split(data []byte, maxSize int) (int, []byte, error) {
+	header, content, found := bytes.Cut(data, []byte{'\r', '\n', '\r', '\n'})
 	if !found {
 		return 0, nil, nil
 	}
-	// Content-Length: <number>
-	contentLengthBytes = header[len("Content-Length: "):]
-	contentLength, err = strconv.Atoi(string(contentLengthBytes))
+	contentLength, err := parseContentLength(header)
 	if err != nil {
-		return 0, nil, fmt.Errorf("failed to parse content length: %w", err)
+		advance := resync(data, len(header)+4)
+		return advance, data[:advance], nil
+	}
+	if contentLength > maxSize {
+		return 0, nil, fmt.Errorf(
+			"%w: content length %d exceeds the limit of %d bytes",
+			ErrMessageTooLarge,
+			contentLength,
+			maxSize,
+		)
 	}
 	if len(content) < contentLength {
 		return 0, nil, nil
 	}
-	advance = len(header) + 4 + contentLength
-	token = data[:advance]
-	return advance, token, nil
+	advance := len(header) + 4 + contentLength
+	return advance, data[:advance], nil
+}
+
+// parseContentLength returns the length of the content declared by the
+// header of a message.
+//
+// The header is read field by field, their names ignoring case, and the
+// fields other than Content-Length, such as Content-Type, are ignored.
+func parseContentLength(header []byte) (int, error) {
+	var value []byte
+	found := false
+	for _, field := range bytes.Split(header, []byte("\r\n")) {
+		name, fieldValue, ok := bytes.Cut(field, []byte(":"))
+		if ok && strings.EqualFold(string(name), "Content-Length") {
+			value, found = bytes.TrimSpace(fieldValue), true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("missing Content-Length header")
+	}
+	contentLength, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse content length: %w", err)
+	}
+	if contentLength < 0 {
+		return 0, fmt.Errorf("negative content length: %d", contentLength)
+	}
+	return contentLength, nil
+}
+
+// resync returns the offset of the first Content-Length header of data
+// after its start and before end, or end when there is none.
+func resync(data []byte, end int) int {
+	if i := bytes.Index(data[1:end], contentLengthPrefix); i >= 0 {
+		return i + 1
+	}
+	return end
+}
+
+// NewScanner returns a scanner splitting the messages read from reader.
+//
+// Its buffer grows as needed to hold messages up to DefaultMaxMessageSize,
+// well past the 64KB default of bufio.Scanner, so large documents can be
+// exchanged.
+func NewScanner(reader io.Reader) *bufio.Scanner {
+	return NewScannerWithLimit(reader, DefaultMaxMessageSize)
+}
+
+// NewScannerWithLimit returns a scanner splitting the messages read from
+// reader, rejecting messages whose content is larger than maxSize bytes
+// with ErrMessageTooLarge.
+func NewScannerWithLimit(reader io.Reader, maxSize int) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(
+		make([]byte, 0, bufio.MaxScanTokenSize),
+		maxSize+maxHeaderSize,
+	)
+	scanner.Split(SplitWithLimit(maxSize))
+	return scanner
 }
@@ -2,6 +2,9 @@ package rpc
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -21,12 +24,26 @@ func TestSplit(t *testing.T) {
 			expectedTok: []byte("Content-Length: 13\r\n\r\nHello, world!"),
 			expectErr:   false,
 		},
+		{
+			name:        "Content Type Header",
+			data:        []byte("Content-Length: 13\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\nHello, world!"),
+			expectedAdv: 92,
+			expectedTok: []byte("Content-Length: 13\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\nHello, world!"),
+			expectErr:   false,
+		},
 		{
 			name:        "Invalid Content Length",
 			data:        []byte("Content-Length: abc\r\n\r\nHello, world!"),
-			expectedAdv: 0,
-			expectedTok: nil,
-			expectErr:   true,
+			expectedAdv: 23,
+			expectedTok: []byte("Content-Length: abc\r\n\r\n"),
+			expectErr:   false,
+		},
+		{
+			name:        "Garbage Before Header",
+			data:        []byte("garbageContent-Length: 13\r\n\r\nHello, world!"),
+			expectedAdv: 7,
+			expectedTok: []byte("garbage"),
+			expectErr:   false,
 		},
 		{
 			name:        "Insufficient Content Length",
@@ -35,6 +52,20 @@ func TestSplit(t *testing.T) {
 			expectedTok: nil,
 			expectErr:   false,
 		},
+		{
+			name:        "Oversized Content Length",
+			data:        []byte("Content-Length: 99999999999\r\n\r\nHello, world!"),
+			expectedAdv: 0,
+			expectedTok: nil,
+			expectErr:   true,
+		},
+		{
+			name:        "Negative Content Length",
+			data:        []byte("Content-Length: -1\r\n\r\nHello, world!"),
+			expectedAdv: 22,
+			expectedTok: []byte("Content-Length: -1\r\n\r\n"),
+			expectErr:   false,
+		},
 		{
 			name:        "Missing Header",
 			data:        []byte("Hello, world!"),
@@ -58,3 +89,50 @@ func TestSplit(t *testing.T) {
 		})
 	}
 }
+
+// TestSplitWithLimit tests that messages declaring a content larger than
+// the limit are rejected before their content is read.
+func TestSplitWithLimit(t *testing.T) {
+	split := SplitWithLimit(8)
+	_, tok, err := split([]byte("Content-Length: 9\r\n\r\n"), false)
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got: %v", err)
+	}
+	if tok != nil {
+		t.Errorf("expected no token, got: %s", tok)
+	}
+	adv, tok, err := split([]byte("Content-Length: 8\r\n\r\n12345678"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adv != 29 || string(tok) != "Content-Length: 8\r\n\r\n12345678" {
+		t.Errorf("unexpected split: %d %q", adv, tok)
+	}
+}
+
+// TestNewScannerLargeMessage tests that messages larger than the default
+// 64KB buffer of bufio.Scanner are scanned whole.
+func TestNewScannerLargeMessage(t *testing.T) {
+	text := strings.Repeat("a", 1<<20)
+	content := `{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"contentChanges":[{"text":"` + text + `"}]}}`
+	message := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(content), content)
+	scanner := NewScanner(strings.NewReader(message + message))
+	for i := 0; i < 2; i++ {
+		if !scanner.Scan() {
+			t.Fatalf("message %d not scanned: %v", i, scanner.Err())
+		}
+		decoded, err := DecodeMessage(scanner.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Method != "textDocument/didChange" || len(decoded.Content) != len(content) {
+			t.Errorf("unexpected message %d: %s (%d bytes)", i, decoded.Method, len(decoded.Content))
+		}
+	}
+	if scanner.Scan() {
+		t.Error("unexpected extra message")
+	}
+	if err := scanner.Err(); err != nil {
+		t.Error(err)
+	}
+}
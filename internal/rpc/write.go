@@ -5,61 +5,113 @@ import (
 	"fmt"
 	"io"
 	"sync"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/safe"
 )
 
 // Writer is a struct for writing messages to a writer
+//
+// It is safe for concurrent use: each message is written whole, under a
+// lock, so that messages sent from several goroutines never interleave.
 type Writer struct {
 	io.Writer
+	// mu serializes the writes to the underlying writer.
 	mu sync.Mutex
+	// lastID is the id of the last request sent to the client.
+	lastID int
+	// pending are the methods of the requests sent to the client that are
+	// awaiting a reply, keyed by id.
+	pending *safe.Map[int, methods.Method]
 }
 
 // NewWriter creates a new writer.
 func NewWriter(writer io.Writer) *Writer {
 	return &Writer{
-		Writer: writer,
-		mu:     sync.Mutex{},
+		Writer:  writer,
+		mu:      sync.Mutex{},
+		pending: safe.NewSafeMap[int, methods.Method](),
+	}
+}
+
+// serverRequest is a request sent from the server to the client.
+type serverRequest struct {
+	// RPC is the rpc version of the request.
+	RPC string `json:"jsonrpc"`
+	// ID is the id of the request, used to correlate the reply.
+	ID int `json:"id"`
+	// Name is the method of the request.
+	Name methods.Method `json:"method"`
+	// Params are the parameters of the request.
+	Params interface{} `json:"params,omitempty"`
+}
+
+// Method returns the method of the server request.
+func (r serverRequest) Method() methods.Method {
+	return r.Name
+}
+
+// WriteRequest writes a request from the server to the client and returns
+// its id.
+//
+// Ids are assigned in increasing order and the request stays pending until
+// its reply is passed to Resolve.
+func (w *Writer) WriteRequest(
+	ctx context.Context,
+	method methods.Method,
+	params interface{},
+) (int, error) {
+	w.mu.Lock()
+	w.lastID++
+	id := w.lastID
+	w.mu.Unlock()
+	w.pending.Set(id, method)
+	err := w.WriteResponse(ctx, serverRequest{
+		RPC:    lsp.RPCVersion,
+		ID:     id,
+		Name:   method,
+		Params: params,
+	})
+	if err != nil {
+		w.pending.Delete(id)
+		return 0, err
 	}
+	return id, nil
+}
+
+// Resolve marks the request sent to the client with the given id as
+// replied to and returns its method.
+//
+// It returns false when no such request is pending, so that of duplicate
+// replies to a request, even concurrent ones, only the first resolves it.
+func (w *Writer) Resolve(id int) (methods.Method, bool) {
+	return w.pending.LoadAndDelete(id)
+}
+
+// Write writes raw bytes to the underlying writer, holding the lock of
+// the writer so they do not interleave with the messages being written.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Writer.Write(p)
 }
 
 // WriteResponse writes a message to the writer
+//
+// The message is encoded straight to the underlying writer.
 func (w *Writer) WriteResponse(
 	ctx context.Context,
 	msg MethodActor,
 ) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	for {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("context cancelled: %w", ctx.Err())
-		default:
-			reply, err := Encode(
-				ctx,
-				msg,
-			)
-			if err != nil {
-				return fmt.Errorf(
-					"failed to encode response to request (%s): %w",
-					msg.Method(),
-					err,
-				)
-			}
-			res, err := w.Write([]byte(reply))
-			if err != nil {
-				return fmt.Errorf(
-					"failed to encode response to request (%s): %w",
-					msg.Method(),
-					err,
-				)
-			}
-			if res != len(reply) {
-				return fmt.Errorf(
-					"failed writing all of response to (%s) request: %w",
-					msg.Method(),
-					err,
-				)
-			}
-			return nil
-		}
+	if err := EncodeTo(ctx, w.Writer, msg); err != nil {
+		return fmt.Errorf(
+			"failed to write response to request (%s): %w",
+			msg.Method(),
+			err,
+		)
 	}
+	return nil
 }
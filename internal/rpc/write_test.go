@@ -0,0 +1,148 @@
+package rpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+)
+
+// TestWriteRequest tests that server requests get increasing ids, are
+// framed with a Content-Length header and stay pending until resolved.
+func TestWriteRequest(t *testing.T) {
+	out := &bytes.Buffer{}
+	writer := rpc.NewWriter(out)
+	params := map[string]string{"token": "scan"}
+
+	first, err := writer.WriteRequest(
+		context.Background(),
+		methods.MethodWindowWorkDoneProgressCreate,
+		params,
+	)
+	assert.NoError(t, err)
+	body := `{"jsonrpc":"2.0","id":1,"method":"window/workDoneProgress/create","params":{"token":"scan"}}` + "\n"
+	assert.Equal(t, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body), out.String())
+
+	second, err := writer.WriteRequest(
+		context.Background(),
+		methods.MethodWindowWorkDoneProgressCreate,
+		params,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+
+	method, ok := writer.Resolve(first)
+	assert.True(t, ok)
+	assert.Equal(t, methods.MethodWindowWorkDoneProgressCreate, method)
+	_, ok = writer.Resolve(first)
+	assert.False(t, ok, "a request is resolved only once")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = writer.WriteRequest(ctx, methods.MethodWindowWorkDoneProgressCreate, params)
+	assert.Error(t, err)
+}
+
+// TestResolveDuplicateReplies tests that of duplicate replies to a
+// request, even concurrent ones, only one resolves it.
+func TestResolveDuplicateReplies(t *testing.T) {
+	writer := rpc.NewWriter(io.Discard)
+	id, err := writer.WriteRequest(
+		context.Background(),
+		methods.MethodWindowWorkDoneProgressCreate,
+		map[string]string{"token": "scan"},
+	)
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	resolved := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := writer.Resolve(id); ok {
+				mu.Lock()
+				resolved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, resolved)
+}
+
+// BenchmarkWriteResponse measures writing a large response, such as the
+// hover preview of a big file, to a connection.
+func BenchmarkWriteResponse(b *testing.B) {
+	response := lsp.HoverResponse{
+		Response: lsp.Response{RPC: lsp.RPCVersion, ID: 1},
+		Result: &lsp.HoverResult{Contents: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: strings.Repeat("a line of a large embedded file\n", 1<<15),
+		}},
+	}
+	writer := rpc.NewWriter(io.Discard)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := writer.WriteResponse(ctx, response); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestWriterConcurrent tests that messages written from many goroutines
+// at once come out as individually valid framed messages.
+func TestWriterConcurrent(t *testing.T) {
+	out := &bytes.Buffer{}
+	writer := rpc.NewWriter(out)
+	const count = 100
+	var wg sync.WaitGroup
+	for i := 1; i <= count; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			err := writer.WriteResponse(context.Background(), lsp.HoverResponse{
+				Response: lsp.Response{RPC: lsp.RPCVersion, ID: id},
+				Result: &lsp.HoverResult{Contents: protocol.MarkupContent{
+					Kind:  protocol.PlainText,
+					Value: strings.Repeat(fmt.Sprint(id), 1000),
+				}},
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 0, 64*1024), rpc.DefaultMaxMessageSize)
+	scanner.Split(rpc.Split)
+	seen := make(map[int]bool)
+	for scanner.Scan() {
+		msg, err := rpc.DecodeMessage(scanner.Bytes())
+		if !assert.NoError(t, err) {
+			return
+		}
+		var response lsp.HoverResponse
+		if !assert.NoError(t, json.Unmarshal(msg.Content, &response)) {
+			return
+		}
+		assert.Equal(t, strings.Repeat(fmt.Sprint(msg.ID), 1000), response.Result.Contents.Value)
+		seen[msg.ID] = true
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Len(t, seen, count)
+}
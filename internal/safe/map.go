@@ -3,6 +3,7 @@ package safe
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -47,6 +48,19 @@ func (sm *Map[K, V]) Delete(key K) {
 	delete(sm.m, key)
 }
 
+// LoadAndDelete deletes the value for the given key, returning it and
+// whether it existed.
+//
+// The lookup and the deletion happen under a single lock, so of concurrent
+// calls for a key only one gets its value.
+func (sm *Map[K, V]) LoadAndDelete(key K) (V, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	val, ok := sm.m[key]
+	delete(sm.m, key)
+	return val, ok
+}
+
 // Len returns the length of the map.
 func (sm *Map[K, V]) Len() int {
 	sm.mu.RLock()
@@ -54,6 +68,21 @@ func (sm *Map[K, V]) Len() int {
 	return len(sm.m)
 }
 
+// LenAndKeys returns the length of the map along with its keys, in no
+// particular order.
+//
+// Both are read under a single lock, so the keys always number the length
+// even while other goroutines write to the map.
+func (sm *Map[K, V]) LenAndKeys() (int, []K) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	keys := make([]K, 0, len(sm.m))
+	for k := range sm.m {
+		keys = append(keys, k)
+	}
+	return len(sm.m), keys
+}
+
 // Clear clears the map.
 func (sm *Map[K, V]) Clear() {
 	log.Debugf("clearing map over type: %s", reflect.TypeOf(sm.m))
@@ -83,7 +112,9 @@ func limitString(s string, limit int) string {
 	return s
 }
 
-// Values returns the values of the map.
+// Values returns the values of the map, in no particular order.
+//
+// Use OrderedValues or ValuesByKey when the order matters.
 func (sm *Map[K, V]) Values() []V {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
@@ -93,3 +124,61 @@ func (sm *Map[K, V]) Values() []V {
 	}
 	return values
 }
+
+// OrderedValues returns the values of the map sorted by less.
+//
+// Values equal for less are in no particular order.
+func (sm *Map[K, V]) OrderedValues(less func(a, b V) bool) []V {
+	values := sm.Values()
+	sort.Slice(values, func(i, j int) bool {
+		return less(values[i], values[j])
+	})
+	return values
+}
+
+// ValuesByKey returns the values of the map in the order of their keys
+// sorted by less, for values that cannot be compared themselves.
+func (sm *Map[K, V]) ValuesByKey(less func(a, b K) bool) []V {
+	sm.mu.RLock()
+	keys := make([]K, 0, len(sm.m))
+	for k := range sm.m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+	values := make([]V, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, sm.m[k])
+	}
+	sm.mu.RUnlock()
+	return values
+}
+
+// Update sets the value of key to the result of fn, called with the current
+// value of key and whether it existed.
+//
+// The write lock is held while fn runs, so concurrent updates of a key are
+// applied one after the other and fn must not call other methods of the
+// map.
+func (sm *Map[K, V]) Update(key K, fn func(old V, existed bool) V) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	old, existed := sm.m[key]
+	sm.m[key] = fn(old, existed)
+}
+
+// ForEach calls fn for every entry of the map, deleting the entries for
+// which fn returns false.
+//
+// The write lock is held for the whole iteration, so fn must not call other
+// methods of the map.
+func (sm *Map[K, V]) ForEach(fn func(key K, value V) (keep bool)) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for k, v := range sm.m {
+		if !fn(k, v) {
+			delete(sm.m, k)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package safe
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 
@@ -60,6 +61,41 @@ func TestLen(t *testing.T) {
 	assert.Equal(t, 1, sm.Len())
 }
 
+// TestLenAndKeys tests that the length and keys of the map agree, even
+// while the map is written concurrently.
+func TestLenAndKeys(t *testing.T) {
+	sm := NewSafeMap[string, int]()
+	n, keys := sm.LenAndKeys()
+	assert.Equal(t, 0, n)
+	assert.Empty(t, keys)
+
+	sm.Set("key1", 10)
+	sm.Set("key2", 20)
+	n, keys = sm.LenAndKeys()
+	assert.Equal(t, 2, n)
+	assert.ElementsMatch(t, []string{"key1", "key2"}, keys)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			sm.Set(fmt.Sprintf("key%d", i+3), i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			n, keys := sm.LenAndKeys()
+			if n != len(keys) {
+				t.Errorf("LenAndKeys() = %d, %d keys", n, len(keys))
+			}
+		}()
+	}
+	wg.Wait()
+	n, keys = sm.LenAndKeys()
+	assert.Equal(t, 102, n)
+	assert.Len(t, keys, 102)
+}
+
 // TestConcurrentSetAndGet tests the SafeMap's concurrent set and get methods.
 func TestConcurrentSetAndGet(t *testing.T) {
 	sm := NewSafeMap[int, int]()
@@ -260,3 +296,136 @@ func TestSafeMap_Concurrent(t *testing.T) {
 		t.Errorf("Expected length <= %d, got %d", expectedLen, sm.Len())
 	}
 }
+
+// TestSafeMap_ForEach tests that ForEach visits every entry and deletes
+// the ones it is told not to keep.
+func TestSafeMap_ForEach(t *testing.T) {
+	sm := NewSafeMap[int, int]()
+	for i := 0; i < 10; i++ {
+		sm.Set(i, i*10)
+	}
+	visited := 0
+	sm.ForEach(func(k, v int) bool {
+		visited++
+		assert.Equal(t, k*10, v)
+		return k%2 == 0
+	})
+	assert.Equal(t, 10, visited)
+	assert.Equal(t, 5, sm.Len())
+	for i := 0; i < 10; i++ {
+		_, ok := sm.Get(i)
+		assert.Equal(t, i%2 == 0, ok)
+	}
+}
+
+// TestSafeMap_ForEachConcurrent tests ForEach deleting entries while other
+// goroutines write to the map.
+func TestSafeMap_ForEachConcurrent(t *testing.T) {
+	sm := NewSafeMap[int, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			sm.Set(i, i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			sm.ForEach(func(k, _ int) bool {
+				return k%2 == 0
+			})
+		}()
+	}
+	wg.Wait()
+	sm.ForEach(func(k, _ int) bool {
+		return k%2 == 0
+	})
+	assert.Equal(t, 50, sm.Len())
+	sm.ForEach(func(k, _ int) bool {
+		assert.Equal(t, 0, k%2)
+		return true
+	})
+}
+
+// TestSafeMap_Update tests that Update transforms the stored value, or the
+// zero value of a missing key.
+func TestSafeMap_Update(t *testing.T) {
+	sm := NewSafeMap[string, int]()
+	sm.Update("a", func(old int, existed bool) int {
+		assert.False(t, existed)
+		assert.Equal(t, 0, old)
+		return 1
+	})
+	sm.Update("a", func(old int, existed bool) int {
+		assert.True(t, existed)
+		return old + 1
+	})
+	value, ok := sm.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, *value)
+}
+
+// TestSafeMap_UpdateConcurrent tests that concurrent updates of a key are
+// serialized, so that no increment is lost.
+func TestSafeMap_UpdateConcurrent(t *testing.T) {
+	sm := NewSafeMap[string, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sm.Update("counter", func(old int, _ bool) int {
+				return old + 1
+			})
+		}()
+	}
+	wg.Wait()
+	value, ok := sm.Get("counter")
+	assert.True(t, ok)
+	assert.Equal(t, 1000, *value)
+}
+
+// TestSafeMap_LoadAndDeleteConcurrent tests that of concurrent
+// LoadAndDelete calls for a key only one gets its value.
+func TestSafeMap_LoadAndDeleteConcurrent(t *testing.T) {
+	sm := NewSafeMap[string, int]()
+	sm.Set("key1", 10)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	loaded := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, ok := sm.LoadAndDelete("key1"); ok {
+				assert.Equal(t, 10, val)
+				mu.Lock()
+				loaded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, loaded)
+	assert.Equal(t, 0, sm.Len())
+}
+
+// TestSafeMap_OrderedValues tests that the ordered values of a map are the
+// same on every call, whatever the iteration order of the map.
+func TestSafeMap_OrderedValues(t *testing.T) {
+	sm := NewSafeMap[int, string]()
+	for i, value := range []string{"d", "b", "e", "a", "c"} {
+		sm.Set(i, value)
+	}
+	for i := 0; i < 20; i++ {
+		assert.Equal(t,
+			[]string{"a", "b", "c", "d", "e"},
+			sm.OrderedValues(func(a, b string) bool { return a < b }),
+		)
+		assert.Equal(t,
+			[]string{"d", "b", "e", "a", "c"},
+			sm.ValuesByKey(func(a, b int) bool { return a < b }),
+		)
+	}
+	assert.Empty(t, NewSafeMap[int, string]().ValuesByKey(func(a, b int) bool { return a < b }))
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"strings"
+
+	"go.lsp.dev/uri"
+)
+
+// isGoFile reports whether the document is a Go source file.
+func isGoFile(uri uri.URI) bool {
+	return strings.HasSuffix(string(uri), ".go")
+}
+
+// handleAssetOpen records a non-Go document opened by the client.
+//
+// Such documents may be embedded by Go files, this is the hook point for
+// features working from the embedded side, like finding the Go files that
+// embed an asset.
+func (l *lspHandler) handleAssetOpen(uri uri.URI, text string) {
+	l.assets.Set(uri, text)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+// handleTextDocumentCodeAction returns the code actions available for the
+// lines of the requested range.
+func (l *lspHandler) handleTextDocumentCodeAction(
+	_ context.Context,
+	request lsp.TextDocumentCodeActionRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.TextDocumentCodeActionResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: make([]protocol.CodeAction, 0),
+	}
+	doc, ok := l.documents.Get(request.Params.TextDocument.URI)
+	if !ok {
+		return resp, nil
+	}
+	index, _ := l.documentIndex(request.Params.TextDocument.URI)
+	only := request.Params.Context.Only
+	if wantsKind(only, protocol.QuickFix) {
+		resp.Result = append(resp.Result, misspelledDirectiveFixes(
+			request.Params.TextDocument.URI,
+			*doc,
+			request.Params.Range,
+		)...)
+	}
+	if wantsKind(only, protocol.RefactorRewrite) {
+		resp.Result = append(resp.Result, splitDirectiveActions(
+			request.Params.TextDocument.URI,
+			*doc,
+			index,
+			request.Params.Range,
+		)...)
+		resp.Result = append(resp.Result, mergeDirectivesActions(
+			request.Params.TextDocument.URI,
+			*doc,
+			index,
+			request.Params.Range,
+		)...)
+	}
+	if wantsKind(only, protocol.Source) {
+		resp.Result = append(resp.Result, showMatchedFilesActions(
+			request.Params.TextDocument.URI,
+			index,
+			request.Params.Range,
+		)...)
+	}
+	return resp, nil
+}
+
+// misspelledDirectiveFixes returns the quick fixes respelling the
+// "// go:embed" directives of the given lines as "//go:embed".
+func misspelledDirectiveFixes(
+	docURI protocol.DocumentURI,
+	doc string,
+	lines protocol.Range,
+) []protocol.CodeAction {
+	actions := make([]protocol.CodeAction, 0)
+	for _, misspelled := range parsers.ParseMisspelledDirectives(doc) {
+		if !inLines(lines, misspelled.Line) {
+			continue
+		}
+		actions = append(actions, protocol.CodeAction{
+			Title:       "Remove the space after the slashes of the go:embed directive",
+			Kind:        protocol.QuickFix,
+			Diagnostics: []protocol.Diagnostic{misspelled.Diagnostic()},
+			IsPreferred: true,
+			Edit: &protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					docURI: {misspelled.Fix()},
+				},
+			},
+		})
+	}
+	return actions
+}
+
+// splitDirectiveActions returns the refactorings splitting the directives
+// of the given lines into one directive per pattern.
+func splitDirectiveActions(
+	docURI protocol.DocumentURI,
+	doc string,
+	index *parsers.Index,
+	lines protocol.Range,
+) []protocol.CodeAction {
+	actions := make([]protocol.CodeAction, 0)
+	for _, directive := range index.Directives {
+		if !inLines(lines, directive.Line) {
+			continue
+		}
+		edit, ok := parsers.SplitDirective(doc, directive)
+		if !ok {
+			continue
+		}
+		actions = append(actions, protocol.CodeAction{
+			Title: "Split into one go:embed directive per pattern",
+			Kind:  protocol.RefactorRewrite,
+			Edit: &protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					docURI: {edit},
+				},
+			},
+		})
+	}
+	return actions
+}
+
+// mergeDirectivesActions returns the refactorings merging the directives
+// of the variables whose declaration or directives are on the given lines.
+func mergeDirectivesActions(
+	docURI protocol.DocumentURI,
+	doc string,
+	index *parsers.Index,
+	lines protocol.Range,
+) []protocol.CodeAction {
+	actions := make([]protocol.CodeAction, 0)
+	for _, block := range index.Blocks() {
+		first := block.Directives[0].Line
+		if int(lines.End.Line) < first || block.Line < int(lines.Start.Line) {
+			continue
+		}
+		edit, ok := parsers.MergeDirectives(doc, block)
+		if !ok {
+			continue
+		}
+		actions = append(actions, protocol.CodeAction{
+			Title: "Merge into one go:embed directive",
+			Kind:  protocol.RefactorRewrite,
+			Edit: &protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+					docURI: {edit},
+				},
+			},
+		})
+	}
+	return actions
+}
+
+// inLines reports whether a line is one of the lines spanned by a range.
+func inLines(lines protocol.Range, line int) bool {
+	return int(lines.Start.Line) <= line && line <= int(lines.End.Line)
+}
+
+// wantsKind reports whether the client asked for code actions of the given
+// kind, every kind being wanted when the client did not filter them.
+//
+// Kinds are hierarchical, so asking for "refactor" includes
+// "refactor.rewrite".
+func wantsKind(only []protocol.CodeActionKind, kind protocol.CodeActionKind) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, wanted := range only {
+		if kind == wanted || strings.HasPrefix(string(kind), string(wanted)+".") {
+			return true
+		}
+	}
+	return false
+}
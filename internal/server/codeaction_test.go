@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// codeActions requests the code actions of the given lines of a document.
+func codeActions(
+	t *testing.T,
+	handler *lspHandler,
+	docURI uri.URI,
+	lines protocol.Range,
+	only ...protocol.CodeActionKind,
+) []protocol.CodeAction {
+	t.Helper()
+	resp, err := handler.handleTextDocumentCodeAction(
+		context.Background(),
+		lsp.TextDocumentCodeActionRequest{
+			Params: protocol.CodeActionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Range:        lines,
+				Context:      protocol.CodeActionContext{Only: only},
+			},
+		},
+	)
+	assert.NoError(t, err)
+	return resp.(lsp.TextDocumentCodeActionResponse).Result
+}
+
+// TestCodeActionMisspelledDirective tests the quick fix removing the space
+// of a "// go:embed" directive.
+func TestCodeActionMisspelledDirective(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n"+
+		"// go:embed hello.txt\n"+
+		"var hello string\n\n"+
+		"//go:embed world.txt\n"+
+		"var world string\n")
+	line := func(n uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: n},
+			End:   protocol.Position{Line: n, Character: 3},
+		}
+	}
+
+	actions := codeActions(t, handler, docURI, line(2))
+	if assert.Len(t, actions, 1) {
+		assert.Equal(t, protocol.QuickFix, actions[0].Kind)
+		assert.True(t, actions[0].IsPreferred)
+		assert.Len(t, actions[0].Diagnostics, 1)
+		assert.Equal(t, map[protocol.DocumentURI][]protocol.TextEdit{
+			docURI: {{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 0},
+					End:   protocol.Position{Line: 2, Character: 11},
+				},
+				NewText: "//go:embed",
+			}},
+		}, actions[0].Edit.Changes)
+	}
+
+	assert.Empty(t, codeActions(t, handler, docURI, line(5)))
+	assert.Empty(t, codeActions(t, handler, docURI, line(2), protocol.Refactor))
+	assert.Len(t, codeActions(t, handler, docURI, line(2), protocol.QuickFix), 1)
+}
+
+// TestWantsKind tests filtering code actions by kind.
+func TestWantsKind(t *testing.T) {
+	tests := []struct {
+		name string
+		only []protocol.CodeActionKind
+		kind protocol.CodeActionKind
+		want bool
+	}{
+		{name: "no filter", kind: protocol.QuickFix, want: true},
+		{name: "same kind", only: []protocol.CodeActionKind{protocol.QuickFix}, kind: protocol.QuickFix, want: true},
+		{name: "parent kind", only: []protocol.CodeActionKind{protocol.Refactor}, kind: protocol.RefactorRewrite, want: true},
+		{name: "other kind", only: []protocol.CodeActionKind{protocol.Refactor}, kind: protocol.QuickFix, want: false},
+		{name: "prefix is not a parent", only: []protocol.CodeActionKind{"quick"}, kind: protocol.QuickFix, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, wantsKind(tt.only, tt.kind))
+		})
+	}
+}
+
+// TestCodeActionSplitDirective tests that splitting a directive of three
+// patterns produces three directives preceding the same variable.
+func TestCodeActionSplitDirective(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	source := "package main\n\n" +
+		"//go:embed a.txt b.txt static\n" +
+		"var content embed.FS\n"
+	handler.documents.Set(docURI, source)
+	actions := codeActions(t, handler, docURI, protocol.Range{
+		Start: protocol.Position{Line: 2, Character: 12},
+		End:   protocol.Position{Line: 2, Character: 12},
+	})
+	if !assert.Len(t, actions, 1) {
+		return
+	}
+	assert.Equal(t, protocol.RefactorRewrite, actions[0].Kind)
+	edits := actions[0].Edit.Changes[docURI]
+	if !assert.Len(t, edits, 1) {
+		return
+	}
+	assert.Equal(t, "package main\n\n"+
+		"//go:embed a.txt\n"+
+		"//go:embed b.txt\n"+
+		"//go:embed static\n"+
+		"var content embed.FS\n", applyEdits(source, edits))
+
+	assert.Empty(t, codeActions(t, handler, docURI, protocol.Range{
+		Start: protocol.Position{Line: 2},
+		End:   protocol.Position{Line: 2},
+	}, protocol.QuickFix))
+}
+
+// applyEdits applies sorted non-overlapping text edits, whose characters
+// are byte offsets, to a source.
+func applyEdits(source string, edits []protocol.TextEdit) string {
+	lines := strings.Split(source, "\n")
+	for i := len(edits) - 1; i >= 0; i-- {
+		edit := edits[i]
+		start := lineOffset(lines, edit.Range.Start)
+		end := lineOffset(lines, edit.Range.End)
+		source = source[:start] + edit.NewText + source[end:]
+	}
+	return source
+}
+
+// lineOffset returns the byte offset of a position in the given lines.
+func lineOffset(lines []string, position protocol.Position) int {
+	offset := 0
+	for _, line := range lines[:position.Line] {
+		offset += len(line) + 1
+	}
+	return offset + int(position.Character)
+}
+
+// TestCodeActionMergeDirectives tests merging the directives of a
+// variable, and that directives of unrelated variables are never merged.
+func TestCodeActionMergeDirectives(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	source := "package main\n\n" +
+		"//go:embed a.txt\n" +
+		"//go:embed b.txt\n" +
+		"//go:embed static\n" +
+		"var content embed.FS\n\n" +
+		"//go:embed c.txt\n" +
+		"var c string\n" +
+		"//go:embed d.txt\n" +
+		"var d string\n"
+	handler.documents.Set(docURI, source)
+	actions := codeActions(t, handler, docURI, protocol.Range{
+		Start: protocol.Position{Line: 5, Character: 5},
+		End:   protocol.Position{Line: 5, Character: 5},
+	}, protocol.RefactorRewrite)
+	if !assert.Len(t, actions, 1) {
+		return
+	}
+	assert.Equal(t, "Merge into one go:embed directive", actions[0].Title)
+	assert.Equal(t, "package main\n\n"+
+		"//go:embed a.txt b.txt static\n"+
+		"var content embed.FS\n\n"+
+		"//go:embed c.txt\n"+
+		"var c string\n"+
+		"//go:embed d.txt\n"+
+		"var d string\n", applyEdits(source, actions[0].Edit.Changes[docURI]))
+
+	assert.Empty(t, codeActions(t, handler, docURI, protocol.Range{
+		Start: protocol.Position{Line: 7},
+		End:   protocol.Position{Line: 10},
+	}, protocol.RefactorRewrite))
+}
+
+// TestCodeActionShowMatchedFiles tests that the glob patterns of a
+// directive are offered an action showing their matched files.
+func TestCodeActionShowMatchedFiles(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n"+
+		"//go:embed hello.txt static/*.html all:assets/*\n"+
+		"var f embed.FS\n")
+	lines := protocol.Range{
+		Start: protocol.Position{Line: 2},
+		End:   protocol.Position{Line: 2, Character: 3},
+	}
+
+	actions := codeActions(t, handler, docURI, lines, protocol.Source)
+	if assert.Len(t, actions, 2) {
+		assert.Equal(t, "Show the files matched by static/*.html", actions[0].Title)
+		assert.Equal(t, &protocol.Command{
+			Title:     "Show the files matched by static/*.html",
+			Command:   lsp.CommandShowMatchedFiles,
+			Arguments: []interface{}{string(docURI), "static/*.html"},
+		}, actions[0].Command)
+		assert.Equal(t, "all:assets/*", actions[1].Command.Arguments[1])
+	}
+	assert.Empty(t, codeActions(t, handler, docURI, lines, protocol.QuickFix))
+}
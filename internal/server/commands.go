@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+func (l *lspHandler) handleWorkspaceExecuteCommand(
+	ctx context.Context,
+	request lsp.ExecuteCommandRequest,
+) (rpc.MethodActor, error) {
+	var result interface{}
+	var err error
+	switch request.Params.Command {
+	case lsp.CommandFindEmbedders:
+		result, err = l.findEmbedders(ctx, request.Params.Arguments)
+	case lsp.CommandStats:
+		result = l.stats()
+	case lsp.CommandScaffold:
+		result, err = l.scaffold(request.Params.Arguments)
+	case lsp.CommandShowMatchedFiles:
+		result, err = l.showMatchedFiles(ctx, request.Params.Arguments)
+	default:
+		return nil, fmt.Errorf(
+			"unknown command: %s",
+			request.Params.Command,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lsp.ExecuteCommandResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: result,
+	}, nil
+}
+
+// findEmbedders returns the locations of the go:embed patterns embedding
+// the asset given as the only argument.
+//
+// The open Go documents are searched along with the Go files on disk next
+// to them.
+func (l *lspHandler) findEmbedders(
+	ctx context.Context,
+	arguments []interface{},
+) ([]protocol.Location, error) {
+	if len(arguments) != 1 {
+		return nil, fmt.Errorf(
+			"%s expects 1 argument, got %d",
+			lsp.CommandFindEmbedders,
+			len(arguments),
+		)
+	}
+	asset, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s expects a string argument, got %T",
+			lsp.CommandFindEmbedders,
+			arguments[0],
+		)
+	}
+	if strings.HasPrefix(asset, "file://") {
+		asset = uriToPath(uri.URI(asset))
+	}
+	sources := make(map[string]string)
+	l.documents.ForEach(func(document uri.URI, source string) bool {
+		sources[uriToPath(document)] = source
+		return true
+	})
+	dirs := make(map[string]bool)
+	for filename := range sources {
+		dirs[filepath.Dir(filename)] = true
+	}
+	for dir := range dirs {
+		entries, err := l.fs.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory: %w", err)
+		}
+		for _, entry := range entries {
+			filename := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || !strings.HasSuffix(filename, ".go") {
+				continue
+			}
+			if l.unlisted(filename, false) {
+				continue
+			}
+			if _, ok := sources[filename]; ok {
+				continue
+			}
+			data, err := l.fs.ReadFile(filename)
+			if err != nil {
+				return nil, fmt.Errorf("error reading file: %w", err)
+			}
+			sources[filename] = string(data)
+		}
+	}
+	locations := make([]protocol.Location, 0)
+	for filename, source := range sources {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+		default:
+		}
+		rel, err := filepath.Rel(uriToDir(uri.File(filename)), asset)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		for _, directive := range parsers.ParseDirectives(source) {
+			for _, pattern := range directive.Patterns {
+				if !parsers.MatchPattern(pattern.Value, rel) {
+					continue
+				}
+				locations = append(locations, protocol.Location{
+					URI:   uri.File(filename),
+					Range: directive.Range(pattern),
+				})
+			}
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].URI != locations[j].URI {
+			return locations[i].URI < locations[j].URI
+		}
+		return locations[i].Range.Start.Line < locations[j].Range.Start.Line
+	})
+	return locations, nil
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// writeTestFiles writes the given files, keyed by slash-separated path,
+// into a new temporary directory and returns it.
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestFindEmbedders tests that findEmbedders returns both the open and the
+// on-disk Go files embedding an asset.
+func TestFindEmbedders(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"static/index.html": "<h1>hi</h1>",
+		"static/app.css":    "body {}",
+		"main.go":           "package main\n\n//go:embed static/*.html\nvar pages embed.FS\n",
+		"assets.go":         "package main\n\n//go:embed static\nvar static embed.FS\n",
+		"other.go":          "package main\n\n//go:embed static/*.css\nvar styles embed.FS\n",
+	})
+	handler, _ := newTestHandler()
+	mainURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(mainURI, "package main\n\n//go:embed static/*.html\nvar pages embed.FS\n")
+
+	asset := filepath.Join(dir, "static", "index.html")
+	resp, err := handler.handleWorkspaceExecuteCommand(
+		context.Background(),
+		lsp.ExecuteCommandRequest{
+			Request: lsp.Request{RPC: lsp.RPCVersion, ID: 7},
+			Params: protocol.ExecuteCommandParams{
+				Command:   lsp.CommandFindEmbedders,
+				Arguments: []interface{}{string(uri.File(asset))},
+			},
+		},
+	)
+	assert.NoError(t, err)
+	result, ok := resp.(lsp.ExecuteCommandResponse)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 7, result.ID)
+	locations, ok := result.Result.([]protocol.Location)
+	if !assert.True(t, ok) {
+		return
+	}
+	if assert.Len(t, locations, 2) {
+		assert.Equal(t, uri.File(filepath.Join(dir, "assets.go")), locations[0].URI)
+		assert.Equal(t, protocol.Range{
+			Start: protocol.Position{Line: 2, Character: 11},
+			End:   protocol.Position{Line: 2, Character: 17},
+		}, locations[0].Range)
+		assert.Equal(t, mainURI, locations[1].URI)
+	}
+}
+
+// TestExecuteUnknownCommand tests that unknown commands are rejected.
+func TestExecuteUnknownCommand(t *testing.T) {
+	handler, _ := newTestHandler()
+	_, err := handler.handleWorkspaceExecuteCommand(
+		context.Background(),
+		lsp.ExecuteCommandRequest{
+			Params: protocol.ExecuteCommandParams{Command: "embedpls.nope"},
+		},
+	)
+	assert.Error(t, err)
+}
+
+// TestShowMatchedFiles tests that the files matched by a glob pattern are
+// both shown to the user and returned.
+func TestShowMatchedFiles(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"static/index.html":      "<h1>hi</h1>",
+		"static/about.html":      "<h1>about</h1>",
+		"static/app.css":         "body {}",
+		"static/sub/page.html":   "<h1>page</h1>",
+		"static/sub/.draft.html": "<h1>draft</h1>",
+		"templates/layout.tmpl":  "{{.}}",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		message string
+	}{
+		{
+			name:    "glob",
+			pattern: "static/*.html",
+			want:    []string{"static/about.html", "static/index.html"},
+			message: "static/*.html matches 2 file(s):\nstatic/about.html\nstatic/index.html",
+		},
+		{
+			name:    "matched directory",
+			pattern: "static/s*",
+			want:    []string{"static/sub/page.html"},
+			message: "static/s* matches 1 file(s):\nstatic/sub/page.html",
+		},
+		{
+			name:    "all prefix",
+			pattern: "all:static/s*",
+			want:    []string{"static/sub/.draft.html", "static/sub/page.html"},
+			message: "all:static/s* matches 2 file(s):\n" +
+				"static/sub/.draft.html\nstatic/sub/page.html",
+		},
+		{
+			name:    "no match",
+			pattern: "static/*.js",
+			want:    []string{},
+			message: "static/*.js matches no file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, out := newTestHandler()
+			resp, err := handler.handleWorkspaceExecuteCommand(
+				context.Background(),
+				lsp.ExecuteCommandRequest{
+					Request: lsp.Request{RPC: lsp.RPCVersion, ID: 3},
+					Params: protocol.ExecuteCommandParams{
+						Command:   lsp.CommandShowMatchedFiles,
+						Arguments: []interface{}{string(docURI), tt.pattern},
+					},
+				},
+			)
+			assert.NoError(t, err)
+			result, ok := resp.(lsp.ExecuteCommandResponse)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tt.want, result.Result)
+			messages := readTestMessages(t, out)
+			if assert.Len(t, messages, 1) {
+				assert.Equal(t, "window/showMessage", messages[0]["method"])
+				params := messages[0]["params"].(map[string]interface{})
+				assert.Equal(t, tt.message, params["message"])
+			}
+		})
+	}
+}
+
+// TestShowMatchedFilesArguments tests that malformed arguments are
+// rejected.
+func TestShowMatchedFilesArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []interface{}
+	}{
+		{name: "no argument", arguments: nil},
+		{name: "no pattern", arguments: []interface{}{"file:///tmp/main.go"}},
+		{name: "non-string pattern", arguments: []interface{}{"file:///tmp/main.go", 1.0}},
+		{name: "invalid pattern", arguments: []interface{}{"file:///tmp/main.go", "static/["}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			_, err := handler.handleWorkspaceExecuteCommand(
+				context.Background(),
+				lsp.ExecuteCommandRequest{
+					Params: protocol.ExecuteCommandParams{
+						Command:   lsp.CommandShowMatchedFiles,
+						Arguments: tt.arguments,
+					},
+				},
+			)
+			if err == nil {
+				t.Errorf("expected an error for %v", tt.arguments)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const (
+	// maxCompletionScore bounds the score of a completion candidate so that
+	// it fits the fixed width of a completion item's sort text.
+	maxCompletionScore = 9999
+)
+
+// completionScore returns how well a candidate matches the fragment typed
+// by the user, higher being better.
+//
+// The fragment matches when its characters appear in order in the
+// candidate, ignoring case. Matches at the start of the candidate, at the
+// start of a word and in consecutive runs score higher. A candidate that
+// does not match scores zero and an empty fragment matches everything
+// equally.
+func completionScore(fragment, candidate string) int {
+	if fragment == "" {
+		return 1
+	}
+	fragment = strings.ToLower(fragment)
+	lower := strings.ToLower(candidate)
+	score := 1
+	run := 0
+	next := 0
+	for i := 0; i < len(lower) && next < len(fragment); i++ {
+		if lower[i] != fragment[next] {
+			run = 0
+			continue
+		}
+		score++
+		switch {
+		case i == 0:
+			score += 8
+		case strings.ContainsRune("/._-", rune(lower[i-1])):
+			score += 4
+		}
+		if run > 0 {
+			score += 2 * run
+		}
+		run++
+		next++
+	}
+	if next < len(fragment) {
+		return 0
+	}
+	// prefer shorter candidates among equal matches.
+	score = score*100 - len(candidate)
+	if score < 1 {
+		score = 1
+	}
+	if score > maxCompletionScore {
+		score = maxCompletionScore
+	}
+	return score
+}
+
+// newCompletionItems returns the completion items for the embeddables
+// ordered by their score against the typed fragment.
+//
+// The order is carried by the SortText of each item, so clients sort the
+// best matches first and, among equal scores, directories before files.
+//
+// When the range of the typed pattern is given, the items replace it
+// whole. The items are documented lazily, when resolved, from the path
+// carried in their data.
+func newCompletionItems(
+	fragment string,
+	embeddables []embeddable,
+	replace *protocol.Range,
+) []protocol.CompletionItem {
+	items := make([]protocol.CompletionItem, 0, len(embeddables))
+	for _, embed := range embeddables {
+		kind := protocol.CompletionItemKindFile
+		group := 1
+		if embed.isDir {
+			kind = protocol.CompletionItemKindFolder
+			group = 0
+		}
+		var edit *protocol.TextEdit
+		if replace != nil {
+			edit = &protocol.TextEdit{Range: *replace, NewText: embed.name}
+		}
+		items = append(items, protocol.CompletionItem{
+			TextEdit: edit,
+			Label:    embed.name,
+			Detail:   embed.name,
+			Data:     completionData{Path: embed.path},
+			Kind:     kind,
+			SortText: fmt.Sprintf(
+				"%04d-%d-%s",
+				maxCompletionScore-completionScore(fragment, embed.name),
+				group,
+				embed.name,
+			),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].SortText < items[j].SortText
+	})
+	return items
+}
+
+// isTriggered reports whether a completion was triggered by typing one of
+// the trigger characters of the server, a path separator or a dot.
+func isTriggered(completion *protocol.CompletionContext) bool {
+	return completion != nil &&
+		completion.TriggerKind == protocol.CompletionTriggerKindTriggerCharacter
+}
+
+// segmentEmbeddables returns the embeddables whose name matches the last
+// element of the pattern typed so far.
+//
+// Completions triggered as the user types a path are scoped to the element
+// being typed, so a slash lists the whole directory it opens while a dot,
+// such as in "static/index.", narrows it to the names matching the
+// element.
+func segmentEmbeddables(typed string, embeddables []embeddable) []embeddable {
+	segment := typed[strings.LastIndex(typed, "/")+1:]
+	scoped := make([]embeddable, 0, len(embeddables))
+	for _, embed := range embeddables {
+		name := strings.TrimSuffix(embed.name, "/")
+		name = name[strings.LastIndex(name, "/")+1:]
+		if completionScore(segment, name) > 0 {
+			scoped = append(scoped, embed)
+		}
+	}
+	return scoped
+}
+
+// completionData is the data of a completion item, kept by the client
+// between completing and resolving the item.
+type completionData struct {
+	// Path is the file path of the completed file or directory.
+	Path string `json:"path"`
+}
+
+// handleCompletionItemResolve documents the completion item selected by
+// the user with a preview of its file, which is too costly to read for
+// every item of a directory upfront.
+func (l *lspHandler) handleCompletionItemResolve(
+	_ context.Context,
+	request lsp.CompletionItemResolveRequest,
+) (rpc.MethodActor, error) {
+	item := request.Params
+	resp := lsp.CompletionItemResolveResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: item,
+	}
+	// the data comes back decoded as a generic JSON value
+	raw, err := json.Marshal(item.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode completion data: %w", err)
+	}
+	var data completionData
+	if err := json.Unmarshal(raw, &data); err != nil || data.Path == "" {
+		// items of other servers or without data are left as they are
+		return resp, nil
+	}
+	info, err := l.fs.Stat(data.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat completed file: %w", err)
+	}
+	if info.IsDir() {
+		resp.Result.Documentation = l.markup("directory")
+		return resp, nil
+	}
+	head, size, err := readHead(l.fs, data.Path, l.currentConfig().Preview())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read completed file: %w", err)
+	}
+	resp.Result.Documentation = l.markup(l.filePreview(item.Label, head, size))
+	return resp, nil
+}
+
+// readFileCompletion returns the completion items of the path of a
+// ReadFile call under a position of a document, such as
+// fs.ReadFile(content, "st"), which are the files embedded by the
+// embed.FS variable read from, named by their path in it.
+//
+// Positions outside of such calls, or in calls reading another variable,
+// get no item. Patterns failing to resolve are left out, as their
+// diagnostics already report them.
+func (l *lspHandler) readFileCompletion(
+	ctx context.Context,
+	docURI uri.URI,
+	source string,
+	position protocol.Position,
+) ([]protocol.CompletionItem, error) {
+	call, ok := parsers.ParseReadFileCall(source, position)
+	if !ok {
+		return []protocol.CompletionItem{}, nil
+	}
+	index, ok := l.documentIndex(docURI)
+	if !ok {
+		return []protocol.CompletionItem{}, nil
+	}
+	var block parsers.EmbedBlock
+	found := false
+	for _, candidate := range index.Blocks() {
+		if candidate.Var == call.FS && candidate.IsFS() {
+			block, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return []protocol.CompletionItem{}, nil
+	}
+	dir := l.embedDir(docURI)
+	names := make(map[string]bool)
+	patterns := block.Patterns()
+	p := l.beginProgress(ctx, "Resolving "+block.Var)
+	for i, pattern := range patterns {
+		p.report(ctx, pattern, uint32(i*100/len(patterns)))
+		files, err := resolver.ResolvePattern(ctx, l.fs, dir, pattern)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				p.end(ctx, "cancelled")
+				return nil, fmt.Errorf("context cancelled: %w", ctxErr)
+			}
+			continue
+		}
+		for _, name := range files {
+			names[name] = true
+		}
+	}
+	p.end(ctx, fmt.Sprintf("%d file(s)", len(names)))
+	embeddables := make([]embeddable, 0, len(names))
+	for name := range names {
+		embeddables = append(embeddables, embeddable{
+			name: name,
+			path: filepath.Join(dir, filepath.FromSlash(name)),
+		})
+	}
+	return newCompletionItems(call.Path, embeddables, &call.Range), nil
+}
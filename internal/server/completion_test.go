@@ -0,0 +1,486 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/config"
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestCompletionScore tests the relevance score of completion candidates.
+func TestCompletionScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		better   string
+		worse    string
+	}{
+		{
+			name:     "subsequence beats non-match",
+			fragment: "tmpl",
+			better:   "templates/",
+			worse:    "main.go",
+		},
+		{
+			name:     "prefix beats inner match",
+			fragment: "st",
+			better:   "static/",
+			worse:    "test.txt",
+		},
+		{
+			name:     "consecutive beats scattered",
+			fragment: "logo",
+			better:   "logo.png",
+			worse:    "large_output.go",
+		},
+		{
+			name:     "word start beats inner match",
+			fragment: "png",
+			better:   "favicon.png",
+			worse:    "spring.txt",
+		},
+		{
+			name:     "case is ignored",
+			fragment: "READ",
+			better:   "readme.md",
+			worse:    "main.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better := completionScore(tt.fragment, tt.better)
+			worse := completionScore(tt.fragment, tt.worse)
+			if better <= worse {
+				t.Errorf(
+					"completionScore(%q, %q) = %d, want more than completionScore(%q, %q) = %d",
+					tt.fragment, tt.better, better,
+					tt.fragment, tt.worse, worse,
+				)
+			}
+		})
+	}
+	assert.Zero(t, completionScore("tmpl", "main.go"))
+	assert.Positive(t, completionScore("", "main.go"))
+}
+
+// TestNewCompletionItems tests that completion items are ordered by their
+// score, with directories before files on equal scores.
+func TestNewCompletionItems(t *testing.T) {
+	embeddables := []embeddable{
+		{name: "main.go"},
+		{name: "templates/", isDir: true},
+		{name: "index.tmpl"},
+	}
+	labels := func(fragment string) []string {
+		items := newCompletionItems(fragment, embeddables, nil)
+		labels := make([]string, 0, len(items))
+		for _, item := range items {
+			assert.NotEmpty(t, item.SortText)
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+	assert.Equal(t, []string{"index.tmpl", "templates/", "main.go"}, labels("tmpl"))
+	assert.Equal(t, []string{"templates/", "index.tmpl", "main.go"}, labels(""))
+}
+
+// TestCompletionSubdirectory tests that completing a pattern ending in a
+// slash lists the entries of that subdirectory, relative to the source
+// file.
+func TestCompletionSubdirectory(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":              "package main\n",
+		"root.txt":             "root",
+		"static/app.js":        "app",
+		"static/css/style.css": "style",
+	})
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "subdirectory",
+			pattern: "static/",
+			want:    []string{"static/css/", "static/app.js"},
+		},
+		{
+			name:    "partial name in subdirectory",
+			pattern: "static/ap",
+			want:    []string{"static/app.js", "static/css/"},
+		},
+		{
+			name:    "nested subdirectory",
+			pattern: "static/css/",
+			want:    []string{"static/css/style.css"},
+		},
+		{
+			name:    "outside of the package",
+			pattern: "../",
+			want:    []string{},
+		},
+		{
+			name:    "all prefix",
+			pattern: "all:static/",
+			want:    []string{"all:static/css/", "all:static/app.js"},
+		},
+		{
+			name:    "all prefix outside of the package",
+			pattern: "all:../",
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			docURI := uri.File(filepath.Join(dir, "main.go"))
+			line := "//go:embed " + tt.pattern
+			handler.documents.Set(docURI, "package main\n\n"+line+"\nvar static embed.FS\n")
+			resp, err := handler.handleTextDocumentCompletion(
+				context.Background(),
+				lsp.TextDocumentCompletionRequest{
+					Params: protocol.CompletionParams{
+						TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+							TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+							Position:     protocol.Position{Line: 2, Character: uint32(len(line))},
+						},
+					},
+				},
+			)
+			assert.NoError(t, err)
+			items := resp.(*lsp.TextDocumentCompletionResponse).Result
+			labels := make([]string, 0, len(items))
+			for _, item := range items {
+				labels = append(labels, item.Label)
+				if assert.NotNil(t, item.TextEdit) {
+					assert.Equal(t, uint32(len("//go:embed ")), item.TextEdit.Range.Start.Character)
+					assert.Equal(t, uint32(len(line)), item.TextEdit.Range.End.Character)
+					assert.Equal(t, item.Label, item.TextEdit.NewText)
+				}
+			}
+			assert.Equal(t, tt.want, labels)
+		})
+	}
+}
+
+// TestEmbeddablesCancelled tests that listing the entries of a directory
+// stops with the error of its context once it is cancelled.
+func TestEmbeddablesCancelled(t *testing.T) {
+	files := map[string]string{"main.go": "package main\n"}
+	for i := 0; i < 100; i++ {
+		files[fmt.Sprintf("file%03d.txt", i)] = "content"
+	}
+	dir := writeTestFiles(t, files)
+	handler, _ := newTestHandler()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errCh := make(chan error, 1)
+	select {
+	case resp := <-handler.getEmbbeddables(ctx, uri.File(filepath.Join(dir, "main.go")), "", errCh):
+		t.Fatalf("listed %d entries after the cancellation", len(resp.embeddables))
+	case err := <-errCh:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("listing did not stop after the cancellation")
+	}
+}
+
+// TestCompletionResolve tests that completion items carry no
+// documentation until resolved, and that resolving an item sent back by
+// the client documents it with a preview of its first lines for text
+// files and with a size summary for binary files.
+func TestCompletionResolve(t *testing.T) {
+	lines := make([]string, 0, 20)
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":     "package main\n",
+		"notes.txt":   strings.Join(lines, "\n") + "\n",
+		"logo.png":    "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"static/a.js": "a",
+	})
+	handler, _ := newTestHandler()
+	resp := <-handler.getEmbbeddables(
+		context.Background(),
+		uri.File(filepath.Join(dir, "main.go")),
+		"",
+		make(chan error, 1),
+	)
+	docs := make(map[string]protocol.MarkupContent)
+	for i, item := range newCompletionItems("", resp.embeddables, nil) {
+		assert.Nil(t, item.Documentation)
+		params, err := json.Marshal(item)
+		assert.NoError(t, err)
+		result, err := handler.handle(context.Background(), newTestMessage(t, fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%d,"method":"completionItem/resolve","params":%s}`,
+			i+1,
+			params,
+		)))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		resolved, ok := result.(lsp.CompletionItemResolveResponse)
+		if !assert.True(t, ok, "unexpected result %#v", result) {
+			continue
+		}
+		assert.Equal(t, i+1, resolved.ID)
+		assert.Equal(t, item.Label, resolved.Result.Label)
+		doc, ok := resolved.Result.Documentation.(protocol.MarkupContent)
+		if !assert.True(t, ok, "documentation of %s = %#v", item.Label, resolved.Result.Documentation) {
+			continue
+		}
+		docs[item.Label] = doc
+	}
+
+	text := docs["notes.txt"]
+	assert.Equal(t, protocol.Markdown, text.Kind)
+	assert.True(t, strings.HasPrefix(text.Value, "```txt\nline 1\n"), text.Value)
+	assert.Contains(t, text.Value, "line 10\n```\n")
+	assert.NotContains(t, text.Value, "line 11")
+	assert.Contains(t, text.Value, "151 B in total")
+
+	assert.Equal(t, "binary file, 16 B, image/png", docs["logo.png"].Value)
+	assert.Equal(t, "directory", docs["static/"].Value)
+}
+
+// TestFormatSize tests formatting file sizes for humans.
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{size: 0, want: "0 B"},
+		{size: 1023, want: "1023 B"},
+		{size: 1024, want: "1.0 KiB"},
+		{size: 1536, want: "1.5 KiB"},
+		{size: 5 << 20, want: "5.0 MiB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.size); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
+
+// TestCompletionOutsideDirective tests that completing outside of a
+// directive replies with an empty list rather than no response.
+func TestCompletionOutsideDirective(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n//go:embed a.txt\nvar a string\n")
+	resp, err := handler.Handle(context.Background(), newTestMessage(t,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/completion","params":{`+
+			`"textDocument":{"uri":"`+string(docURI)+`"},"position":{"line":3,"character":2}}}`,
+	))
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp) {
+		encoded, err := json.Marshal(resp)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":4,"result":[]}`, string(encoded))
+	}
+}
+
+// TestCompletionTriggerCharacter tests that completions triggered by a path
+// separator or a dot are scoped to the path element being typed.
+func TestCompletionTriggerCharacter(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":              "package main\n",
+		"static/app.js":        "app",
+		"static/index.html":    "index",
+		"static/index.css":     "index",
+		"static/css/style.css": "style",
+	})
+	tests := []struct {
+		name    string
+		pattern string
+		trigger string
+		want    []string
+	}{
+		{
+			name:    "slash",
+			pattern: "static/",
+			trigger: "/",
+			want:    []string{"static/css/", "static/app.js", "static/index.css", "static/index.html"},
+		},
+		{
+			name:    "dot",
+			pattern: "static/index.",
+			trigger: ".",
+			want:    []string{"static/index.css", "static/index.html"},
+		},
+		{
+			name:    "all prefix slash",
+			pattern: "all:static/css/",
+			trigger: "/",
+			want:    []string{"all:static/css/style.css"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			docURI := uri.File(filepath.Join(dir, "main.go"))
+			line := "//go:embed " + tt.pattern
+			handler.documents.Set(docURI, "package main\n\n"+line+"\nvar static embed.FS\n")
+			resp, err := handler.handleTextDocumentCompletion(
+				context.Background(),
+				lsp.TextDocumentCompletionRequest{
+					Params: protocol.CompletionParams{
+						TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+							TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+							Position:     protocol.Position{Line: 2, Character: uint32(len(line))},
+						},
+						Context: &protocol.CompletionContext{
+							TriggerKind:      protocol.CompletionTriggerKindTriggerCharacter,
+							TriggerCharacter: tt.trigger,
+						},
+					},
+				},
+			)
+			assert.NoError(t, err)
+			labels := make([]string, 0)
+			for _, item := range resp.(*lsp.TextDocumentCompletionResponse).Result {
+				labels = append(labels, item.Label)
+			}
+			assert.ElementsMatch(t, tt.want, labels)
+		})
+	}
+}
+
+// TestCompletionRespectsGitignore tests that the files ignored by git are
+// only left out of completions when the configuration respects them.
+func TestCompletionRespectsGitignore(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		".git/HEAD":         "ref: refs/heads/main\n",
+		".gitignore":        "*.min.js\ndist/\n",
+		"main.go":           "package main\n",
+		"static/app.js":     "app",
+		"static/app.min.js": "app",
+		"static/dist/a.js":  "a",
+	})
+	for _, respect := range []bool{false, true} {
+		t.Run(fmt.Sprintf("respect %v", respect), func(t *testing.T) {
+			handler, _ := newTestHandler()
+			cfg := config.Default()
+			cfg.RespectGitignore = respect
+			handler.setConfig(cfg)
+			docURI := uri.File(filepath.Join(dir, "main.go"))
+			errCh := make(chan error, 1)
+			resp := <-handler.getEmbbeddables(context.Background(), docURI, "static/", errCh)
+			names := make([]string, 0)
+			for _, embed := range resp.embeddables {
+				names = append(names, embed.name)
+			}
+			want := []string{"static/app.js"}
+			if !respect {
+				want = append(want, "static/app.min.js", "static/dist/")
+			}
+			assert.ElementsMatch(t, want, names)
+		})
+	}
+}
+
+// TestCompletionReadFile tests that the path of a ReadFile call reading an
+// embed.FS variable of the document completes to the files it embeds.
+func TestCompletionReadFile(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"static/app.js":         "app",
+		"static/css/style.css":  "style",
+		"static/.hidden":        "hidden",
+		"templates/index.tmpl":  "index",
+		"templates/layout.tmpl": "layout",
+		"other.txt":             "other",
+	})
+	source := "package main\n\n" +
+		"//go:embed static templates/*.tmpl missing.txt\n" +
+		"var content embed.FS\n\n" +
+		"//go:embed other.txt\n" +
+		"var other string\n\n" +
+		"func main() {\n" +
+		"\tfs.ReadFile(content, \"static/\")\n" +
+		"\tcontent.ReadFile(\"\")\n" +
+		"\tfs.ReadFile(other, \"\")\n" +
+		"\tos.ReadFile(\"\")\n" +
+		"}\n"
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	tests := []struct {
+		name      string
+		position  protocol.Position
+		want      []string
+		wantRange protocol.Range
+	}{
+		{
+			name:     "fs.ReadFile",
+			position: protocol.Position{Line: 9, Character: 23},
+			want: []string{
+				"static/app.js",
+				"static/css/style.css",
+				"templates/index.tmpl",
+				"templates/layout.tmpl",
+			},
+			wantRange: protocol.Range{
+				Start: protocol.Position{Line: 9, Character: 23},
+				End:   protocol.Position{Line: 9, Character: 30},
+			},
+		},
+		{
+			name:     "method",
+			position: protocol.Position{Line: 10, Character: 19},
+			want: []string{
+				"static/app.js",
+				"static/css/style.css",
+				"templates/index.tmpl",
+				"templates/layout.tmpl",
+			},
+			wantRange: protocol.Range{
+				Start: protocol.Position{Line: 10, Character: 19},
+				End:   protocol.Position{Line: 10, Character: 19},
+			},
+		},
+		{
+			name:     "not an embed.FS",
+			position: protocol.Position{Line: 11, Character: 21},
+			want:     []string{},
+		},
+		{
+			name:     "not an embedding variable",
+			position: protocol.Position{Line: 12, Character: 14},
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			handler.documents.Set(docURI, source)
+			resp, err := handler.handleTextDocumentCompletion(
+				context.Background(),
+				lsp.TextDocumentCompletionRequest{
+					Params: protocol.CompletionParams{
+						TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+							TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+							Position:     tt.position,
+						},
+					},
+				},
+			)
+			assert.NoError(t, err)
+			labels := make([]string, 0)
+			for _, item := range resp.(*lsp.TextDocumentCompletionResponse).Result {
+				labels = append(labels, item.Label)
+				if assert.NotNil(t, item.TextEdit) {
+					assert.Equal(t, tt.wantRange, item.TextEdit.Range)
+				}
+			}
+			assert.ElementsMatch(t, tt.want, labels)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// unlisted reports whether the file or directory at filename is left out
+// of the directory listings of completion and commands: when the
+// configuration ignores it, or the .gitignore files of its repository do
+// and the configuration respects them.
+func (l *lspHandler) unlisted(filename string, isDir bool) bool {
+	if l.currentConfig().RespectGitignore && l.gitignore.Ignored(filename, isDir) {
+		return true
+	}
+	return l.ignored(filename, isDir)
+}
+
+// ignored reports whether the ignore patterns of the configuration of the
+// workspace match the file or directory at filename.
+//
+// The .gitignore files are left out: ignored files are still embedded by
+// the go command, so the diagnostics about them stay relevant.
+//
+// Files outside of the workspace root are only matched by their name.
+func (l *lspHandler) ignored(filename string, isDir bool) bool {
+	name := filepath.Base(filename)
+	if l.root != "" {
+		rel, err := filepath.Rel(l.root, filename)
+		if err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			name = rel
+		}
+	}
+	return l.currentConfig().Ignored(filepath.ToSlash(name), isDir)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/conneroisu/embedpls/internal/config"
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// configurationReply is the reply of the client to a
+// workspace/configuration request.
+type configurationReply struct {
+	// Result holds the settings of each requested section.
+	Result []json.RawMessage `json:"result"`
+	// Error is the error of a failed request.
+	Error json.RawMessage `json:"error"`
+}
+
+// handleInitialized registers the capabilities of the server that clients
+// only accept dynamically, then pulls the settings of the client once it
+// is initialized, when it supports workspace/configuration requests.
+func (l *lspHandler) handleInitialized(
+	ctx context.Context,
+	_ *rpc.BaseMessage,
+) (rpc.MethodActor, error) {
+	if l.watchedFiles {
+		if err := l.registerFileWatchers(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if !l.configuration {
+		return nil, nil
+	}
+	return nil, l.requestConfiguration(ctx)
+}
+
+// requestConfiguration sends a workspace/configuration request for the
+// embedpls section of the settings of the client, which are applied when
+// the reply arrives.
+func (l *lspHandler) requestConfiguration(ctx context.Context) error {
+	item := protocol.ConfigurationItem{Section: config.Section}
+	if l.root != "" {
+		item.ScopeURI = uri.File(l.root)
+	}
+	_, err := l.writer.WriteRequest(
+		ctx,
+		methods.MethodWorkspaceConfiguration,
+		protocol.ConfigurationParams{Items: []protocol.ConfigurationItem{item}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to request configuration: %w", err)
+	}
+	return nil
+}
+
+// handleConfigurationReply applies the settings of the client replied to
+// a workspace/configuration request over the configuration file of the
+// workspace, and publishes the diagnostics of the open documents again
+// with the new configuration.
+func (l *lspHandler) handleConfigurationReply(
+	ctx context.Context,
+	msg *rpc.BaseMessage,
+) (rpc.MethodActor, error) {
+	var reply configurationReply
+	if err := json.Unmarshal(msg.Content, &reply); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	if len(reply.Error) > 0 && string(reply.Error) != "null" {
+		l.logger.Warnf("client failed to send its configuration: %s", reply.Error)
+		return nil, nil
+	}
+	if len(reply.Result) == 0 {
+		return nil, nil
+	}
+	cfg, err := l.loadConfig(reply.Result[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply client configuration: %w", err)
+	}
+	l.settings = reply.Result[0]
+	l.setConfig(cfg)
+	return nil, l.republishDiagnostics(ctx)
+}
+
+// handleWorkspaceDidChangeConfiguration reloads the configuration when the
+// settings of the client change and publishes the diagnostics of the open
+// documents again with the new configuration.
+//
+// Clients supporting workspace/configuration are asked for their settings
+// as the settings of the notification are deprecated in favor of pulling
+// them. Otherwise the embedpls section of the settings of the
+// notification, if any, is applied over the configuration file.
+func (l *lspHandler) handleWorkspaceDidChangeConfiguration(
+	ctx context.Context,
+	request lsp.DidChangeConfigurationNotification,
+) (rpc.MethodActor, error) {
+	if l.configuration {
+		return nil, l.requestConfiguration(ctx)
+	}
+	settings := configSection(request.Params.Settings)
+	cfg, err := l.loadConfig(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply client configuration: %w", err)
+	}
+	l.settings = settings
+	l.setConfig(cfg)
+	return nil, l.republishDiagnostics(ctx)
+}
+
+// configSection returns the embedpls section of the settings pushed by a
+// client, or nil when they have none.
+func configSection(settings interface{}) []byte {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return nil
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &sections); err != nil {
+		return nil
+	}
+	return sections[config.Section]
+}
+
+// loadConfig returns the configuration file of the workspace, with the
+// settings of the client applied over it when not empty.
+func (l *lspHandler) loadConfig(settings []byte) (config.Config, error) {
+	cfg := config.Default()
+	if l.root != "" {
+		loaded, err := config.Load(l.root)
+		if err != nil {
+			l.logger.Warnf("using default config: %v", err)
+		}
+		cfg = loaded
+	}
+	if len(settings) == 0 {
+		return cfg, nil
+	}
+	return config.Apply(cfg, settings)
+}
+
+// setConfig replaces the configuration of the workspace, applying its log
+// level to the logger of the session.
+func (l *lspHandler) setConfig(cfg config.Config) {
+	l.configMu.Lock()
+	l.config = cfg
+	l.configMu.Unlock()
+	if cfg.LogLevel == "" {
+		return
+	}
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		l.logger.Warnf("ignoring log level: %v", err)
+		return
+	}
+	l.logger.SetLevel(level)
+}
+
+// currentConfig returns the configuration of the workspace, which a
+// configuration change may replace while requests are handled.
+func (l *lspHandler) currentConfig() config.Config {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	return l.config
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
+)
+
+// TestConfigurationRequest tests that the settings of a client supporting
+// workspace/configuration are pulled once it is initialized and applied
+// when it replies.
+func TestConfigurationRequest(t *testing.T) {
+	level := log.GetLevel()
+	handler, out := newTestHandler()
+	ctx := context.Background()
+	_, err := handler.Handle(ctx, newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{"workspace":{"configuration":true}}}}`,
+	))
+	assert.NoError(t, err)
+	_, err = handler.Handle(ctx, newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+	))
+	assert.NoError(t, err)
+	messages := readTestMessages(t, out)
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	assert.Equal(t, "workspace/configuration", messages[0]["method"])
+	assert.Equal(t, map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"section": "embedpls"}},
+	}, messages[0]["params"])
+
+	_, err = handler.Handle(ctx, newTestMessage(t, fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":%v,"result":[{"ignore":["*.tmp"],"previewSize":64,"logLevel":"error"}]}`,
+		messages[0]["id"],
+	)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*.tmp"}, handler.currentConfig().Ignore)
+	assert.Equal(t, 64, handler.currentConfig().Preview())
+	assert.Equal(t, log.ErrorLevel, handler.logger.GetLevel())
+	assert.Equal(t, level, log.GetLevel())
+}
+
+// TestConfigurationUnsupported tests that no configuration is requested
+// from clients not supporting workspace/configuration.
+func TestConfigurationUnsupported(t *testing.T) {
+	handler, out := newTestHandler()
+	_, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, out.String())
+}
+
+// TestDidChangeConfiguration tests that changing the ignore list of the
+// settings publishes the diagnostics of the open documents again, clearing
+// the findings about the newly ignored files.
+func TestDidChangeConfiguration(t *testing.T) {
+	source := "package main\n\n//go:embed static static/draft.tmp\nvar f embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":          source,
+		"static/draft.tmp": "draft",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler, out := newTestHandler()
+	ctx := context.Background()
+
+	params, err := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": docURI, "version": 1, "text": source},
+	})
+	assert.NoError(t, err)
+	_, err = handler.Handle(ctx, newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":`+string(params)+`}`,
+	))
+	assert.NoError(t, err)
+	assert.Len(t, publishedDiagnostics(t, out), 2)
+
+	_, err = handler.Handle(ctx, newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"workspace/didChangeConfiguration","params":{"settings":{"embedpls":{"ignore":["*.tmp"]}}}}`,
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*.tmp"}, handler.currentConfig().Ignore)
+	assert.Empty(t, publishedDiagnostics(t, out))
+}
+
+// publishedDiagnostics returns the diagnostics of the last diagnostics
+// published to a buffer, consuming its messages.
+func publishedDiagnostics(t *testing.T, out *bytes.Buffer) []interface{} {
+	t.Helper()
+	messages := readTestMessages(t, out)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i]["method"] == "textDocument/publishDiagnostics" {
+			params := messages[i]["params"].(map[string]interface{})
+			return params["diagnostics"].([]interface{})
+		}
+	}
+	t.Fatal("no diagnostics published")
+	return nil
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// publishDiagnostics diagnoses the given document and publishes the
+// resulting diagnostics to the client, leaving out the diagnostics
+// suppressed by the ignore comments of the document and the findings
+// about the files ignored by the configuration. The optional lints enabled
+// by the configuration are published along.
+//
+// The directives are taken from the index cache, which the queries on the
+// document reuse afterwards.
+func (l *lspHandler) publishDiagnostics(
+	ctx context.Context,
+	uri uri.URI,
+	source string,
+) error {
+	dir := uriToDir(uri)
+	index := l.sourceIndex(uri, source)
+	p := l.beginProgress(ctx, "Diagnosing "+filepath.Base(uriToPath(uri)))
+	diagnostics := parsers.DiagnoseDirProgress(
+		ctx,
+		l.fs,
+		index,
+		dir,
+		func(name string) bool {
+			return l.ignored(filepath.Join(dir, filepath.FromSlash(name)), false)
+		},
+		func(variable string, done, total int) {
+			p.report(ctx, variable, uint32(done*100/total))
+		},
+	)
+	p.end(ctx, fmt.Sprintf("%d diagnostic(s)", len(diagnostics)))
+	if l.currentConfig().LintExportedFS {
+		diagnostics = append(diagnostics, index.DiagnoseExportedFS()...)
+	}
+	return l.writeDiagnostics(ctx, uri, parsers.ParseSuppressions(source).Filter(diagnostics))
+}
+
+// clearDiagnostics publishes an empty list of diagnostics for a closed
+// document, so the client stops showing those published while it was
+// open.
+func (l *lspHandler) clearDiagnostics(ctx context.Context, uri uri.URI) error {
+	return l.writeDiagnostics(ctx, uri, []protocol.Diagnostic{})
+}
+
+// writeDiagnostics sends the diagnostics of a document to the client,
+// replacing those previously published for it.
+func (l *lspHandler) writeDiagnostics(
+	ctx context.Context,
+	uri uri.URI,
+	diagnostics []protocol.Diagnostic,
+) error {
+	err := l.writer.WriteResponse(ctx, lsp.PublishDiagnosticsNotification{
+		Notification: lsp.Notification{
+			RPC:    lsp.RPCVersion,
+			Method: methods.MethodTextDocumentPublishDiagnostics.String(),
+		},
+		Params: protocol.PublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: diagnostics,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish diagnostics: %w", err)
+	}
+	return nil
+}
+
+// republishDiagnostics publishes the diagnostics of every open document
+// again, after a change of the rules they are computed with.
+func (l *lspHandler) republishDiagnostics(ctx context.Context) error {
+	type document struct {
+		uri    uri.URI
+		source string
+	}
+	n, keys := l.documents.LenAndKeys()
+	documents := make([]document, 0, n)
+	for _, key := range keys {
+		// documents closed since the keys were read are left out
+		source, ok := l.documents.Get(key)
+		if !ok {
+			continue
+		}
+		documents = append(documents, document{key, *source})
+	}
+	for _, doc := range documents {
+		if err := l.publishDiagnostics(ctx, doc.uri, doc.source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/config"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
+)
+
+// TestPublishDiagnosticsSuppressed tests that the diagnostics suppressed
+// by an ignore comment are not published.
+func TestPublishDiagnosticsSuppressed(t *testing.T) {
+	source := "//embedpls:ignore path-traversal\n//go:embed ../secret.txt /etc/hosts\nvar f embed.FS\n"
+	handler, out := newTestHandler()
+	err := handler.publishDiagnostics(context.Background(), uri.File("/tmp/main.go"), source)
+	assert.NoError(t, err)
+	messages := readTestMessages(t, out)
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	diagnostics := messages[0]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, "embed/absolute-path", diagnostics[0].(map[string]interface{})["code"])
+	}
+}
+
+// TestPublishDiagnosticsGitignored tests that the findings about the files
+// ignored by the .gitignore files are still published when the
+// configuration respects them, since the go command embeds them anyway.
+func TestPublishDiagnosticsGitignored(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		".git/HEAD":              "ref: refs/heads/main\n",
+		".gitignore":             "vendored/\n",
+		"static/index.html":      "<h1>hi</h1>",
+		"static/vendored/go.mod": "module example.com/vendored\n",
+	})
+	handler, out := newTestHandler()
+	cfg := config.Default()
+	cfg.RespectGitignore = true
+	handler.setConfig(cfg)
+	err := handler.publishDiagnostics(
+		context.Background(),
+		uri.File(filepath.Join(dir, "main.go")),
+		"package main\n\n//go:embed static\nvar content embed.FS\n",
+	)
+	assert.NoError(t, err)
+	diagnostics := publishedDiagnostics(t, out)
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, "embed/nested-module", diagnostics[0].(map[string]interface{})["code"])
+	}
+}
+
+// TestDidCloseClearsDiagnostics tests that closing a Go document publishes
+// an empty list of diagnostics for it, while closing an asset publishes
+// nothing.
+func TestDidCloseClearsDiagnostics(t *testing.T) {
+	handler, out := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	_, err := handler.Handle(context.Background(), newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"`+
+			string(docURI)+`","languageId":"go","version":1,"text":"//go:embed ../secret.txt\nvar s string\n"}}}`,
+	))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, publishedDiagnostics(t, out))
+
+	_, err = handler.Handle(context.Background(), newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"`+
+			string(docURI)+`"}}}`,
+	))
+	assert.NoError(t, err)
+	messages := readTestMessages(t, out)
+	if assert.Len(t, messages, 1) {
+		params := messages[0]["params"].(map[string]interface{})
+		assert.Equal(t, string(docURI), params["uri"])
+		assert.Equal(t, []interface{}{}, params["diagnostics"])
+	}
+
+	_, err = handler.Handle(context.Background(), newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"file:///tmp/hello.txt"}}}`,
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, out.String())
+}
+
+// TestPublishDiagnosticsExportedFS tests that the exported embed.FS lint
+// is only published when the configuration enables it, and can be
+// suppressed like any other diagnostic.
+func TestPublishDiagnosticsExportedFS(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"static/index.html": "<h1>hi</h1>"})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	tests := []struct {
+		name   string
+		lint   bool
+		source string
+		want   []interface{}
+	}{
+		{
+			name:   "disabled",
+			source: "package main\n\n//go:embed static\nvar Content embed.FS\n",
+			want:   []interface{}{},
+		},
+		{
+			name:   "enabled",
+			lint:   true,
+			source: "package main\n\n//go:embed static\nvar Content embed.FS\n",
+			want:   []interface{}{"embed/exported-fs"},
+		},
+		{
+			name:   "unexported",
+			lint:   true,
+			source: "package main\n\n//go:embed static\nvar content embed.FS\n",
+			want:   []interface{}{},
+		},
+		{
+			name: "suppressed",
+			lint: true,
+			source: "package main\n\n//go:embed static\n" +
+				"var Content embed.FS //embedpls:ignore exported-fs\n",
+			want: []interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, out := newTestHandler()
+			cfg := config.Default()
+			cfg.LintExportedFS = tt.lint
+			handler.setConfig(cfg)
+			err := handler.publishDiagnostics(context.Background(), docURI, tt.source)
+			assert.NoError(t, err)
+			codes := make([]interface{}, 0)
+			for _, diagnostic := range publishedDiagnostics(t, out) {
+				codes = append(codes, diagnostic.(map[string]interface{})["code"])
+			}
+			assert.Equal(t, tt.want, codes)
+		})
+	}
+}
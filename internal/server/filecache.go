@@ -0,0 +1,106 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+)
+
+const (
+	// fileCacheSize bounds the total size of the file contents kept by the
+	// file cache of a handler.
+	fileCacheSize = 8 << 20
+)
+
+// fileCache is a file system keeping the content of the files read from
+// another in a size-bounded cache, evicting the least recently read files
+// first.
+//
+// A cached content is only used while the modification time and size of
+// its file are unchanged, so edited files are read again. It is safe for
+// concurrent use.
+type fileCache struct {
+	resolver.FS
+	// maxSize bounds the total size of the cached contents.
+	maxSize int64
+	mu      sync.Mutex
+	// size is the total size of the cached contents.
+	size int64
+	// entries are the elements of order by path.
+	entries map[string]*list.Element
+	// order holds the cached files, most recently read first.
+	order *list.List
+}
+
+// cachedFile is the content of a file along with what it was read at.
+type cachedFile struct {
+	path    string
+	modTime time.Time
+	data    []byte
+}
+
+// newFileCache returns a cache of the contents of the files of fsys
+// bounded to maxSize bytes.
+func newFileCache(fsys resolver.FS, maxSize int64) *fileCache {
+	return &fileCache{
+		FS:      fsys,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// ReadFile returns the content of the named file, from the cache when the
+// file is unchanged since it was cached.
+func (c *fileCache) ReadFile(name string) ([]byte, error) {
+	info, err := c.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if element, ok := c.entries[name]; ok {
+		cached := element.Value.(*cachedFile)
+		if cached.modTime.Equal(info.ModTime()) && int64(len(cached.data)) == info.Size() {
+			c.order.MoveToFront(element)
+			c.mu.Unlock()
+			return cached.data, nil
+		}
+		c.remove(element)
+	}
+	c.mu.Unlock()
+	data, err := c.FS.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	c.add(&cachedFile{path: name, modTime: info.ModTime(), data: data})
+	return data, nil
+}
+
+// add caches the content of a file, evicting the least recently read
+// files until the cache fits in its bound. Contents larger than the bound
+// are not cached.
+func (c *fileCache) add(file *cachedFile) {
+	size := int64(len(file.data))
+	if size > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[file.path]; ok {
+		c.remove(element)
+	}
+	c.entries[file.path] = c.order.PushFront(file)
+	c.size += size
+	for c.size > c.maxSize {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops a cached file, with the lock of the cache held.
+func (c *fileCache) remove(element *list.Element) {
+	file := c.order.Remove(element).(*cachedFile)
+	delete(c.entries, file.path)
+	c.size -= int64(len(file.data))
+}
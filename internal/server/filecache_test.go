@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
+)
+
+// countingFS is a file system counting the files read from it.
+type countingFS struct {
+	resolver.FS
+	reads map[string]int
+}
+
+// ReadFile counts the read of the named file.
+func (c *countingFS) ReadFile(name string) ([]byte, error) {
+	c.reads[name]++
+	return c.FS.ReadFile(name)
+}
+
+// TestFileCache tests that hovering a file again reads it from the cache
+// until it is modified or evicted by more recently read files.
+func TestFileCache(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"pkg/hello.txt": {Data: []byte("hello"), ModTime: modTime},
+		"pkg/large.txt": {Data: []byte("0123456789"), ModTime: modTime},
+	}
+	counting := &countingFS{FS: resolver.FromFS(fsys), reads: make(map[string]int)}
+	cache := newFileCache(counting, 12)
+	docURI := uri.File("/pkg/main.go")
+	read := func(name, want string) {
+		t.Helper()
+		content, _, err := relativeReadFile(context.Background(), cache, uriToDir(docURI), name, hoverSize)
+		assert.NoError(t, err)
+		assert.Equal(t, want, content)
+	}
+
+	read("hello.txt", "hello")
+	read("hello.txt", "hello")
+	assert.Equal(t, 1, counting.reads["/pkg/hello.txt"], "cache hit")
+
+	fsys["pkg/hello.txt"] = &fstest.MapFile{Data: []byte("hallo"), ModTime: modTime.Add(time.Second)}
+	read("hello.txt", "hallo")
+	assert.Equal(t, 2, counting.reads["/pkg/hello.txt"], "modification time changed")
+
+	read("large.txt", "0123456789")
+	read("hello.txt", "hallo")
+	assert.Equal(t, 3, counting.reads["/pkg/hello.txt"], "evicted by a larger file")
+	read("large.txt", "0123456789")
+	assert.Equal(t, 2, counting.reads["/pkg/large.txt"], "evicted by a more recent file")
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+// handleTextDocumentFoldingRange folds every group of go:embed directives
+// on consecutive lines of the document.
+//
+// A single directive is not folded, as there is nothing to hide.
+func (l *lspHandler) handleTextDocumentFoldingRange(
+	_ context.Context,
+	request lsp.FoldingRangeRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.FoldingRangeResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: []protocol.FoldingRange{},
+	}
+	index, ok := l.documentIndex(request.Params.TextDocument.URI)
+	if !ok {
+		return resp, nil
+	}
+	directives := index.Directives
+	for start := 0; start < len(directives); {
+		end := start
+		for end+1 < len(directives) && directives[end+1].Line == directives[end].Line+1 {
+			end++
+		}
+		if end > start {
+			resp.Result = append(resp.Result, protocol.FoldingRange{
+				StartLine: uint32(directives[start].Line),
+				EndLine:   uint32(directives[end].Line),
+				Kind:      protocol.CommentFoldingRange,
+			})
+		}
+		start = end + 1
+	}
+	return resp, nil
+}
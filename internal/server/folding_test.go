@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestFoldingRange tests that groups of directives on consecutive lines
+// are folded, while single directives are not.
+func TestFoldingRange(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n"+
+		"//go:embed a.txt\n"+
+		"//go:embed b.txt\n"+
+		"//go:embed static/*.html\n"+
+		"//go:embed all:assets\n"+
+		"var files embed.FS\n\n"+
+		"//go:embed c.txt\n"+
+		"var c string\n\n"+
+		"//go:embed d.txt\n\n"+
+		"//go:embed e.txt\n"+
+		"var de embed.FS\n")
+	foldingRanges := func(document uri.URI) []protocol.FoldingRange {
+		resp, err := handler.handleTextDocumentFoldingRange(
+			context.Background(),
+			lsp.FoldingRangeRequest{
+				Params: protocol.FoldingRangeParams{
+					TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: document},
+					},
+				},
+			},
+		)
+		assert.NoError(t, err)
+		return resp.(lsp.FoldingRangeResponse).Result
+	}
+
+	assert.Equal(t, []protocol.FoldingRange{
+		{StartLine: 2, EndLine: 5, Kind: protocol.CommentFoldingRange},
+	}, foldingRanges(docURI))
+	assert.Empty(t, foldingRanges(uri.File("/tmp/unknown.go")))
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+// handleTextDocumentFormatting formats the go:embed directives of the
+// document, leaving the rest of the source to gofmt.
+func (l *lspHandler) handleTextDocumentFormatting(
+	_ context.Context,
+	request lsp.DocumentFormattingRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.DocumentFormattingResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+	}
+	doc, ok := l.documents.Get(request.Params.TextDocument.URI)
+	if !ok {
+		return resp, nil
+	}
+	resp.Result = parsers.FormatDirectives(*doc)
+	return resp, nil
+}
+
+// handleTextDocumentWillSaveWaitUntil respells the "// go:embed" comments
+// of the document as directives before it is saved.
+func (l *lspHandler) handleTextDocumentWillSaveWaitUntil(
+	_ context.Context,
+	request lsp.WillSaveWaitUntilRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.WillSaveWaitUntilResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: make([]protocol.TextEdit, 0),
+	}
+	doc, ok := l.documents.Get(request.Params.TextDocument.URI)
+	if !ok {
+		return resp, nil
+	}
+	for _, misspelled := range parsers.ParseMisspelledDirectives(*doc) {
+		resp.Result = append(resp.Result, misspelled.Fix())
+	}
+	return resp, nil
+}
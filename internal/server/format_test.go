@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestFormatting tests formatting the directives of a document.
+func TestFormatting(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []protocol.TextEdit
+	}{
+		{
+			name:   "space after slashes and unsorted patterns",
+			source: "package main\n\n// go:embed b.txt  a.txt\nvar s embed.FS\n",
+			want: []protocol.TextEdit{{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2},
+					End:   protocol.Position{Line: 2, Character: 24},
+				},
+				NewText: "//go:embed a.txt b.txt",
+			}},
+		},
+		{
+			name:   "already canonical",
+			source: "package main\n\n//go:embed a.txt b.txt\nvar s embed.FS\n",
+			want:   []protocol.TextEdit{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			docURI := uri.File("/tmp/main.go")
+			handler.documents.Set(docURI, tt.source)
+			resp, err := handler.handleTextDocumentFormatting(
+				context.Background(),
+				lsp.DocumentFormattingRequest{
+					Params: protocol.DocumentFormattingParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+					},
+				},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, resp.(lsp.DocumentFormattingResponse).Result)
+		})
+	}
+}
+
+// TestWillSaveWaitUntil tests that misspelled directives are fixed before
+// saving while clean files are left untouched.
+func TestWillSaveWaitUntil(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []protocol.TextEdit
+	}{
+		{
+			name:   "misspelled directive",
+			source: "package main\n\n//  go:embed hello.txt\nvar s string\n",
+			want: []protocol.TextEdit{{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 0},
+					End:   protocol.Position{Line: 2, Character: 12},
+				},
+				NewText: "//go:embed",
+			}},
+		},
+		{
+			name:   "clean file",
+			source: "package main\n\n//go:embed hello.txt\nvar s string\n",
+			want:   []protocol.TextEdit{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			docURI := uri.File("/tmp/main.go")
+			handler.documents.Set(docURI, tt.source)
+			resp, err := handler.handleTextDocumentWillSaveWaitUntil(
+				context.Background(),
+				lsp.WillSaveWaitUntilRequest{
+					Params: protocol.WillSaveTextDocumentParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+						Reason:       protocol.TextDocumentSaveReasonManual,
+					},
+				},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, resp.(lsp.WillSaveWaitUntilResponse).Result)
+		})
+	}
+}
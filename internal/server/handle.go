@@ -3,18 +3,24 @@
 package server
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/conneroisu/embedpls/internal/config"
+	"github.com/conneroisu/embedpls/internal/gitignore"
 	"github.com/conneroisu/embedpls/internal/lsp"
 	"github.com/conneroisu/embedpls/internal/lsp/methods"
 	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
 	"github.com/conneroisu/embedpls/internal/rpc"
+	rpcerrors "github.com/conneroisu/embedpls/internal/rpc/errors"
 	"github.com/conneroisu/embedpls/internal/safe"
 	"go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
@@ -28,25 +34,205 @@ type Handler interface {
 	) (rpc.MethodActor, error)
 }
 
+var (
+	// ErrExit is returned by Handle for the exit notification of a
+	// session whose shutdown was requested, after which the session is
+	// over and the read loop of the server must end.
+	ErrExit = errors.New("exit notification received")
+	// ErrExitWithoutShutdown is returned by Handle for the exit
+	// notification of a session whose shutdown was not requested, which
+	// the protocol asks to end with a failure.
+	ErrExitWithoutShutdown = fmt.Errorf("%w without a shutdown request", ErrExit)
+)
+
+const (
+	// DefaultRequestTimeout is the time given to the server to handle a
+	// message when no other timeout is configured.
+	DefaultRequestTimeout = time.Second
+)
+
+// HandlerOptions configures a handler created by NewLSPHandler.
+type HandlerOptions struct {
+	// Version is the version reported to the client at initialization.
+	Version string
+	// Timeout bounds the time given to handle a message,
+	// DefaultRequestTimeout when not positive.
+	Timeout time.Duration
+	// Root is the directory of the workspace root, overriding the root
+	// sent by the client at initialization when not empty.
+	Root string
+	// FS is the file system the files of the packages are read from,
+	// resolver.OS when nil.
+	FS resolver.FS
+}
+
 // NewLSPHandler creates a new LSPHandler.
-func NewLSPHandler(documents *safe.Map[uri.URI, string]) Handler {
-	return &lspHandler{documents: documents}
+//
+// The writer is used to send notifications, such as diagnostics, to the
+// client outside of the request/response cycle.
+func NewLSPHandler(
+	documents *safe.Map[uri.URI, string],
+	writer *rpc.Writer,
+	opts HandlerOptions,
+) Handler {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultRequestTimeout
+	}
+	if opts.FS == nil {
+		opts.FS = resolver.OS
+	}
+	m := &metrics{}
+	fsys := meteredFS{FS: opts.FS, dirs: &m.dirs}
+	l := &lspHandler{
+		documents:     documents,
+		assets:        safe.NewSafeMap[uri.URI, string](),
+		cancelMap:     safe.NewSafeMap[int, context.CancelFunc](),
+		versions:      safe.NewSafeMap[uri.URI, int32](),
+		progress:      safe.NewSafeMap[int, *progress](),
+		progressDelay: defaultProgressDelay,
+		index:         parsers.NewDocIndex(),
+		writer:        writer,
+		hoverKind:     protocol.Markdown,
+		config:        config.Default(),
+		logger:        log.Default().With(),
+		version:       opts.Version,
+		timeout:       opts.Timeout,
+		rootOverride:  opts.Root,
+		fs:            fsys,
+		gitignore:     gitignore.NewMatcher(fsys),
+		files:         newFileCache(fsys, fileCacheSize),
+		metrics:       m,
+	}
+	l.methods = l.registerMethods()
+	l.replies = l.registerReplies()
+	m.register(l.methods)
+	return l
 }
 
 type lspHandler struct {
+	// methods are the handlers of the supported methods.
+	methods map[methods.Method]methodHandler
+	// replies are the handlers of the replies to the requests sent to the
+	// client, by method of the request.
+	replies   map[methods.Method]methodHandler
 	documents *safe.Map[uri.URI, string]
+	// assets are the opened documents that are not Go files.
+	assets    *safe.Map[uri.URI, string]
 	cancelMap *safe.Map[int, context.CancelFunc]
+	// versions are the last applied versions of the opened documents.
+	versions *safe.Map[uri.URI, int32]
+	// index caches the parsed directives of the opened documents.
+	index  *parsers.DocIndex
+	writer *rpc.Writer
+	// hoverKind is the markup kind of the hover contents supported by the
+	// client.
+	hoverKind protocol.MarkupKind
+	// workDoneProgress is whether the client supports progress initiated
+	// by the server.
+	workDoneProgress bool
+	// configuration is whether the client supports workspace/configuration
+	// requests.
+	configuration bool
+	// watchedFiles is whether the client supports registering file
+	// watchers dynamically.
+	watchedFiles bool
+	// progressTokens counts the progress tokens created by the server.
+	progressTokens atomic.Int32
+	// progress are the progresses whose window/workDoneProgress/create
+	// request awaits a reply, by id of the request.
+	progress *safe.Map[int, *progress]
+	// progressMu orders the recording of a progress before the handling
+	// of the reply to its request.
+	progressMu sync.Mutex
+	// progressDelay is the time an operation runs before its progress is
+	// reported.
+	progressDelay time.Duration
+	// shutdown is whether the client requested the shutdown of the
+	// session.
+	shutdown atomic.Bool
+	// root is the directory of the workspace root, if any.
+	root string
+	// rootOverride is the directory of the workspace root set by the
+	// options of the handler, used instead of the one of the client.
+	rootOverride string
+	// config is the configuration of the workspace, read through
+	// currentConfig and replaced through setConfig.
+	config config.Config
+	// configMu guards config.
+	configMu sync.RWMutex
+	// logger is the logger of the session, whose level the configuration
+	// and the trace value of the client set without affecting the other
+	// sessions.
+	logger *log.Logger
+	// settings are the last settings of the client applied over the
+	// configuration file, applied again when the file changes.
+	settings []byte
+	// version is the version of the server build.
+	version string
+	// timeout bounds the time given to handle a message.
+	timeout time.Duration
+	// fs is the file system the files of the packages are read from.
+	fs resolver.FS
+	// files caches the contents of the files read for hovers.
+	files *fileCache
+	// gitignore matches files against the .gitignore files of their
+	// repository.
+	gitignore *gitignore.Matcher
+	// metrics counts the messages handled.
+	metrics *metrics
 }
 
-// Handle handles a message from the client to the server.
+// Handle handles a message from the client to the server, counting it in
+// the metrics of the session.
+//
+// Invalid messages are rejected before their method is looked at. Methods
+// the server does not support fail with a MethodNotFound error, except for
+// the notifications starting with "$/" which are ignored.
 func (l *lspHandler) Handle(
 	ctx context.Context,
 	msg *rpc.BaseMessage,
 ) (rpc.MethodActor, error) {
-	errCh := make(chan error)
-	resultCh := make(chan rpc.MethodActor)
-	ctx, cancel := context.WithTimeout(ctx, time.Second*1)
+	if err := msg.Validate(); err != nil {
+		l.metrics.rejected.Add(1)
+		return nil, err
+	}
+	if _, ok := l.methods[methods.Method(msg.Method)]; msg.Method != "" && !ok {
+		if msg.IsNotification() && strings.HasPrefix(msg.Method, "$/") {
+			// implementation-dependent notifications may be ignored
+			return nil, nil
+		}
+		l.metrics.unknown.Add(1)
+		return nil, rpcerrors.New(
+			rpcerrors.CodeMethodNotFound,
+			fmt.Sprintf("unknown method: %s", msg.Method),
+		)
+	}
+	result, err := l.handleWithin(ctx, msg)
+	l.metrics.record(msg.Method, err)
+	return result, err
+}
+
+// handleWithin handles a message within the timeout of the handler.
+//
+// A message not handled within the timeout of the handler fails with a
+// RequestFailed error, even when its method handler ignores the
+// cancellation of its context, such as when blocked on a stalled file
+// system. A request is cancellable by its id while it runs, and fails
+// with a RequestCancelled error once cancelled.
+func (l *lspHandler) handleWithin(
+	ctx context.Context,
+	msg *rpc.BaseMessage,
+) (rpc.MethodActor, error) {
+	// buffered so that a handler finishing after the timeout does not
+	// block forever
+	errCh := make(chan error, 1)
+	resultCh := make(chan rpc.MethodActor, 1)
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
 	defer cancel()
+	if msg.Method != "" && !msg.IsNotification() {
+		l.cancelMap.Set(msg.ID, cancel)
+		defer l.cancelMap.Delete(msg.ID)
+	}
 	go func() {
 		result, err := l.handle(ctx, msg)
 		if err == nil {
@@ -57,7 +243,16 @@ func (l *lspHandler) Handle(
 	}()
 	select {
 	case <-ctx.Done():
-		return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, rpcerrors.New(
+				rpcerrors.CodeRequestFailed,
+				fmt.Sprintf("%s timed out after %s", msg.Method, l.timeout),
+			)
+		}
+		return nil, rpcerrors.New(
+			rpcerrors.CodeRequestCancelled,
+			fmt.Sprintf("%s was cancelled", msg.Method),
+		)
 	case err := <-errCh:
 		return nil, err
 	case result := <-resultCh:
@@ -65,169 +260,237 @@ func (l *lspHandler) Handle(
 	}
 }
 
+// handle dispatches a valid message to the handler of its method, which
+// Handle checked is supported, or a reply to the handler of its request.
 func (l *lspHandler) handle(ctx context.Context, msg *rpc.BaseMessage) (rpc.MethodActor, error) {
-	switch methods.Method(msg.Method) {
-	case methods.MethodCancelRequest:
-		request, err := rpc.Decode[lsp.CancelRequest](msg)
-		if err != nil {
-			return nil, err
-		}
-		id, err := lsp.ParseCancelParams(request.Params)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to parse cancel params: %w",
-				err,
-			)
+	if msg.Method == "" {
+		// replies to the requests sent to the client carry no method
+		method, ok := l.writer.Resolve(msg.ID)
+		if !ok {
+			return nil, fmt.Errorf("reply to unknown request: %d", msg.ID)
 		}
-		c, ok := l.cancelMap.Get(id)
-		if ok {
-			(*c)()
-		}
-		return lsp.CancelResponse{
-			RPC: lsp.RPCVersion,
-			ID:  id,
-		}, nil
-
-	case methods.MethodNotificationExit:
-		for _, cancel := range l.cancelMap.Values() {
-			cancel()
-		}
-		os.Exit(0)
-		return nil, nil
-
-	case methods.NotificationTextDocumentDidClose:
-		var request lsp.DidCloseTextDocumentParamsNotification
-		err := json.Unmarshal([]byte(msg.Content), &request)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"decode (%s) failed: %w",
-				msg.Method,
-				err,
-			)
+		if reply, ok := l.replies[method]; ok {
+			return reply(ctx, msg)
 		}
-		l.documents.Delete(request.Params.TextDocument.URI)
-		return nil, nil
-
-	case methods.MethodNotificationInitialized:
 		return nil, nil
+	}
+	return l.methods[methods.Method(msg.Method)](ctx, msg)
+}
 
-	case methods.MethodNotificationTextDocumentWillSave:
-		return nil, nil
+func (l *lspHandler) handleCancelRequest(
+	_ context.Context,
+	request lsp.CancelRequest,
+) (rpc.MethodActor, error) {
+	id, err := lsp.ParseCancelParams(request.Params)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to parse cancel params: %w",
+			err,
+		)
+	}
+	c, ok := l.cancelMap.Get(id)
+	if ok {
+		(*c)()
+	}
+	return nil, nil
+}
 
-	case methods.MethodNotificationTextDocumentDidSave:
-		request, err := rpc.Decode[lsp.DidSaveTextDocumentNotification](msg)
-		if err != nil {
-			return nil, err
-		}
-		read, err := os.ReadFile(request.Params.TextDocument.URI.Filename())
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file: %w", err)
-		}
-		l.documents.Set(request.Params.TextDocument.URI, string(read))
-		return nil, nil
+// handleExit cancels the requests still running and ends the session
+// with ErrExit, or ErrExitWithoutShutdown when no shutdown request came
+// first. Exiting the process, if at all, is up to the caller.
+func (l *lspHandler) handleExit(
+	_ context.Context,
+	_ *rpc.BaseMessage,
+) (rpc.MethodActor, error) {
+	for _, cancel := range l.cancelMap.ValuesByKey(cmp.Less[int]) {
+		cancel()
+	}
+	if !l.shutdown.Load() {
+		return nil, ErrExitWithoutShutdown
+	}
+	return nil, ErrExit
+}
 
-	case methods.MethodShutdown:
-		request, err := rpc.Decode[lsp.ShutdownRequest](msg)
-		if err != nil {
-			return nil, err
-		}
-		for _, cancel := range l.cancelMap.Values() {
+func (l *lspHandler) handleShutdown(
+	_ context.Context,
+	request lsp.ShutdownRequest,
+) (rpc.MethodActor, error) {
+	// the other running requests are cancelled, not the shutdown itself
+	l.cancelMap.ForEach(func(id int, cancel context.CancelFunc) bool {
+		if id != request.ID {
 			cancel()
 		}
-		return lsp.NewShutdownResponse(request, nil)
+		return true
+	})
+	l.shutdown.Store(true)
+	l.logger.Info("shutting down", "stats", l.stats())
+	return lsp.NewShutdownResponse(request), nil
+}
 
-	case methods.NotificationMethodTextDocumentDidChange:
-		var request lsp.TextDocumentDidChangeNotification
-		err := json.Unmarshal(msg.Content, &request)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"decode (%s) failed: %w",
-				msg.Method,
-				err,
-			)
-		}
-		l.documents.Set(request.Params.TextDocument.URI, string(request.Params.ContentChanges[0].Text))
-		return nil, nil
+func (l *lspHandler) handleSetTrace(
+	_ context.Context,
+	request lsp.SetTraceNotification,
+) (rpc.MethodActor, error) {
+	l.setTrace(request.Params.Value)
+	return nil, nil
+}
 
-	case methods.MethodInitialize:
-		request, err := rpc.Decode[lsp.InitializeRequest](msg)
-		if err != nil {
-			return nil, err
-		}
-		return lsp.NewInitializeResponse(&request), nil
+func (l *lspHandler) handleTextDocumentDidOpen(
+	ctx context.Context,
+	request lsp.NotificationDidOpenTextDocument,
+) (rpc.MethodActor, error) {
+	l.versions.Set(request.Params.TextDocument.URI, request.Params.TextDocument.Version)
+	l.index.Invalidate(request.Params.TextDocument.URI)
+	if !isGoFile(request.Params.TextDocument.URI) {
+		l.handleAssetOpen(
+			request.Params.TextDocument.URI,
+			request.Params.TextDocument.Text,
+		)
+		return nil, nil
+	}
+	l.documents.Set(request.Params.TextDocument.URI, string(request.Params.TextDocument.Text))
+	return nil, l.publishDiagnostics(
+		ctx,
+		request.Params.TextDocument.URI,
+		request.Params.TextDocument.Text,
+	)
+}
 
-	case methods.MethodRequestTextDocumentDidOpen:
-		request, err := rpc.Decode[lsp.NotificationDidOpenTextDocument](msg)
-		if err != nil {
-			return nil, err
-		}
-		if !strings.HasSuffix(
-			string(request.Params.TextDocument.URI),
-			".go",
-		) {
-			return nil, nil
+func (l *lspHandler) handleTextDocumentDidChange(
+	ctx context.Context,
+	request lsp.TextDocumentDidChangeNotification,
+) (rpc.MethodActor, error) {
+	document := request.Params.TextDocument.URI
+	texts := l.documents
+	if !isGoFile(document) {
+		texts = l.assets
+	}
+	// the version is checked and the changes applied under the lock of the
+	// text, so that concurrent changes apply in the order of their versions
+	applied := false
+	text := ""
+	texts.Update(document, func(current string, _ bool) string {
+		if !l.applyVersion(document, request.Params.TextDocument.Version) {
+			return current
 		}
-		l.documents.Set(request.Params.TextDocument.URI, string(request.Params.TextDocument.Text))
+		applied = true
+		text = applyContentChanges(current, request.Params.ContentChanges)
+		return text
+	})
+	if !applied || !isGoFile(document) {
 		return nil, nil
+	}
+	l.index.Invalidate(document)
+	return nil, l.publishDiagnostics(ctx, document, text)
+}
 
-	case methods.MethodRequestTextDocumentDefinition:
-		request, err := rpc.Decode[lsp.TextDocumentCompletionRequest](msg)
-		if err != nil {
-			return nil, err
+// applyContentChanges returns the text of a document after the changes of
+// a notification, applied in order.
+//
+// Clients may batch several edits in a single notification, each applying
+// to the text left by the previous ones, so a full change replaces the
+// text and an incremental one edits it.
+func applyContentChanges(text string, changes []lsp.TextDocumentContentChangeEvent) string {
+	for _, change := range changes {
+		if change.Range == nil {
+			text = change.Text
+			continue
 		}
-		ctx, cancel := context.WithTimeout(ctx, time.Second*1)
-		defer cancel()
-		ans, err := l.handleTextDocumentDefinition(
-			ctx,
-			request,
-		)
-		return ans, err
+		text = parsers.ApplyChange(text, *change.Range, change.Text)
+	}
+	return text
+}
 
-	case methods.MethodRequestTextDocumentCompletion:
-		request, err := rpc.Decode[lsp.TextDocumentCompletionRequest](msg)
-		if err != nil {
-			return nil, err
+// applyVersion records the version of a change to a document, reporting
+// false when the change is stale because a change of a greater or equal
+// version was already applied.
+//
+// The check and the record are a single update of the versions, so that
+// concurrent changes of a document cannot both pass the check.
+func (l *lspHandler) applyVersion(document uri.URI, version int32) bool {
+	applied := true
+	var latest int32
+	l.versions.Update(document, func(current int32, ok bool) int32 {
+		if ok && version <= current {
+			applied, latest = false, current
+			return current
 		}
-		ctx, cancel := context.WithTimeout(ctx, time.Second*1)
-		defer cancel()
-		ans, err := l.handleTextDocumentCompletion(
-			ctx,
-			request,
+		return version
+	})
+	if !applied {
+		l.logger.Warnf(
+			"ignoring stale change to %s: version %d, current version %d",
+			document,
+			version,
+			latest,
 		)
-		return ans, err
+	}
+	return applied
+}
 
-	case methods.MethodRequestTextDocumentHover:
-		request, err := rpc.Decode[lsp.HoverRequest](msg)
-		if err != nil {
-			return nil, err
-		}
-		ctx, cancel := context.WithTimeout(ctx, time.Second*1)
-		defer cancel()
-		ans, err := l.handleTextDocumentHover(
-			ctx,
-			request,
-		)
-		return ans, err
+func (l *lspHandler) handleTextDocumentDidSave(
+	ctx context.Context,
+	request lsp.DidSaveTextDocumentNotification,
+) (rpc.MethodActor, error) {
+	read, err := l.fs.ReadFile(uriToPath(request.Params.TextDocument.URI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if !isGoFile(request.Params.TextDocument.URI) {
+		l.handleAssetOpen(request.Params.TextDocument.URI, string(read))
+		return nil, nil
+	}
+	l.documents.Set(request.Params.TextDocument.URI, string(read))
+	l.index.Invalidate(request.Params.TextDocument.URI)
+	return nil, l.publishDiagnostics(
+		ctx,
+		request.Params.TextDocument.URI,
+		string(read),
+	)
+}
+
+func (l *lspHandler) handleTextDocumentDidClose(
+	ctx context.Context,
+	request lsp.DidCloseTextDocumentParamsNotification,
+) (rpc.MethodActor, error) {
+	l.documents.Delete(request.Params.TextDocument.URI)
+	l.assets.Delete(request.Params.TextDocument.URI)
+	l.versions.Delete(request.Params.TextDocument.URI)
+	l.index.Invalidate(request.Params.TextDocument.URI)
+	if !isGoFile(request.Params.TextDocument.URI) {
+		return nil, nil
+	}
+	return nil, l.clearDiagnostics(ctx, request.Params.TextDocument.URI)
+}
 
-	case methods.MethodRequestTextDocumentCodeAction:
-		request, err := rpc.Decode[lsp.TextDocumentCodeActionRequest](msg)
+func (l *lspHandler) handleInitialize(
+	_ context.Context,
+	request lsp.InitializeRequest,
+) (rpc.MethodActor, error) {
+	l.workDoneProgress = request.Params.WorkDoneProgress()
+	l.hoverKind = request.Params.HoverMarkupKind()
+	l.configuration = request.Params.Configuration()
+	l.watchedFiles = request.Params.WatchedFilesRegistration()
+	if request.Params.Trace != "" {
+		l.setTrace(request.Params.Trace)
+	}
+	switch root := request.Params.WorkspaceRoot(); {
+	case l.rootOverride != "":
+		l.root = l.rootOverride
+	case root != "":
+		l.root = uriToPath(root)
+	}
+	if l.root != "" {
+		cfg, err := l.loadConfig(nil)
 		if err != nil {
-			return nil, err
+			l.logger.Warnf("using default config: %v", err)
 		}
-		ctx, cancel := context.WithTimeout(ctx, time.Second*1)
-		defer cancel()
-		ans, err := l.handleTextDocumentCodeAction(
-			ctx,
-			request,
-		)
-		return ans, err
-
-	default:
-		return nil, fmt.Errorf("unknown method: %s", msg.Method)
+		l.setConfig(cfg)
 	}
+	return lsp.NewInitializeResponse(&request, l.version), nil
 }
 
-// TODO: Implement Below This Line
+//
 
 func (l *lspHandler) handleTextDocumentCompletion(
 	ctx context.Context,
@@ -245,33 +508,63 @@ func (l *lspHandler) handleTextDocumentCompletion(
 		return nil, err
 	}
 	if state == parsers.StateUnknown {
-		log.Debugf("unknown state")
-		return nil, nil
+		// some clients wait for a reply to every completion request, so
+		// positions outside of directives and ReadFile calls get an
+		// empty list
+		items, err := l.readFileCompletion(
+			ctx,
+			request.Params.TextDocument.URI,
+			*doc,
+			request.Params.Position,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &lsp.TextDocumentCompletionResponse{
+			Response: lsp.Response{
+				RPC: lsp.RPCVersion,
+				ID:  request.ID,
+			},
+			Result: items,
+		}, nil
+	}
+	// replace the whole pattern under the cursor, as clients may consider
+	// its slashes to be word boundaries
+	var replace *protocol.Range
+	index, _ := l.documentIndex(request.Params.TextDocument.URI)
+	if directive, pattern, ok := index.PatternAt(request.Params.Position); ok &&
+		pattern.Value == curVal {
+		patternRange := directive.Range(pattern)
+		replace = &patternRange
 	}
-	errCh := make(chan error)
+	// the "all:" prefix is no part of the listed paths, so it is stripped
+	// before listing and put back in front of the completed names
+	glob, all := resolver.SplitAllPrefix(curVal)
+	// the listing may fail after the request is given up, so its error
+	// must not block
+	errCh := make(chan error, 1)
 	select {
 	case <-ctx.Done():
 		return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
-	case embeds := <-getEmbbeddables(request.Params.TextDocument.URI, curVal, errCh):
-		resp := &lsp.TextDocumentCompletionResponse{
+	case embeds := <-l.getEmbbeddables(ctx, request.Params.TextDocument.URI, glob, errCh):
+		if isTriggered(request.Params.Context) {
+			embeds.embeddables = segmentEmbeddables(glob, embeds.embeddables)
+		}
+		if all {
+			for i := range embeds.embeddables {
+				embeds.embeddables[i].name = resolver.AllPrefix + embeds.embeddables[i].name
+			}
+		}
+		return &lsp.TextDocumentCompletionResponse{
 			Response: lsp.Response{
 				RPC: lsp.RPCVersion,
 				ID:  request.ID,
 			},
-			Result: []protocol.CompletionItem{},
-		}
-		for _, embed := range embeds.embeddables {
-			resp.Result = append(resp.Result, protocol.CompletionItem{
-				Label:         embed.name,
-				Detail:        embed.name,
-				Documentation: embed.name,
-				Kind:          protocol.CompletionItemKindFile,
-			})
-		}
+			Result: newCompletionItems(curVal, embeds.embeddables, replace),
+		}, nil
 	case err := <-errCh:
 		return nil, err
 	}
-	return nil, nil
 }
 
 //
@@ -285,32 +578,14 @@ func (l *lspHandler) handleTextDocumentHover(
 			RPC: lsp.RPCVersion,
 			ID:  request.ID,
 		}}
-	errCh := make(chan error)
+	errCh := make(chan error, 1)
 	select {
 	case <-ctx.Done():
 		return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
-	case res := <-l.getHoverResp(request, errCh):
+	case res := <-l.getHoverResp(ctx, request, errCh):
 		resp.Result = res
 		return resp, nil
 	case err := <-errCh:
 		return nil, err
 	}
 }
-
-//
-
-func (l *lspHandler) handleTextDocumentDefinition(
-	ctx context.Context,
-	request lsp.TextDocumentCompletionRequest,
-) (rpc.MethodActor, error) {
-	return nil, nil
-}
-
-//
-
-func (l *lspHandler) handleTextDocumentCodeAction(
-	ctx context.Context,
-	request lsp.TextDocumentCodeActionRequest,
-) (rpc.MethodActor, error) {
-	return nil, nil
-}
@@ -0,0 +1,605 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	rpcerrors "github.com/conneroisu/embedpls/internal/rpc/errors"
+	"github.com/conneroisu/embedpls/internal/safe"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// newTestHandler creates a handler writing its notifications to the
+// returned buffer.
+func newTestHandler() (*lspHandler, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	handler := NewLSPHandler(
+		safe.NewSafeMap[uri.URI, string](),
+		rpc.NewWriter(out),
+		HandlerOptions{Version: "v0.0.0-test"},
+	)
+	return handler.(*lspHandler), out
+}
+
+// newTestMessage frames and decodes a message body.
+func newTestMessage(t *testing.T, body string) *rpc.BaseMessage {
+	t.Helper()
+	msg, err := rpc.DecodeMessage([]byte(
+		fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// TestHandleInitializePositionEncoding tests that UTF-16 is announced at
+// initialization whatever encodings the client offers, as every position
+// of the server is counted in UTF-16 code units.
+func TestHandleInitializePositionEncoding(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		want         lsp.PositionEncodingKind
+	}{
+		{
+			name:         "no general capabilities",
+			capabilities: `{}`,
+			want:         lsp.PositionEncodingUTF16,
+		},
+		{
+			name:         "utf-16 only",
+			capabilities: `{"general":{"positionEncodings":["utf-16"]}}`,
+			want:         lsp.PositionEncodingUTF16,
+		},
+		{
+			name:         "utf-8 offered",
+			capabilities: `{"general":{"positionEncodings":["utf-32","utf-16","utf-8"]}}`,
+			want:         lsp.PositionEncodingUTF16,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			resp, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":`+tt.capabilities+`}}`,
+			))
+			assert.NoError(t, err)
+			initialize, ok := resp.(*lsp.InitializeResponse)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.want, initialize.Result.Capabilities.PositionEncoding)
+			}
+			encoded, err := rpc.Encode(context.Background(), resp)
+			assert.NoError(t, err)
+			assert.Contains(t, encoded, `"positionEncoding":"`+string(tt.want)+`"`)
+		})
+	}
+}
+
+// TestHandleDidOpenAsset tests that opened non-Go documents are recorded
+// as assets instead of being discarded.
+func TestHandleDidOpenAsset(t *testing.T) {
+	handler, out := newTestHandler()
+	resp, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///tmp/templates/index.html","languageId":"html","version":1,"text":"<h1>hi</h1>"}}}`,
+	))
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	text, ok := handler.assets.Get(uri.URI("file:///tmp/templates/index.html"))
+	assert.True(t, ok)
+	assert.Equal(t, "<h1>hi</h1>", *text)
+	assert.Equal(t, 0, handler.documents.Len())
+	assert.Empty(t, out.String())
+
+	_, err = handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"file:///tmp/templates/index.html"}}}`,
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, handler.assets.Len())
+}
+
+// TestHandleInitializeConfig tests that the workspace configuration is
+// loaded at initialization and applied when scanning directories.
+func TestHandleInitializeConfig(t *testing.T) {
+	root := writeTestFiles(t, map[string]string{
+		".embedpls.json":        `{"ignore": ["*.tmp", "dist/"]}`,
+		"main.go":               "package main\n",
+		"hello.txt":             "hello",
+		"build.tmp":             "tmp",
+		"dist/index.html":       "<html></html>",
+		"static/dist/index.txt": "nested",
+	})
+	handler, _ := newTestHandler()
+	_, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":"`+
+			string(uri.File(root))+`","capabilities":{}}}`,
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, root, handler.root)
+	assert.Equal(t, []string{"*.tmp", "dist/"}, handler.currentConfig().Ignore)
+
+	errCh := make(chan error, 1)
+	resp := <-handler.getEmbbeddables(context.Background(), uri.File(filepath.Join(root, "main.go")), "", errCh)
+	names := make([]string, 0)
+	for _, embed := range resp.embeddables {
+		names = append(names, embed.name)
+	}
+	assert.ElementsMatch(t, []string{
+		".embedpls.json",
+		"hello.txt",
+		"main.go",
+		"static/",
+	}, names)
+}
+
+// TestHandleInitializeHoverKind tests that the hover markup kind follows
+// the hover content formats of the client.
+func TestHandleInitializeHoverKind(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		want         protocol.MarkupKind
+	}{
+		{
+			name:         "markdown preferred",
+			capabilities: `{"textDocument":{"hover":{"contentFormat":["markdown","plaintext"]}}}`,
+			want:         protocol.Markdown,
+		},
+		{
+			name:         "markdown supported",
+			capabilities: `{"textDocument":{"hover":{"contentFormat":["plaintext","markdown"]}}}`,
+			want:         protocol.Markdown,
+		},
+		{
+			name:         "plaintext only",
+			capabilities: `{"textDocument":{"hover":{"contentFormat":["plaintext"]}}}`,
+			want:         protocol.PlainText,
+		},
+		{
+			name:         "no hover capability",
+			capabilities: `{}`,
+			want:         protocol.PlainText,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			_, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":`+
+					tt.capabilities+`}}`,
+			))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, handler.hoverKind)
+		})
+	}
+}
+
+// TestHandleInitializeVersion tests that the server reports the version
+// it was created with.
+func TestHandleInitializeVersion(t *testing.T) {
+	handler := NewLSPHandler(
+		safe.NewSafeMap[uri.URI, string](),
+		rpc.NewWriter(&bytes.Buffer{}),
+		HandlerOptions{Version: "v1.2.3"},
+	)
+	resp, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`,
+	))
+	assert.NoError(t, err)
+	result := resp.(*lsp.InitializeResponse).Result
+	if assert.NotNil(t, result.ServerInfo) {
+		assert.Equal(t, "embedpls", result.ServerInfo.Name)
+		assert.Equal(t, "v1.2.3", result.ServerInfo.Version)
+	}
+}
+
+// TestHandleDidChangeVersions tests that changes arriving out of order do
+// not overwrite the text of a later version.
+func TestHandleDidChangeVersions(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.URI("file:///tmp/main.go")
+	send := func(body string) {
+		t.Helper()
+		_, err := handler.Handle(context.Background(), newTestMessage(t, body))
+		assert.NoError(t, err)
+	}
+	change := func(version int, text string) string {
+		return fmt.Sprintf(
+			`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"%s","version":%d},"contentChanges":[{"text":%q}]}}`,
+			docURI,
+			version,
+			text,
+		)
+	}
+	send(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"` +
+		string(docURI) + `","languageId":"go","version":1,"text":"package v1\n"}}}`)
+	send(change(3, "package v3\n"))
+	send(change(2, "package v2\n"))
+	send(change(3, "package v3 again\n"))
+
+	text, ok := handler.documents.Get(docURI)
+	assert.True(t, ok)
+	assert.Equal(t, "package v3\n", *text)
+	version, ok := handler.versions.Get(docURI)
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), *version)
+
+	send(change(4, "package v4\n"))
+	text, _ = handler.documents.Get(docURI)
+	assert.Equal(t, "package v4\n", *text)
+
+	send(`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"` +
+		string(docURI) + `"}}}`)
+	assert.Equal(t, 0, handler.versions.Len())
+}
+
+// TestHandleDidChangeConcurrent tests that concurrent changes leave the
+// text of the latest version, whatever order they run in.
+func TestHandleDidChangeConcurrent(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.URI("file:///tmp/main.go")
+	var wg sync.WaitGroup
+	for version := 1; version <= 50; version++ {
+		wg.Add(1)
+		go func(version int) {
+			defer wg.Done()
+			_, err := handler.handleTextDocumentDidChange(context.Background(), lsp.TextDocumentDidChangeNotification{
+				Params: lsp.DidChangeTextDocumentParams{
+					TextDocument: protocol.VersionedTextDocumentIdentifier{
+						TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: docURI},
+						Version:                int32(version),
+					},
+					ContentChanges: []lsp.TextDocumentContentChangeEvent{
+						{Text: fmt.Sprintf("package v%d\n", version)},
+					},
+				},
+			})
+			assert.NoError(t, err)
+		}(version)
+	}
+	wg.Wait()
+	text, ok := handler.documents.Get(docURI)
+	assert.True(t, ok)
+	assert.Equal(t, "package v50\n", *text)
+}
+
+// TestHandleDidChangeBatch tests that every change of a notification is
+// applied, in order, whether it replaces the whole text or a range of it.
+func TestHandleDidChangeBatch(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.URI("file:///tmp/main.go")
+	handler.documents.Set(docURI, "package main\n")
+	_, err := handler.Handle(context.Background(), newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{`+
+			`"textDocument":{"uri":"`+string(docURI)+`","version":2},"contentChanges":[`+
+			`{"text":"package old\n"},`+
+			`{"text":"package main\n\nvar a string\n"},`+
+			`{"range":{"start":{"line":2,"character":4},"end":{"line":2,"character":5}},"text":"b"},`+
+			`{"range":{"start":{"line":2,"character":0},"end":{"line":2,"character":0}},"text":"//go:embed b.txt\n"}`+
+			`]}}`,
+	))
+	assert.NoError(t, err)
+	text, ok := handler.documents.Get(docURI)
+	assert.True(t, ok)
+	assert.Equal(t, "package main\n\n//go:embed b.txt\nvar b string\n", *text)
+}
+
+// TestHandleReply tests that replies to server requests resolve them.
+func TestHandleReply(t *testing.T) {
+	handler, _ := newTestHandler()
+	id, err := handler.writer.WriteRequest(
+		context.Background(),
+		methods.MethodWindowWorkDoneProgressCreate,
+		nil,
+	)
+	assert.NoError(t, err)
+	reply := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":null}`, id)
+	resp, err := handler.Handle(context.Background(), newTestMessage(t, reply))
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	_, err = handler.Handle(context.Background(), newTestMessage(t, reply))
+	assert.Error(t, err, "a reply is only expected once")
+}
+
+// TestHandleIgnorableNotifications tests that the standard notifications
+// the server has no use for are ignored while unknown methods still error.
+func TestHandleIgnorableNotifications(t *testing.T) {
+	tests := []struct {
+		method  methods.Method
+		wantErr bool
+	}{
+		{method: methods.MethodTelemetryEvent},
+		{method: methods.MethodWindowWorkDoneProgressCancel},
+		{method: methods.MethodWorkspaceDidChangeConfiguration},
+		{method: methods.MethodWorkspaceDidChangeWorkspaceFolders},
+		{method: methods.MethodWorkspaceDidCreateFiles},
+		{method: methods.MethodWorkspaceDidRenameFiles},
+		{method: methods.MethodWorkspaceDidDeleteFiles},
+		{method: "custom/unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			handler, out := newTestHandler()
+			resp, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","method":"`+string(tt.method)+`","params":{}}`,
+			))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Handle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Nil(t, resp)
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+// TestHandleInvalidVersion tests that messages of another jsonrpc version
+// are rejected before being processed.
+func TestHandleInvalidVersion(t *testing.T) {
+	handler, _ := newTestHandler()
+	_, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"1.0","id":1,"method":"initialize","params":{"rootUri":"file:///tmp","capabilities":{}}}`,
+	))
+	var rpcErr *rpcerrors.Error
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, rpcerrors.CodeInvalidRequest, rpcErr.Code)
+	}
+	assert.Empty(t, handler.root)
+}
+
+// TestHandleTimeout tests that a message whose handler outlasts the
+// timeout fails with a RequestFailed error without waiting for it.
+func TestHandleTimeout(t *testing.T) {
+	handler := NewLSPHandler(
+		safe.NewSafeMap[uri.URI, string](),
+		rpc.NewWriter(&bytes.Buffer{}),
+		HandlerOptions{Version: "v0.0.0-test", Timeout: 10 * time.Millisecond},
+	).(*lspHandler)
+	release := make(chan struct{})
+	defer close(release)
+	handler.methods["test/slow"] = func(context.Context, *rpc.BaseMessage) (rpc.MethodActor, error) {
+		// ignores the cancellation, as a read of a stalled file system
+		<-release
+		return nil, nil
+	}
+	start := time.Now()
+	_, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","id":1,"method":"test/slow","params":{}}`,
+	))
+	assert.Less(t, time.Since(start), time.Second)
+	var rpcErr *rpcerrors.Error
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, rpcerrors.CodeRequestFailed, rpcErr.Code)
+		assert.Equal(t, "test/slow timed out after 10ms", rpcErr.Message)
+	}
+}
+
+// TestHandleCancelRequest tests that a $/cancelRequest notification
+// cancels the context of the running request with its id, which then
+// fails with a RequestCancelled error, and that finished requests are no
+// longer cancellable.
+func TestHandleCancelRequest(t *testing.T) {
+	handler := NewLSPHandler(
+		safe.NewSafeMap[uri.URI, string](),
+		rpc.NewWriter(&bytes.Buffer{}),
+		HandlerOptions{Version: "v0.0.0-test", Timeout: time.Minute},
+	).(*lspHandler)
+	stopped := make(chan struct{})
+	handler.methods["test/slow"] = func(ctx context.Context, _ *rpc.BaseMessage) (rpc.MethodActor, error) {
+		<-ctx.Done()
+		close(stopped)
+		return nil, ctx.Err()
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := handler.Handle(context.Background(), newTestMessage(
+			t,
+			`{"jsonrpc":"2.0","id":7,"method":"test/slow","params":{}}`,
+		))
+		errCh <- err
+	}()
+	assert.Eventually(t, func() bool {
+		_, ok := handler.cancelMap.Get(7)
+		return ok
+	}, time.Second, time.Millisecond, "a running request is registered")
+
+	_, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":7}}`,
+	))
+	assert.NoError(t, err)
+	select {
+	case err := <-errCh:
+		var rpcErr *rpcerrors.Error
+		if assert.ErrorAs(t, err, &rpcErr) {
+			assert.Equal(t, rpcerrors.CodeRequestCancelled, rpcErr.Code)
+			assert.Equal(t, "test/slow was cancelled", rpcErr.Message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the cancelled request is still running")
+	}
+	<-stopped
+	assert.Equal(t, 0, handler.cancelMap.Len(), "finished requests are unregistered")
+}
+
+// slowFS is a file system taking delay to list a directory, as a stalled
+// network mount does.
+type slowFS struct {
+	resolver.FS
+	delay time.Duration
+}
+
+// ReadDir lists the named directory after the delay of the file system.
+func (s slowFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	time.Sleep(s.delay)
+	return s.FS.ReadDir(name)
+}
+
+// TestHandleTimeoutRegistered tests that the timeout of the handler, and
+// no other, bounds the registered methods reading the file system.
+func TestHandleTimeoutRegistered(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		delay   time.Duration
+		wantErr string
+	}{
+		{
+			name:    "timed out",
+			timeout: 10 * time.Millisecond,
+			delay:   500 * time.Millisecond,
+			wantErr: "textDocument/completion timed out after 10ms",
+		},
+		{
+			name:    "longer than a second",
+			timeout: 5 * time.Second,
+			delay:   1100 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewLSPHandler(
+				safe.NewSafeMap[uri.URI, string](),
+				rpc.NewWriter(&bytes.Buffer{}),
+				HandlerOptions{
+					Version: "v0.0.0-test",
+					Timeout: tt.timeout,
+					FS: slowFS{
+						FS: resolver.FromFS(fstest.MapFS{
+							"pkg/static.txt": {Data: []byte("static")},
+						}),
+						delay: tt.delay,
+					},
+				},
+			).(*lspHandler)
+			handler.documents.Set(
+				uri.URI("file:///pkg/main.go"),
+				"package main\n\n//go:embed s\nvar f string\n",
+			)
+			resp, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","id":1,"method":"textDocument/completion","params":{`+
+					`"textDocument":{"uri":"file:///pkg/main.go"},"position":{"line":2,"character":12}}}`,
+			))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, resp)
+				return
+			}
+			var rpcErr *rpcerrors.Error
+			if assert.ErrorAs(t, err, &rpcErr) {
+				assert.Equal(t, rpcerrors.CodeRequestFailed, rpcErr.Code)
+				assert.Equal(t, tt.wantErr, rpcErr.Message)
+			}
+		})
+	}
+}
+
+// TestHandleExit tests that the exit notification cancels the running
+// requests and ends the session with an error telling whether the
+// shutdown was requested first.
+func TestHandleExit(t *testing.T) {
+	for _, shutdown := range []bool{false, true} {
+		t.Run(fmt.Sprintf("shutdown %v", shutdown), func(t *testing.T) {
+			handler, _ := newTestHandler()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			handler.cancelMap.Set(4, cancel)
+			if shutdown {
+				_, err := handler.Handle(context.Background(), newTestMessage(t,
+					`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+				))
+				assert.NoError(t, err)
+			}
+			_, err := handler.Handle(context.Background(), newTestMessage(t,
+				`{"jsonrpc":"2.0","method":"exit"}`,
+			))
+			assert.ErrorIs(t, err, ErrExit)
+			assert.Equal(t, !shutdown, errors.Is(err, ErrExitWithoutShutdown))
+			assert.Error(t, ctx.Err(), "running requests are cancelled")
+		})
+	}
+}
+
+// TestSavedUnopenedDocument tests that a Go document known from a save,
+// without being opened, has no version but can still be queried.
+func TestSavedUnopenedDocument(t *testing.T) {
+	source := "package main\n\n//go:embed hello.txt\nvar hello string\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":   source,
+		"hello.txt": "hello",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler, _ := newTestHandler()
+	_, err := handler.Handle(context.Background(), newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"`+
+			string(docURI)+`"}}}`,
+	))
+	assert.NoError(t, err)
+	_, ok := handler.versions.Get(docURI)
+	assert.False(t, ok)
+
+	index, ok := handler.documentIndex(docURI)
+	if assert.True(t, ok) {
+		assert.Len(t, index.Directives, 1)
+	}
+	actions := codeActions(t, handler, docURI, protocol.Range{
+		Start: protocol.Position{Line: 2},
+		End:   protocol.Position{Line: 2, Character: 3},
+	})
+	assert.NotNil(t, actions)
+}
+
+// TestRootOverride tests that the root of the options of the handler is
+// used as the workspace root, whether the client sends one or not.
+func TestRootOverride(t *testing.T) {
+	root := writeTestFiles(t, map[string]string{
+		"go.mod":         "module example.com/mod\n",
+		".embedpls.yaml": "ignore: [\"*.tmp\"]\n",
+	})
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{name: "no root", params: `{"capabilities":{}}`},
+		{name: "other root", params: `{"rootUri":"file:///tmp","capabilities":{}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewLSPHandler(
+				safe.NewSafeMap[uri.URI, string](),
+				rpc.NewWriter(&bytes.Buffer{}),
+				HandlerOptions{Root: root},
+			).(*lspHandler)
+			_, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":`+tt.params+`}`,
+			))
+			assert.NoError(t, err)
+			assert.Equal(t, root, handler.root)
+			assert.Equal(t, []string{"*.tmp"}, handler.currentConfig().Ignore)
+		})
+	}
+}
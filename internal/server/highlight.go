@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+// handleTextDocumentDocumentHighlight highlights every occurrence, across
+// the directives of the document, of the pattern under the cursor.
+func (l *lspHandler) handleTextDocumentDocumentHighlight(
+	_ context.Context,
+	request lsp.DocumentHighlightRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.DocumentHighlightResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+	}
+	index, ok := l.documentIndex(request.Params.TextDocument.URI)
+	if !ok {
+		return resp, nil
+	}
+	_, current, ok := index.PatternAt(request.Params.Position)
+	if !ok {
+		return resp, nil
+	}
+	for _, directive := range index.Directives {
+		for _, pattern := range directive.Patterns {
+			if pattern.Value != current.Value {
+				continue
+			}
+			resp.Result = append(resp.Result, protocol.DocumentHighlight{
+				Range: directive.Range(pattern),
+				Kind:  protocol.DocumentHighlightKindText,
+			})
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestDocumentHighlight tests that every occurrence of the pattern under
+// the cursor is highlighted.
+func TestDocumentHighlight(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n"+
+		"//go:embed a.txt b.txt\n"+
+		"var ab embed.FS\n\n"+
+		"//go:embed b.txt\n"+
+		"var b string\n")
+	highlight := func(position protocol.Position) []protocol.DocumentHighlight {
+		resp, err := handler.handleTextDocumentDocumentHighlight(
+			context.Background(),
+			lsp.DocumentHighlightRequest{
+				Params: protocol.DocumentHighlightParams{
+					TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+						Position:     position,
+					},
+				},
+			},
+		)
+		assert.NoError(t, err)
+		return resp.(lsp.DocumentHighlightResponse).Result
+	}
+
+	highlights := highlight(protocol.Position{Line: 2, Character: 19})
+	assert.Equal(t, []protocol.DocumentHighlight{
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 2, Character: 17},
+				End:   protocol.Position{Line: 2, Character: 22},
+			},
+			Kind: protocol.DocumentHighlightKindText,
+		},
+		{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 5, Character: 11},
+				End:   protocol.Position{Line: 5, Character: 16},
+			},
+			Kind: protocol.DocumentHighlightKindText,
+		},
+	}, highlights)
+
+	assert.Len(t, highlight(protocol.Position{Line: 2, Character: 12}), 1)
+	assert.Empty(t, highlight(protocol.Position{Line: 3, Character: 2}))
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// embedBlockHover returns the hover of an embedding variable,
+// listing the patterns feeding it, the build constraint of its file if
+// any, the tree of the files it embeds and their total size, which is what
+// its directives add to the binary.
+//
+// Resolving the patterns of large directories can take a while, so the
+// progress is reported to clients supporting it.
+func (l *lspHandler) embedBlockHover(
+	ctx context.Context,
+	docURI uri.URI,
+	block parsers.EmbedBlock,
+) protocol.MarkupContent {
+	patterns := block.Patterns()
+	quoted := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		quoted = append(quoted, l.inlineCode(pattern))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s embeds %s\n\n",
+		l.inlineCode(block.Var+" "+block.Type),
+		strings.Join(quoted, ", "),
+	)
+	if source, ok := l.documents.Get(docURI); ok {
+		if build, ok := parsers.ParseBuildConstraint(*source); ok {
+			fmt.Fprintf(&b, "%s\n\n", build.Note())
+		}
+	}
+	p := l.beginProgress(ctx, "Resolving "+block.Var)
+	files, err := resolver.ResolveProgress(
+		ctx,
+		l.fs,
+		l.embedDir(docURI),
+		patterns,
+		func(pattern string, done, total int) {
+			p.report(ctx, pattern, uint32(done*100/total))
+		},
+	)
+	p.end(ctx, fmt.Sprintf("%d file(s)", len(files)))
+	if err != nil {
+		fmt.Fprintf(&b, "%s\n", err)
+		return l.markup(b.String())
+	}
+	b.WriteString(l.codeBlock("text", fileTree(files)))
+	if total, err := l.totalSize(l.embedDir(docURI), files); err == nil {
+		if block.IsFS() {
+			fmt.Fprintf(&b, "\n%d file(s), %s in total\n", len(files), formatSize(total))
+		} else {
+			fmt.Fprintf(&b, "\n%s in total\n", formatSize(total))
+		}
+	}
+	return l.markup(b.String())
+}
+
+// totalSize returns the total size of the files of a package directory,
+// named by their slash-separated path relative to it.
+func (l *lspHandler) totalSize(dir string, files []string) (int64, error) {
+	var total int64
+	for _, file := range files {
+		info, err := l.fs.Stat(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+const (
+	// maxVirtualPaths bounds the number of files whose virtual path is
+	// shown on the hover of a pattern.
+	maxVirtualPaths = 10
+)
+
+// virtualPathHover returns the lines telling how the files embedded by the
+// pattern under a position are read back from their embed.FS variable, or
+// an empty string when the pattern embeds into another type of variable
+// or matches no file.
+//
+// The virtual path of an embedded file is its slash-separated path
+// relative to the package directory, as resolved by the go command, even
+// when the pattern names a directory holding the file.
+func (l *lspHandler) virtualPathHover(
+	ctx context.Context,
+	docURI uri.URI,
+	index *parsers.Index,
+	position protocol.Position,
+) string {
+	directive, pattern, ok := index.PatternAt(position)
+	if !ok {
+		return ""
+	}
+	block, ok := index.EmbedBlockOf(directive)
+	if !ok || !block.IsFS() {
+		return ""
+	}
+	files, err := resolver.ResolvePattern(ctx, l.fs, l.embedDir(docURI), pattern.Value)
+	if err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(files))
+	for i, file := range files {
+		if i == maxVirtualPaths {
+			lines = append(lines, fmt.Sprintf("and %d more file(s)", len(files)-i))
+			break
+		}
+		lines = append(lines, "accessible as: "+l.inlineCode(
+			fmt.Sprintf("fs.ReadFile(%s, %s)", block.Var, strconv.Quote(file)),
+		))
+	}
+	separator := "\n"
+	if l.hoverKind == protocol.Markdown {
+		// markdown joins the lines of a paragraph
+		separator = "\n\n"
+	}
+	return strings.Join(lines, separator) + "\n"
+}
+
+// directoryHover returns the tree of the files embedded by a pattern naming
+// a directory, which embeds them recursively, reporting false when the
+// pattern names no directory.
+func (l *lspHandler) directoryHover(
+	ctx context.Context,
+	docURI uri.URI,
+	pattern string,
+) (string, bool) {
+	dir := l.embedDir(docURI)
+	glob, _ := resolver.SplitAllPrefix(pattern)
+	if !isPackagePath(glob) || !parsers.IsLiteralPattern(glob) {
+		return "", false
+	}
+	info, err := l.fs.Stat(filepath.Join(dir, filepath.FromSlash(glob)))
+	if err != nil || !info.IsDir() {
+		return "", false
+	}
+	files, err := resolver.ResolvePattern(ctx, l.fs, dir, pattern)
+	if err != nil {
+		return err.Error() + "\n", true
+	}
+	return l.codeBlock("text", fileTree(files)), true
+}
+
+// markup wraps hover contents formatted for the client.
+func (l *lspHandler) markup(value string) protocol.MarkupContent {
+	return protocol.MarkupContent{Kind: l.hoverKind, Value: value}
+}
+
+// inlineCode formats code within a line of hover contents, using a code
+// span only when the client renders markdown.
+func (l *lspHandler) inlineCode(code string) string {
+	if l.hoverKind != protocol.Markdown {
+		return code
+	}
+	return "`" + code + "`"
+}
+
+// codeBlock formats a block of code of the given language, using a fenced
+// code block only when the client renders markdown.
+//
+// The fence is made longer than any run of backticks in the code so the
+// code cannot close it early.
+func (l *lspHandler) codeBlock(lang, code string) string {
+	if code != "" && !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	if l.hoverKind != protocol.Markdown {
+		return code
+	}
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + code + fence + "\n"
+}
+
+// fileTree renders sorted slash-separated file names as an indented tree,
+// one file or directory per line with directories ending in a slash.
+func fileTree(files []string) string {
+	var b strings.Builder
+	previous := []string{}
+	for _, file := range files {
+		elements := strings.Split(file, "/")
+		common := 0
+		for common < len(previous)-1 && common < len(elements)-1 &&
+			previous[common] == elements[common] {
+			common++
+		}
+		for i := common; i < len(elements); i++ {
+			b.WriteString(strings.Repeat("  ", i))
+			b.WriteString(elements[i])
+			if i < len(elements)-1 {
+				b.WriteString("/")
+			}
+			b.WriteString("\n")
+		}
+		previous = elements
+	}
+	return b.String()
+}
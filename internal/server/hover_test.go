@@ -0,0 +1,343 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestHoverEmbedVar tests that hovering an embedding variable shows its
+// patterns, the tree of the files it embeds and their total size in the
+// markup kind of the client.
+func TestHoverEmbedVar(t *testing.T) {
+	source := "package main\n\n" +
+		"import \"embed\"\n\n" +
+		"//go:embed static/*.js\n" +
+		"//go:embed templates hello.txt\n" +
+		"var content embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":                     source,
+		"hello.txt":                   "hello",
+		"static/app.js":               "app",
+		"static/style.css":            "style",
+		"templates/index.html":        "index",
+		"templates/partials/nav.html": "nav",
+	})
+	tree := "hello.txt\n" +
+		"static/\n" +
+		"  app.js\n" +
+		"templates/\n" +
+		"  index.html\n" +
+		"  partials/\n" +
+		"    nav.html\n"
+	tests := []struct {
+		name string
+		kind protocol.MarkupKind
+		want string
+	}{
+		{
+			name: "markdown",
+			kind: protocol.Markdown,
+			want: "`content embed.FS` embeds `static/*.js`, `templates`, `hello.txt`\n\n" +
+				"```text\n" + tree + "```\n" +
+				"\n4 file(s), 16 B in total\n",
+		},
+		{
+			name: "plaintext",
+			kind: protocol.PlainText,
+			want: "content embed.FS embeds static/*.js, templates, hello.txt\n\n" + tree +
+				"\n4 file(s), 16 B in total\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			handler.hoverKind = tt.kind
+			docURI := uri.File(filepath.Join(dir, "main.go"))
+			handler.documents.Set(docURI, source)
+			resp, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+				Params: protocol.HoverParams{
+					TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+						Position:     protocol.Position{Line: 6, Character: 6},
+					},
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, protocol.MarkupContent{Kind: tt.kind, Value: tt.want},
+				resp.(lsp.HoverResponse).Result.Contents)
+		})
+	}
+}
+
+// TestHoverEmbedBytesSize tests that hovering a []byte variable embedding a
+// single file shows the size of the file.
+func TestHoverEmbedBytesSize(t *testing.T) {
+	source := "package main\n\n" +
+		"import _ \"embed\"\n\n" +
+		"//go:embed logo.png\n" +
+		"var logo []byte\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":  source,
+		"logo.png": strings.Repeat("x", 1536),
+	})
+	handler, _ := newTestHandler()
+	handler.hoverKind = protocol.PlainText
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, source)
+	resp, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+		Params: protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Position:     protocol.Position{Line: 5, Character: 5},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"logo []byte embeds logo.png\n\nlogo.png\n\n1.5 KiB in total\n",
+		resp.(lsp.HoverResponse).Result.Contents.Value,
+	)
+}
+
+// TestHoverBuildConstraint tests that hovering an embedding variable of a
+// file with a build constraint notes it.
+func TestHoverBuildConstraint(t *testing.T) {
+	source := "//go:build linux && !cgo\n\n" +
+		"package main\n\n" +
+		"import _ \"embed\"\n\n" +
+		"//go:embed hello.txt\n" +
+		"var hello string\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":   source,
+		"hello.txt": "hello",
+	})
+	handler, _ := newTestHandler()
+	handler.hoverKind = protocol.PlainText
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, source)
+	resp, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+		Params: protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Position:     protocol.Position{Line: 7, Character: 5},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"hello string embeds hello.txt\n\n"+
+			"gated by //go:build linux && !cgo\n\n"+
+			"hello.txt\n\n5 B in total\n",
+		resp.(lsp.HoverResponse).Result.Contents.Value,
+	)
+}
+
+// TestCodeBlock tests formatting code blocks for each markup kind.
+func TestCodeBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		kind protocol.MarkupKind
+		lang string
+		code string
+		want string
+	}{
+		{"markdown", protocol.Markdown, "txt", "hello", "```txt\nhello\n```\n"},
+		{"markdown fence in code", protocol.Markdown, "md", "```go\n```\n", "````md\n```go\n```\n````\n"},
+		{"plaintext", protocol.PlainText, "txt", "hello", "hello\n"},
+		{"plaintext empty", protocol.PlainText, "txt", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &lspHandler{hoverKind: tt.kind}
+			assert.Equal(t, tt.want, handler.codeBlock(tt.lang, tt.code))
+		})
+	}
+}
+
+// TestFileTree tests rendering file names as a tree.
+func TestFileTree(t *testing.T) {
+	assert.Equal(t,
+		"a/\n  b/\n    c.txt\n  d.txt\ne/\n  f/\n    g.txt\nh.txt\n",
+		fileTree([]string{"a/b/c.txt", "a/d.txt", "e/f/g.txt", "h.txt"}),
+	)
+	assert.Empty(t, fileTree(nil))
+}
+
+// TestHoverParsesOnce tests that repeated hovers of an unchanged document
+// parse it once, and that a change parses it again.
+func TestHoverParsesOnce(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	_, err := handler.handleTextDocumentDidOpen(context.Background(), lsp.NotificationDidOpenTextDocument{
+		Params: protocol.DidOpenTextDocumentParams{
+			TextDocument: protocol.TextDocumentItem{
+				URI:     docURI,
+				Version: 1,
+				Text:    "package main\n\n//go:embed missing.txt\nvar s string\n",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	hover := func() {
+		_, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+			Params: protocol.HoverParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+					Position:     protocol.Position{Line: 0, Character: 2},
+				},
+			},
+		})
+		assert.NoError(t, err)
+	}
+	for i := 0; i < 5; i++ {
+		hover()
+	}
+	assert.Equal(t, 1, handler.index.Parses())
+
+	_, err = handler.handleTextDocumentDidChange(context.Background(), lsp.TextDocumentDidChangeNotification{
+		Params: lsp.DidChangeTextDocumentParams{
+			TextDocument: protocol.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: docURI},
+				Version:                2,
+			},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{
+				{Text: "package main\n"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	hover()
+	hover()
+	assert.Equal(t, 2, handler.index.Parses())
+}
+
+// TestHoverVirtualPath tests that hovering a pattern embedding into an
+// embed.FS shows the paths its files are read back by, which are relative
+// to the package directory even for a file of a nested directory, and the
+// tree of the files embedded by a directory.
+func TestHoverVirtualPath(t *testing.T) {
+	source := "package main\n\n" +
+		"import \"embed\"\n\n" +
+		"//go:embed static/css/style.css\n" +
+		"var assets embed.FS\n\n" +
+		"//go:embed static\n" +
+		"var tree embed.FS\n\n" +
+		"//go:embed static/css/style.css\n" +
+		"var style string\n\n" +
+		"//go:embed all:static\n" +
+		"var everything embed.FS\n\n" +
+		"//go:embed all:static/css/style.css\n" +
+		"var allStyle string\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":              source,
+		"static/css/style.css": "body {}",
+		"static/.hidden":       "secret",
+	})
+	tests := []struct {
+		name string
+		line uint32
+		want string
+	}{
+		{
+			name: "nested file",
+			line: 4,
+			want: "```css\nbody {}\n```\n\n" +
+				"accessible as: `fs.ReadFile(assets, \"static/css/style.css\")`\n",
+		},
+		{
+			name: "directory",
+			line: 7,
+			want: "```text\nstatic/\n  css/\n    style.css\n```\n\n" +
+				"accessible as: `fs.ReadFile(tree, \"static/css/style.css\")`\n",
+		},
+		{
+			name: "string variable",
+			line: 10,
+			want: "```css\nbody {}\n```\n",
+		},
+		{
+			name: "all prefix directory",
+			line: 13,
+			want: "```text\nstatic/\n  .hidden\n  css/\n    style.css\n```\n\n" +
+				"accessible as: `fs.ReadFile(everything, \"static/.hidden\")`\n\n" +
+				"accessible as: `fs.ReadFile(everything, \"static/css/style.css\")`\n",
+		},
+		{
+			name: "all prefix file",
+			line: 16,
+			want: "```css\nbody {}\n```\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			docURI := uri.File(filepath.Join(dir, "main.go"))
+			handler.documents.Set(docURI, source)
+			resp, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+				Params: protocol.HoverParams{
+					TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+						Position:     protocol.Position{Line: tt.line, Character: 14},
+					},
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, resp.(lsp.HoverResponse).Result.Contents.Value)
+		})
+	}
+}
+
+// TestHoverNull tests that a position away from any pattern is answered
+// with a null hover rather than left without a result.
+func TestHoverNull(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\nfunc main() {}\n")
+	resp, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+		Params: protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Position:     protocol.Position{Line: 2, Character: 6},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.IsType(t, lsp.HoverResponse{}, resp) {
+		assert.Nil(t, resp.(lsp.HoverResponse).Result)
+	}
+}
+
+// TestHoverLargeFile tests that hovering a file larger than the hover
+// size shows its first lines only, with the size of the whole file.
+func TestHoverLargeFile(t *testing.T) {
+	source := "package main\n\n//go:embed large.txt\nvar s string\n"
+	large := strings.Repeat("line\n", hoverSize/5+10)
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":   source,
+		"large.txt": large,
+	})
+	handler, _ := newTestHandler()
+	handler.hoverKind = protocol.PlainText
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, source)
+	resp, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+		Params: protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Position:     protocol.Position{Line: 2, Character: 14},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	// the partial last line is dropped
+	want := handler.codeBlock("txt", large[:hoverSize/5*5]) + "\n… 64.0 KiB in total\n"
+	got := resp.(lsp.HoverResponse).Result.Contents.Value
+	assert.True(t, got == want, "hover of %d bytes ending in %q", len(got), got[max(0, len(got)-40):])
+}
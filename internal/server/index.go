@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"go.lsp.dev/uri"
+)
+
+// documentIndex returns the index of the directives of an opened Go
+// document, parsing it only when it changed since the last query.
+//
+// Documents known from a save without being opened have no version, and
+// are cached as version 0; the cache compares their text anyway.
+func (l *lspHandler) documentIndex(document uri.URI) (*parsers.Index, bool) {
+	doc, ok := l.documents.Get(document)
+	if !ok {
+		return nil, false
+	}
+	return l.sourceIndex(document, *doc), true
+}
+
+// sourceIndex returns the index of a text of a document, parsing it only
+// when the cache holds no index of this text at the current version of
+// the document.
+func (l *lspHandler) sourceIndex(document uri.URI, source string) *parsers.Index {
+	var version int32
+	if v, ok := l.versions.Get(document); ok {
+		version = *v
+	}
+	return l.index.Get(document, version, source)
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/conneroisu/embedpls/internal/rpc"
+)
+
+// handleTextDocumentInlayHint shows after each pattern of the requested
+// range matching several files, such as a glob or a directory, the number
+// of files it matches.
+//
+// Patterns naming a single file get no hint, as would patterns failing to
+// resolve, which are already reported by the diagnostics.
+func (l *lspHandler) handleTextDocumentInlayHint(
+	ctx context.Context,
+	request lsp.InlayHintRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.InlayHintResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: []lsp.InlayHint{},
+	}
+	document := request.Params.TextDocument.URI
+	index, ok := l.documentIndex(document)
+	if !ok {
+		return resp, nil
+	}
+	visible := request.Params.Range
+	for _, directive := range index.Directives {
+		line := uint32(directive.Line)
+		if line < visible.Start.Line || line > visible.End.Line {
+			continue
+		}
+		for _, pattern := range directive.Patterns {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			files, err := resolver.ResolvePattern(ctx, l.fs, uriToDir(document), pattern.Value)
+			if err != nil || namesFile(pattern, files) {
+				continue
+			}
+			resp.Result = append(resp.Result, lsp.InlayHint{
+				Position:    pattern.Range.End,
+				Label:       matchCountLabel(len(files)),
+				PaddingLeft: true,
+			})
+		}
+	}
+	return resp, nil
+}
+
+// namesFile reports whether a pattern names the single file it resolved
+// to, rather than matching it.
+func namesFile(pattern parsers.PatternToken, files []string) bool {
+	return parsers.IsLiteralPattern(pattern.Glob) &&
+		len(files) == 1 &&
+		files[0] == pattern.Glob
+}
+
+// matchCountLabel returns the label of the hint of a pattern matching the
+// given number of files.
+func matchCountLabel(count int) string {
+	if count == 1 {
+		return "(1 file)"
+	}
+	return fmt.Sprintf("(%d files)", count)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestInlayHint tests that the patterns matching several files are hinted
+// with their number of matches, while literal file names are not.
+func TestInlayHint(t *testing.T) {
+	source := "package main\n\n" +
+		"//go:embed hello.txt static/*.js \"static\" docs\n" +
+		"var files embed.FS\n\n" +
+		"//go:embed static/*.css\n" +
+		"var css embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":          source,
+		"hello.txt":        "hello",
+		"static/app.js":    "app",
+		"static/lib.js":    "lib",
+		"static/style.css": "body {}",
+		"docs/index.md":    "# docs",
+	})
+	handler, _ := newTestHandler()
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, source)
+	hints := func(visible protocol.Range) []lsp.InlayHint {
+		resp, err := handler.handleTextDocumentInlayHint(
+			context.Background(),
+			lsp.InlayHintRequest{
+				Params: lsp.InlayHintParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+					Range:        visible,
+				},
+			},
+		)
+		assert.NoError(t, err)
+		return resp.(lsp.InlayHintResponse).Result
+	}
+
+	assert.Equal(t, []lsp.InlayHint{
+		{Position: protocol.Position{Line: 2, Character: 32}, Label: "(2 files)", PaddingLeft: true},
+		{Position: protocol.Position{Line: 2, Character: 41}, Label: "(3 files)", PaddingLeft: true},
+		{Position: protocol.Position{Line: 2, Character: 46}, Label: "(1 file)", PaddingLeft: true},
+		{Position: protocol.Position{Line: 5, Character: 23}, Label: "(1 file)", PaddingLeft: true},
+	}, hints(protocol.Range{End: protocol.Position{Line: 7}}))
+	assert.Len(t, hints(protocol.Range{
+		Start: protocol.Position{Line: 4},
+		End:   protocol.Position{Line: 7},
+	}), 1)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// showMatchedFiles returns the files currently matched by a pattern of a
+// go:embed directive of a document, after showing them to the user.
+//
+// The arguments are the URI of the document and the pattern. Unlike the
+// diagnostics, a pattern matching no file is not an error: the empty list
+// is shown and returned.
+func (l *lspHandler) showMatchedFiles(
+	ctx context.Context,
+	arguments []interface{},
+) ([]string, error) {
+	if len(arguments) != 2 {
+		return nil, fmt.Errorf(
+			"%s expects 2 arguments, got %d",
+			lsp.CommandShowMatchedFiles,
+			len(arguments),
+		)
+	}
+	document, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s expects a document URI, got %T",
+			lsp.CommandShowMatchedFiles,
+			arguments[0],
+		)
+	}
+	pattern, ok := arguments[1].(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s expects a pattern, got %T",
+			lsp.CommandShowMatchedFiles,
+			arguments[1],
+		)
+	}
+	resolution, err := resolver.Inspect(ctx, l.fs, uriToDir(uri.URI(document)), pattern)
+	if err != nil && resolution.Files == nil {
+		// an empty resolution comes with the errors other than the lack
+		// of matching files
+		return nil, err
+	}
+	message := fmt.Sprintf("%s matches no file", parsers.QuotePattern(pattern))
+	if len(resolution.Files) > 0 {
+		message = fmt.Sprintf(
+			"%s matches %d file(s):\n%s",
+			parsers.QuotePattern(pattern),
+			len(resolution.Files),
+			strings.Join(resolution.Files, "\n"),
+		)
+	}
+	err = l.writer.WriteResponse(ctx, lsp.ShowMessageNotification{
+		Notification: lsp.Notification{
+			RPC:    lsp.RPCVersion,
+			Method: methods.MethodWindowShowMessage.String(),
+		},
+		Params: protocol.ShowMessageParams{
+			Type:    protocol.MessageTypeInfo,
+			Message: message,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to show matched files: %w", err)
+	}
+	return resolution.Files, nil
+}
+
+// showMatchedFilesActions returns the actions showing the files matched by
+// the glob patterns of the directives of the given lines.
+//
+// Patterns naming a single file or directory are left out, their matches
+// being plain from the pattern itself.
+func showMatchedFilesActions(
+	docURI protocol.DocumentURI,
+	index *parsers.Index,
+	lines protocol.Range,
+) []protocol.CodeAction {
+	actions := make([]protocol.CodeAction, 0)
+	for _, directive := range index.Directives {
+		if !inLines(lines, directive.Line) {
+			continue
+		}
+		for _, pattern := range directive.Patterns {
+			if parsers.IsLiteralPattern(pattern.Glob) {
+				continue
+			}
+			title := "Show the files matched by " + parsers.QuotePattern(pattern.Value)
+			actions = append(actions, protocol.CodeAction{
+				Title: title,
+				Kind:  protocol.Source,
+				Command: &protocol.Command{
+					Title:     title,
+					Command:   lsp.CommandShowMatchedFiles,
+					Arguments: []interface{}{string(docURI), pattern.Value},
+				},
+			})
+		}
+	}
+	return actions
+}
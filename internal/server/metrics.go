@@ -0,0 +1,111 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"sync/atomic"
+
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/resolver"
+)
+
+// metrics counts the messages handled by a session of the server, for
+// performance tuning.
+//
+// Its counters are atomics, so counting a message takes no lock and no
+// allocation.
+type metrics struct {
+	// requests counts the messages handled by method. It is filled when
+	// the methods of the session are registered and only read afterwards.
+	requests map[methods.Method]*atomic.Int64
+	// unknown counts the requests of methods the server does not handle.
+	unknown atomic.Int64
+	// rejected counts the messages failing validation, which are counted
+	// under no method.
+	rejected atomic.Int64
+	// errors counts the messages whose handler failed.
+	errors atomic.Int64
+	// dirs counts the directories read by the session.
+	dirs atomic.Int64
+}
+
+// register counts the messages of the given methods.
+func (m *metrics) register(handled map[methods.Method]methodHandler) {
+	m.requests = make(map[methods.Method]*atomic.Int64, len(handled))
+	for method := range handled {
+		m.requests[method] = &atomic.Int64{}
+	}
+}
+
+// record counts a message of a registered method, or a reply when the
+// method is empty, whose handler failed when err is not nil.
+//
+// Replies to the requests of the server are only counted when they fail,
+// and exiting is not a failure.
+func (m *metrics) record(method string, err error) {
+	if err != nil && !errors.Is(err, ErrExit) {
+		m.errors.Add(1)
+	}
+	if counter, ok := m.requests[methods.Method(method)]; ok {
+		counter.Add(1)
+	}
+}
+
+// meteredFS is a file system counting the directories read from it, so
+// that the work of a session is counted apart from the other sessions.
+type meteredFS struct {
+	resolver.FS
+	// dirs counts the directories read.
+	dirs *atomic.Int64
+}
+
+// ReadDir counts and reads the named directory.
+func (m meteredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.dirs.Add(1)
+	return m.FS.ReadDir(name)
+}
+
+// Stats are the counters of the work of a session of the server.
+type Stats struct {
+	// Requests are the numbers of messages handled by method, leaving out
+	// the methods without messages.
+	Requests map[string]int64 `json:"requests"`
+	// Unknown is the number of requests of methods the server does not
+	// handle.
+	Unknown int64 `json:"unknown"`
+	// Rejected is the number of messages failing validation.
+	Rejected int64 `json:"rejected"`
+	// Errors is the number of messages whose handler failed.
+	Errors int64 `json:"errors"`
+	// IndexHits is the number of document indexes reused from the cache.
+	IndexHits int `json:"indexHits"`
+	// IndexParses is the number of document indexes built.
+	IndexParses int `json:"indexParses"`
+	// IndexHitRatio is the ratio of the document index lookups answered
+	// from the cache.
+	IndexHitRatio float64 `json:"indexHitRatio"`
+	// Dirs is the number of directories read by the session.
+	Dirs int64 `json:"dirs"`
+}
+
+// stats returns a snapshot of the counters of the session.
+func (l *lspHandler) stats() Stats {
+	stats := Stats{
+		Requests:    make(map[string]int64),
+		Unknown:     l.metrics.unknown.Load(),
+		Rejected:    l.metrics.rejected.Load(),
+		Errors:      l.metrics.errors.Load(),
+		IndexHits:   l.index.Hits(),
+		IndexParses: l.index.Parses(),
+		Dirs:        l.metrics.dirs.Load(),
+	}
+	for method, counter := range l.metrics.requests {
+		if count := counter.Load(); count > 0 {
+			stats.Requests[string(method)] = count
+		}
+	}
+	if lookups := stats.IndexHits + stats.IndexParses; lookups > 0 {
+		stats.IndexHitRatio = float64(stats.IndexHits) / float64(lookups)
+	}
+	return stats
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
+)
+
+// TestStats tests that the counters of a session increment across
+// requests, apart from those of the other sessions, and are returned by
+// the stats command.
+func TestStats(t *testing.T) {
+	source := "package main\n\n//go:embed static\nvar f embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":          source,
+		"static/app.js":    "app",
+		"static/css/a.css": "a",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler, _ := newTestHandler()
+	other, _ := newTestHandler()
+	ctx := context.Background()
+	send := func(body string) (interface{}, error) {
+		return handler.Handle(ctx, newTestMessage(t, body))
+	}
+
+	params, err := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": docURI, "version": 1, "text": source},
+	})
+	assert.NoError(t, err)
+	_, err = send(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":` + string(params) + `}`)
+	assert.NoError(t, err)
+	highlight := `{"jsonrpc":"2.0","id":1,"method":"textDocument/documentHighlight","params":{` +
+		`"textDocument":{"uri":"` + string(docURI) + `"},"position":{"line":2,"character":13}}}`
+	for i := 0; i < 3; i++ {
+		_, err = send(highlight)
+		assert.NoError(t, err)
+	}
+	_, err = send(`{"jsonrpc":"2.0","id":2,"method":"custom/unknown","params":{}}`)
+	assert.Error(t, err)
+	_, err = send(`{"jsonrpc":"2.0","method":"$/custom","params":{}}`)
+	assert.NoError(t, err)
+	_, err = send(`{"jsonrpc":"1.0","id":4,"method":"textDocument/documentHighlight","params":{}}`)
+	assert.Error(t, err)
+
+	resp, err := send(`{"jsonrpc":"2.0","id":3,"method":"workspace/executeCommand","params":{"command":"embedpls.stats"}}`)
+	assert.NoError(t, err)
+	stats := resp.(lsp.ExecuteCommandResponse).Result.(Stats)
+	assert.Equal(t, map[string]int64{
+		"textDocument/didOpen":           1,
+		"textDocument/documentHighlight": 3,
+	}, stats.Requests, "a message is counted once handled")
+	assert.Equal(t, int64(1), stats.Unknown, "ignored notifications are not unknown")
+	assert.Equal(t, int64(1), stats.Rejected)
+	assert.Equal(t, int64(0), stats.Errors, "unknown and rejected messages are no handler failures")
+	assert.Equal(t, 1, stats.IndexParses)
+	assert.Equal(t, 3, stats.IndexHits, "the index of the diagnostics is reused")
+	assert.InDelta(t, 3.0/4.0, stats.IndexHitRatio, 1e-9)
+	assert.GreaterOrEqual(t, stats.Dirs, int64(2))
+
+	assert.Equal(t, map[string]int64{
+		"textDocument/didOpen":           1,
+		"textDocument/documentHighlight": 3,
+		"workspace/executeCommand":       1,
+	}, handler.stats().Requests)
+
+	assert.Equal(t, Stats{Requests: map[string]int64{}}, other.stats(), "sessions are counted apart")
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"go.lsp.dev/uri"
+)
+
+// uriToPath returns the file path of a document URI.
+//
+// The path of the URI is percent-decoded explicitly, keeping plus signs
+// as they are since they only mean spaces in query strings. Unlike
+// uri.URI.Filename, malformed URIs do not panic but are used as is.
+//
+// Windows drive URIs such as file:///c:/src/main.go lose the slash before
+// their drive letter, which is upper cased so the paths of a document are
+// equal whichever way the client spells its drive.
+func uriToPath(u uri.URI) string {
+	raw := strings.TrimPrefix(string(u), uri.FileScheme+"://")
+	path, err := url.PathUnescape(raw)
+	if err != nil {
+		path = raw
+	}
+	if len(path) >= 3 && path[0] == '/' && isDriveLetter(path[1]) && path[2] == ':' {
+		path = path[1:]
+	}
+	path = filepath.Clean(filepath.FromSlash(path))
+	if len(path) >= 2 && isDriveLetter(path[0]) && path[1] == ':' {
+		path = strings.ToUpper(path[:1]) + path[1:]
+	}
+	return path
+}
+
+// isDriveLetter reports whether c can be the letter of a Windows drive.
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// uriToDir returns the directory of the document of a URI, which is the
+// directory its embedding patterns are relative to.
+func uriToDir(u uri.URI) string {
+	return filepath.Dir(uriToPath(u))
+}
+
+// embedDir returns the directory completion and hover resolve the
+// embedding patterns of a document against: the embed base of the
+// configuration below the workspace root when set, the directory of the
+// document otherwise.
+//
+// The go command always resolves patterns against the package directory,
+// so the features reporting or changing what is embedded, such as the
+// diagnostics and renames, use uriToDir instead.
+func (l *lspHandler) embedDir(u uri.URI) string {
+	base := l.currentConfig().EmbedBase
+	if base == "" || l.root == "" {
+		return uriToDir(u)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(base))
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/conneroisu/embedpls/internal/config"
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"github.com/conneroisu/embedpls/internal/safe"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestURIToDir tests converting document URIs to their directory.
+func TestURIToDir(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  uri.URI
+		want string
+	}{
+		{
+			name: "unix",
+			uri:  "file:///home/user/src/main.go",
+			want: "/home/user/src",
+		},
+		{
+			name: "spaces and percent encoding",
+			uri:  "file:///home/user/my%20src/caf%C3%A9/main.go",
+			want: "/home/user/my src/café",
+		},
+		{
+			name: "plus is not a space",
+			uri:  "file:///home/user/c%2B%2B+go/main.go",
+			want: "/home/user/c+++go",
+		},
+		{
+			name: "malformed escape",
+			uri:  "file:///home/user/100%/main.go",
+			want: "/home/user/100%",
+		},
+		{
+			name: "windows drive",
+			uri:  "file:///C:/Users/user/src/main.go",
+			want: "C:/Users/user/src",
+		},
+		{
+			name: "windows encoded lower case drive",
+			uri:  "file:///c%3A/Users/user/my%20src/main.go",
+			want: "C:/Users/user/my src",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, filepath.FromSlash(tt.want), uriToDir(tt.uri))
+		})
+	}
+}
+
+// TestRelativeReadFile tests reading embedded files from the directory of
+// a document whose path needs decoding.
+func TestRelativeReadFile(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":           "package main\n",
+		"hello.txt":         "root",
+		"static/hello.txt":  "hello",
+		"my assets/a+b.txt": "plus",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	tests := []struct {
+		name      string
+		embedPath string
+		want      string
+		wantErr   bool
+	}{
+		{name: "nested path", embedPath: "static/hello.txt", want: "hello"},
+		{name: "spaces and plus", embedPath: "my assets/a+b.txt", want: "plus"},
+		{name: "current directory prefix", embedPath: "./static/hello.txt", want: "hello"},
+		{name: "missing", embedPath: "static/missing.txt", wantErr: true},
+		{name: "suffix of a name", embedPath: "ello.txt", wantErr: true},
+		{name: "extension", embedPath: "txt", wantErr: true},
+		{name: "single glob match", embedPath: "static/*.txt", want: "hello"},
+		{name: "several files", embedPath: "*/*.txt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := relativeReadFile(context.Background(), resolver.OS, uriToDir(docURI), tt.embedPath, hoverSize)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestRelativeReadFileNotFound tests that the error of a missing file
+// names the path, the directory it was searched in and what it holds.
+func TestRelativeReadFileNotFound(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":          "package main\n",
+		"static/a.txt":     "a",
+		"static/b.txt":     "b",
+		"static/css/c.css": "c",
+		"static/d.txt":     "d",
+		"empty/.keep":      "",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	tests := []struct {
+		name      string
+		embedPath string
+		want      string
+	}{
+		{
+			name:      "few entries shown",
+			embedPath: "static/missing.txt",
+			want: "file not found: static/missing.txt in " +
+				filepath.Join(dir, "static") + " (has a.txt, b.txt, css/ and 1 more)",
+		},
+		{
+			name:      "all entries shown",
+			embedPath: "empty/missing.txt",
+			want: "file not found: empty/missing.txt in " +
+				filepath.Join(dir, "empty") + " (has .keep)",
+		},
+		{
+			name:      "missing directory",
+			embedPath: "assets/missing.txt",
+			want: "file not found: assets/missing.txt in " +
+				filepath.Join(dir, "assets") + " (no such directory)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := relativeReadFile(context.Background(), resolver.OS, uriToDir(docURI), tt.embedPath, hoverSize)
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.want, err.Error())
+			}
+		})
+	}
+}
+
+// TestRelativeReadFileFoldCase tests that embedding paths differing in
+// case from the names on disk are only read when case is folded.
+func TestRelativeReadFileFoldCase(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":          "package main\n",
+		"Static/hello.txt": "hello",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+
+	content, _, err := relativeReadFile(context.Background(), resolver.FoldCase(resolver.OS), uriToDir(docURI), "static/HELLO.txt", hoverSize)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", content)
+
+	_, _, err = relativeReadFile(context.Background(), resolver.OS, uriToDir(docURI), "static/HELLO.txt", hoverSize)
+	assert.Error(t, err)
+}
+
+// TestEncodedDirectory tests that the features reading the directory of a
+// document work when its path is percent-encoded in the URI.
+func TestEncodedDirectory(t *testing.T) {
+	root := writeTestFiles(t, map[string]string{
+		"my dir+go/main.go":   "package main\n",
+		"my dir+go/hello.txt": "hello",
+	})
+	docURI := uri.URI("file://" + (&url.URL{
+		Path: filepath.ToSlash(filepath.Join(root, "my dir+go", "main.go")),
+	}).EscapedPath())
+	assert.Contains(t, string(docURI), "my%20dir+go")
+
+	handler, _ := newTestHandler()
+	errCh := make(chan error, 1)
+	resp := <-handler.getEmbbeddables(context.Background(), docURI, "", errCh)
+	names := make([]string, 0)
+	for _, embed := range resp.embeddables {
+		names = append(names, embed.name)
+	}
+	assert.ElementsMatch(t, []string{"hello.txt", "main.go"}, names)
+
+	content, _, err := relativeReadFile(context.Background(), resolver.OS, uriToDir(docURI), "hello.txt", hoverSize)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", content)
+}
+
+// TestInMemoryFS tests that completion, hover and the commands read the
+// files of the file system of the handler options rather than those of the
+// host.
+func TestInMemoryFS(t *testing.T) {
+	source := "package main\n\n//go:embed static/hello.txt\nvar s string\n"
+	handler := NewLSPHandler(
+		safe.NewSafeMap[uri.URI, string](),
+		rpc.NewWriter(&bytes.Buffer{}),
+		HandlerOptions{FS: resolver.FromFS(fstest.MapFS{
+			"project/main.go":          {Data: []byte(source)},
+			"project/static/hello.txt": {Data: []byte("hello")},
+			"project/static/css/a.css": {Data: []byte("a {}")},
+		})},
+	).(*lspHandler)
+	docURI := uri.URI("file:///project/main.go")
+	handler.documents.Set(docURI, source)
+
+	errCh := make(chan error, 1)
+	resp := <-handler.getEmbbeddables(context.Background(), docURI, "static/", errCh)
+	names := make([]string, 0)
+	for _, embed := range resp.embeddables {
+		names = append(names, embed.name)
+	}
+	assert.ElementsMatch(t, []string{"static/css/", "static/hello.txt"}, names)
+
+	hover, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+		Params: protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Position:     protocol.Position{Line: 2, Character: 14},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "```txt\nhello\n```\n", hover.(lsp.HoverResponse).Result.Contents.Value)
+
+	resolved, err := handler.handleCompletionItemResolve(context.Background(), lsp.CompletionItemResolveRequest{
+		Params: protocol.CompletionItem{
+			Label: "static/hello.txt",
+			Data:  completionData{Path: "/project/static/hello.txt"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, resolved.(lsp.CompletionItemResolveResponse).Result.Documentation.(protocol.MarkupContent).Value, "hello")
+
+	embedders, err := handler.findEmbedders(context.Background(), []interface{}{"/project/static/hello.txt"})
+	assert.NoError(t, err)
+	if assert.Len(t, embedders, 1) {
+		assert.Equal(t, docURI, embedders[0].URI)
+	}
+}
+
+// TestEmbedBase tests that the embed base of the configuration replaces
+// the directory of the document as the base of the patterns it completes
+// and hovers, while the diagnostics keep resolving them against the
+// directory of the document.
+func TestEmbedBase(t *testing.T) {
+	source := "package main\n\n//go:embed static/hello.txt\nvar hello string\n"
+	tests := []struct {
+		name      string
+		embedBase string
+		wantNames []string
+		wantHover string
+	}{
+		{
+			name:      "directory of the document",
+			wantNames: []string{"static/local.txt"},
+		},
+		{
+			name:      "embed base",
+			embedBase: "gen/assets",
+			wantNames: []string{"static/hello.txt"},
+			wantHover: "```txt\ngenerated\n```\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			handler := NewLSPHandler(
+				safe.NewSafeMap[uri.URI, string](),
+				rpc.NewWriter(out),
+				HandlerOptions{FS: resolver.FromFS(fstest.MapFS{
+					"project/cmd/app/main.go":             {Data: []byte(source)},
+					"project/cmd/app/static/local.txt":    {Data: []byte("local")},
+					"project/gen/assets/static/hello.txt": {Data: []byte("generated")},
+				})},
+			).(*lspHandler)
+			handler.root = "/project"
+			cfg := config.Default()
+			cfg.EmbedBase = tt.embedBase
+			handler.setConfig(cfg)
+			docURI := uri.URI("file:///project/cmd/app/main.go")
+			handler.documents.Set(docURI, source)
+
+			errCh := make(chan error, 1)
+			resp := <-handler.getEmbbeddables(context.Background(), docURI, "static/", errCh)
+			names := make([]string, 0)
+			for _, embed := range resp.embeddables {
+				names = append(names, embed.name)
+			}
+			assert.ElementsMatch(t, tt.wantNames, names)
+
+			hover, err := handler.handleTextDocumentHover(context.Background(), lsp.HoverRequest{
+				Params: protocol.HoverParams{
+					TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+						Position:     protocol.Position{Line: 2, Character: 14},
+					},
+				},
+			})
+			if tt.wantHover == "" {
+				if err == nil {
+					t.Errorf("expected an error hovering a missing file, got %v", hover)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantHover, hover.(lsp.HoverResponse).Result.Contents.Value)
+			}
+
+			local := "package main\n\n//go:embed static/local.txt\nvar local string\n"
+			assert.NoError(t, handler.publishDiagnostics(context.Background(), docURI, local))
+			assert.Empty(t, publishedDiagnostics(t, out))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/conneroisu/embedpls/internal/resolver"
+)
+
+const (
+	// previewLines is the maximum number of lines of a text preview.
+	previewLines = 10
+	// hoverSize is the number of bytes read from the start of a file to
+	// show it in a hover.
+	hoverSize = 64 << 10
+)
+
+// readHead reads the first limit bytes of a file of fsys and returns them
+// with the size of the whole file, bounding the cost of previewing large
+// files.
+func readHead(fsys resolver.FS, filename string, limit int) ([]byte, int64, error) {
+	f, err := fsys.Open(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	head := make([]byte, limit)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, 0, err
+	}
+	return head[:n], info.Size(), nil
+}
+
+// filePreview returns the preview of a file shown in its completion item
+// from the start of its content and its size: its first lines for text
+// files, its size and media type for binary files.
+func (l *lspHandler) filePreview(name string, head []byte, size int64) string {
+	if isBinary(head) {
+		return fmt.Sprintf(
+			"binary file, %s, %s",
+			formatSize(size),
+			http.DetectContentType(head),
+		)
+	}
+	text, truncated := headText(string(head), size)
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > previewLines {
+		lines = lines[:previewLines]
+		truncated = true
+	}
+	preview := l.codeBlock(
+		strings.TrimPrefix(path.Ext(name), "."),
+		strings.Join(lines, ""),
+	)
+	if truncated {
+		preview += fmt.Sprintf("\n… %s in total\n", formatSize(size))
+	}
+	return preview
+}
+
+// headText returns the start of the text of a file from the start of its
+// content and its size, reporting whether the file holds more.
+//
+// The partial last line of a truncated file is dropped, as it may end in a
+// partial rune.
+func headText(head string, size int64) (string, bool) {
+	if size <= int64(len(head)) {
+		return head, false
+	}
+	if i := strings.LastIndex(head, "\n"); i >= 0 {
+		head = head[:i+1]
+	}
+	return head, true
+}
+
+// isBinary reports whether the start of a file looks like binary content,
+// that is content with NUL bytes or that is not UTF-8.
+func isBinary(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	// the head may end in the middle of a rune
+	return !utf8.Valid(trimPartialRune(head))
+}
+
+// trimPartialRune drops the incomplete rune cut at the end of a buffer.
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// formatSize formats a size in bytes for humans, in binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, prefix := float64(size)/unit, 0
+	for value >= unit && prefix < len("KMGTPE")-1 {
+		value /= unit
+		prefix++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTPE"[prefix])
+}
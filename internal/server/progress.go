@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+const (
+	// defaultProgressDelay is the time an operation runs before its
+	// progress is reported, so that the quick ones, such as diagnosing a
+	// document on every change, report nothing.
+	defaultProgressDelay = 500 * time.Millisecond
+)
+
+// progress reports the progress of a long-running operation to the client
+// with $/progress notifications.
+//
+// Nothing is sent for operations ending within the progress delay of the
+// handler. Past it, the token is created with a
+// window/workDoneProgress/create request, and the beginning of the
+// operation reported along with its last report.
+//
+// A token may only be used once the client replied successfully to its
+// window/workDoneProgress/create request, so the notifications are queued
+// until then, and dropped when the client fails the request. The reply is
+// read by the same loop as the requests, so the operation never waits for
+// it.
+//
+// A nil progress reports nothing, so that callers do not need to check
+// whether the client supports progress.
+type progress struct {
+	handler *lspHandler
+	title   string
+	token   protocol.ProgressToken
+	// mu guards the state of the token and the queued notifications.
+	mu sync.Mutex
+	// timer begins the progress once the delay of the handler is over.
+	timer *time.Timer
+	// begun is whether the creation of the token was requested.
+	begun bool
+	// ended is whether the operation ended.
+	ended bool
+	// last is the last report made before the progress began.
+	last interface{}
+	// created is whether the client created the token.
+	created bool
+	// failed is whether the client failed to create the token.
+	failed bool
+	// queued are the values of the notifications sent before the token
+	// was created.
+	queued []interface{}
+}
+
+// progressReply is the reply of the client to a
+// window/workDoneProgress/create request.
+type progressReply struct {
+	// Error is the error of a failed request.
+	Error json.RawMessage `json:"error"`
+}
+
+// beginProgress starts reporting the progress of an operation, which
+// begins once the operation has run for the progress delay of the
+// handler, or returns nil when the client does not support server
+// initiated progress.
+func (l *lspHandler) beginProgress(ctx context.Context, title string) *progress {
+	if !l.workDoneProgress {
+		return nil
+	}
+	p := &progress{handler: l, title: title}
+	if l.progressDelay <= 0 {
+		p.begin(ctx)
+		return p
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timer = time.AfterFunc(l.progressDelay, func() { p.begin(ctx) })
+	return p
+}
+
+// begin creates a progress token and reports the beginning of the
+// operation, unless it already ended.
+func (p *progress) begin(ctx context.Context) {
+	l := p.handler
+	p.mu.Lock()
+	if p.ended {
+		p.mu.Unlock()
+		return
+	}
+	p.begun = true
+	p.token = *protocol.NewProgressToken(
+		fmt.Sprintf("embedpls-%d", l.progressTokens.Add(1)),
+	)
+	p.queued = append(p.queued, &protocol.WorkDoneProgressBegin{
+		Kind:  protocol.WorkDoneProgressKindBegin,
+		Title: p.title,
+	})
+	if p.last != nil {
+		p.queued = append(p.queued, p.last)
+	}
+	token := p.token
+	p.mu.Unlock()
+	// the reply may be read before WriteRequest returns its id, so its
+	// handler waits for the progress to be recorded under the same lock
+	l.progressMu.Lock()
+	defer l.progressMu.Unlock()
+	// the token is only encoded as a string through a pointer, its
+	// MarshalJSON method having a pointer receiver
+	id, err := l.writer.WriteRequest(
+		ctx,
+		methods.MethodWindowWorkDoneProgressCreate,
+		&protocol.WorkDoneProgressCreateParams{Token: token},
+	)
+	if err != nil {
+		l.logger.Errorf("failed to create progress: %v", err)
+		p.fail()
+		return
+	}
+	l.progress.Set(id, p)
+}
+
+// handleProgressReply sends the notifications queued for the progress
+// token created by a window/workDoneProgress/create request once the
+// client replied successfully to it, and drops them otherwise.
+func (l *lspHandler) handleProgressReply(
+	ctx context.Context,
+	msg *rpc.BaseMessage,
+) (rpc.MethodActor, error) {
+	l.progressMu.Lock()
+	p, ok := l.progress.Get(msg.ID)
+	l.progress.Delete(msg.ID)
+	l.progressMu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	var reply progressReply
+	if err := json.Unmarshal(msg.Content, &reply); err != nil {
+		(*p).fail()
+		return nil, fmt.Errorf("failed to decode progress reply: %w", err)
+	}
+	if len(reply.Error) > 0 && string(reply.Error) != "null" {
+		l.logger.Warnf("client failed to create progress: %s", reply.Error)
+		(*p).fail()
+		return nil, nil
+	}
+	(*p).create(ctx)
+	return nil, nil
+}
+
+// create marks the token as created by the client and sends the queued
+// notifications.
+func (p *progress) create(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.created = true
+	for _, value := range p.queued {
+		p.write(ctx, value)
+	}
+	p.queued = nil
+}
+
+// fail marks the token as refused by the client, dropping the queued
+// notifications and those to come.
+func (p *progress) fail() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failed = true
+	p.queued = nil
+}
+
+// report reports the progress of the operation, only keeping the last
+// report until the progress begins.
+func (p *progress) report(ctx context.Context, message string, percentage uint32) {
+	if p == nil {
+		return
+	}
+	value := &protocol.WorkDoneProgressReport{
+		Kind:       protocol.WorkDoneProgressKindReport,
+		Message:    message,
+		Percentage: percentage,
+	}
+	p.mu.Lock()
+	if !p.begun {
+		p.last = value
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	p.notify(ctx, value)
+}
+
+// end reports the end of the operation, or cancels the progress when it
+// has not begun.
+func (p *progress) end(ctx context.Context, message string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.ended = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	begun := p.begun
+	p.mu.Unlock()
+	if !begun {
+		return
+	}
+	p.notify(ctx, &protocol.WorkDoneProgressEnd{
+		Kind:    protocol.WorkDoneProgressKindEnd,
+		Message: message,
+	})
+}
+
+// notify sends a $/progress notification with the given value once the
+// token is created.
+func (p *progress) notify(ctx context.Context, value interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case p.failed:
+	case p.created:
+		p.write(ctx, value)
+	default:
+		p.queued = append(p.queued, value)
+	}
+}
+
+// write writes a $/progress notification with the given value.
+func (p *progress) write(ctx context.Context, value interface{}) {
+	err := p.handler.writer.WriteResponse(ctx, &lsp.ProgressNotification{
+		Notification: lsp.Notification{
+			RPC:    lsp.RPCVersion,
+			Method: string(methods.MethodProgress),
+		},
+		Params: protocol.ProgressParams{
+			Token: p.token,
+			Value: value,
+		},
+	})
+	if err != nil {
+		p.handler.logger.Errorf("failed to report progress: %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"github.com/conneroisu/embedpls/internal/safe"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// readTestMessages decodes the messages written to a buffer.
+func readTestMessages(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	scanner := bufio.NewScanner(out)
+	scanner.Split(rpc.Split)
+	messages := make([]map[string]interface{}, 0)
+	for scanner.Scan() {
+		msg, err := rpc.DecodeMessage(scanner.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var message map[string]interface{}
+		if err := json.Unmarshal(msg.Content, &message); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// progressKinds returns the kinds of the $/progress notifications of the
+// token of a window/workDoneProgress/create request, which must all be
+// for that token.
+func progressKinds(t *testing.T, token interface{}, messages []map[string]interface{}) []interface{} {
+	t.Helper()
+	kinds := make([]interface{}, 0)
+	for _, message := range messages {
+		assert.Equal(t, "$/progress", message["method"])
+		params := message["params"].(map[string]interface{})
+		assert.Equal(t, token, params["token"])
+		kinds = append(kinds, params["value"].(map[string]interface{})["kind"])
+	}
+	return kinds
+}
+
+// TestProgress tests that progress is reported around resolving the
+// patterns of an embedding variable only when the client supports it, and
+// only once the client created its token.
+func TestProgress(t *testing.T) {
+	source := "//go:embed static templates\nvar content embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":              source,
+		"static/app.js":        "app",
+		"templates/index.html": "index",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	block := parsers.ParseEmbedBlocks(source)[0]
+
+	handler, out := newTestHandler()
+	handler.embedBlockHover(context.Background(), docURI, block)
+	assert.Empty(t, out.String(), "progress requires client support")
+
+	handler.workDoneProgress = true
+	handler.progressDelay = 0
+	handler.embedBlockHover(context.Background(), docURI, block)
+	messages := readTestMessages(t, out)
+	if !assert.Len(t, messages, 1, "progress awaits the creation of its token") {
+		return
+	}
+	assert.Equal(t, "window/workDoneProgress/create", messages[0]["method"])
+	token := messages[0]["params"].(map[string]interface{})["token"]
+	assert.Equal(t, "embedpls-1", token)
+
+	_, err := handler.Handle(context.Background(), newTestMessage(t,
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":null}`, messages[0]["id"]),
+	))
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]interface{}{"begin", "report", "report", "end"},
+		progressKinds(t, token, readTestMessages(t, out)),
+	)
+}
+
+// TestProgressRefused tests that no progress is reported when the client
+// fails to create its token.
+func TestProgressRefused(t *testing.T) {
+	handler, out := newTestHandler()
+	handler.workDoneProgress = true
+	handler.progressDelay = 0
+	p := handler.beginProgress(context.Background(), "Resolving")
+	messages := readTestMessages(t, out)
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	_, err := handler.Handle(context.Background(), newTestMessage(t,
+		fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%v,"error":{"code":-32603,"message":"no"}}`,
+			messages[0]["id"],
+		),
+	))
+	assert.NoError(t, err)
+	p.end(context.Background(), "done")
+	assert.Empty(t, out.String())
+}
+
+// TestProgressCompletionAndDiagnostics tests that progress is reported
+// while completing the paths of a ReadFile call and while diagnosing a
+// document.
+func TestProgressCompletionAndDiagnostics(t *testing.T) {
+	source := "package main\n\n//go:embed static\nvar content embed.FS\n\n" +
+		"func main() {\n\tcontent.ReadFile(\"\")\n}\n"
+	dir := writeTestFiles(t, map[string]string{"static/app.js": "app"})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	tests := []struct {
+		name string
+		run  func(handler *lspHandler) error
+	}{
+		{
+			name: "completion",
+			run: func(handler *lspHandler) error {
+				_, err := handler.readFileCompletion(
+					context.Background(),
+					docURI,
+					source,
+					protocol.Position{Line: 6, Character: 19},
+				)
+				return err
+			},
+		},
+		{
+			name: "diagnostics",
+			run: func(handler *lspHandler) error {
+				return handler.publishDiagnostics(context.Background(), docURI, source)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, out := newTestHandler()
+			handler.workDoneProgress = true
+			handler.progressDelay = 0
+			handler.documents.Set(docURI, source)
+			assert.NoError(t, tt.run(handler))
+			messages := readTestMessages(t, out)
+			if !assert.NotEmpty(t, messages) {
+				return
+			}
+			assert.Equal(t, "window/workDoneProgress/create", messages[0]["method"])
+			token := messages[0]["params"].(map[string]interface{})["token"]
+			_, err := handler.Handle(context.Background(), newTestMessage(t,
+				fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":null}`, messages[0]["id"]),
+			))
+			assert.NoError(t, err)
+			assert.Equal(
+				t,
+				[]interface{}{"begin", "report", "end"},
+				progressKinds(t, token, readTestMessages(t, out)),
+			)
+		})
+	}
+}
+
+// TestProgressDelay tests that the progress of a diagnostics pass is only
+// reported once the pass outlasts the progress delay, so that diagnosing
+// a document on every change sends nothing.
+func TestProgressDelay(t *testing.T) {
+	source := "package main\n\n//go:embed static\nvar content embed.FS\n"
+	tests := []struct {
+		name      string
+		delay     time.Duration
+		wantKinds []interface{}
+	}{
+		{name: "quick", delay: 0},
+		{
+			name:      "slow",
+			delay:     50 * time.Millisecond,
+			wantKinds: []interface{}{"begin", "report", "end"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			handler := NewLSPHandler(
+				safe.NewSafeMap[uri.URI, string](),
+				rpc.NewWriter(out),
+				HandlerOptions{
+					Version: "v0.0.0-test",
+					FS: slowFS{
+						FS: resolver.FromFS(fstest.MapFS{
+							"pkg/static/app.js": {Data: []byte("app")},
+						}),
+						delay: tt.delay,
+					},
+				},
+			).(*lspHandler)
+			handler.workDoneProgress = true
+			handler.progressDelay = 10 * time.Millisecond
+			docURI := uri.URI("file:///pkg/main.go")
+			assert.NoError(t, handler.publishDiagnostics(context.Background(), docURI, source))
+			messages := readTestMessages(t, out)
+			if tt.wantKinds == nil {
+				if assert.Len(t, messages, 1) {
+					assert.Equal(t, "textDocument/publishDiagnostics", messages[0]["method"])
+				}
+				return
+			}
+			if !assert.Len(t, messages, 2) {
+				return
+			}
+			assert.Equal(t, "window/workDoneProgress/create", messages[0]["method"])
+			token := messages[0]["params"].(map[string]interface{})["token"]
+			_, err := handler.Handle(context.Background(), newTestMessage(t,
+				fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":null}`, messages[0]["id"]),
+			))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKinds, progressKinds(t, token, readTestMessages(t, out)))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/rpc"
+)
+
+// methodHandler handles a message of a single method.
+type methodHandler func(
+	ctx context.Context,
+	msg *rpc.BaseMessage,
+) (rpc.MethodActor, error)
+
+// ignorableNotifications are the standard notifications the server has no
+// use for and safely ignores instead of reporting an unknown method.
+var ignorableNotifications = map[methods.Method]bool{
+	methods.MethodTelemetryEvent:                     true,
+	methods.MethodWindowWorkDoneProgressCancel:       true,
+	methods.MethodWorkspaceDidChangeWorkspaceFolders: true,
+	methods.MethodWorkspaceDidCreateFiles:            true,
+	methods.MethodWorkspaceDidRenameFiles:            true,
+	methods.MethodWorkspaceDidDeleteFiles:            true,
+	methods.MethodTextDocumentWillSave:               true,
+	// $/logTrace is meant for clients, there is nothing to do when one
+	// echoes it back.
+	methods.MethodLogTrace: true,
+}
+
+// registerMethods returns the handlers of the methods supported by the
+// server.
+func (l *lspHandler) registerMethods() map[methods.Method]methodHandler {
+	registry := map[methods.Method]methodHandler{
+		methods.MethodInitialize:                      decoded(l.handleInitialize),
+		methods.MethodShutdown:                        decoded(l.handleShutdown),
+		methods.MethodExit:                            l.handleExit,
+		methods.MethodInitialized:                     l.handleInitialized,
+		methods.MethodCancelRequest:                   decoded(l.handleCancelRequest),
+		methods.MethodSetTrace:                        decoded(l.handleSetTrace),
+		methods.MethodTextDocumentDidOpen:             decoded(l.handleTextDocumentDidOpen),
+		methods.MethodTextDocumentDidChange:           decoded(l.handleTextDocumentDidChange),
+		methods.MethodTextDocumentDidSave:             decoded(l.handleTextDocumentDidSave),
+		methods.MethodTextDocumentDidClose:            decoded(l.handleTextDocumentDidClose),
+		methods.MethodTextDocumentCompletion:          decoded(l.handleTextDocumentCompletion),
+		methods.MethodCompletionItemResolve:           decoded(l.handleCompletionItemResolve),
+		methods.MethodTextDocumentHover:               decoded(l.handleTextDocumentHover),
+		methods.MethodTextDocumentCodeAction:          decoded(l.handleTextDocumentCodeAction),
+		methods.MethodTextDocumentInlayHint:           decoded(l.handleTextDocumentInlayHint),
+		methods.MethodTextDocumentSignatureHelp:       decoded(l.handleTextDocumentSignatureHelp),
+		methods.MethodTextDocumentDocumentHighlight:   decoded(l.handleTextDocumentDocumentHighlight),
+		methods.MethodTextDocumentFoldingRange:        decoded(l.handleTextDocumentFoldingRange),
+		methods.MethodTextDocumentSelectionRange:      decoded(l.handleTextDocumentSelectionRange),
+		methods.MethodTextDocumentRename:              decoded(l.handleTextDocumentRename),
+		methods.MethodTextDocumentPrepareRename:       decoded(l.handleTextDocumentPrepareRename),
+		methods.MethodTextDocumentWillSaveWaitUntil:   decoded(l.handleTextDocumentWillSaveWaitUntil),
+		methods.MethodTextDocumentFormatting:          decoded(l.handleTextDocumentFormatting),
+		methods.MethodWorkspaceExecuteCommand:         decoded(l.handleWorkspaceExecuteCommand),
+		methods.MethodWorkspaceDidChangeConfiguration: decoded(l.handleWorkspaceDidChangeConfiguration),
+		methods.MethodWorkspaceDidChangeWatchedFiles:  decoded(l.handleWorkspaceDidChangeWatchedFiles),
+	}
+	for method := range ignorableNotifications {
+		registry[method] = ignore
+	}
+	return registry
+}
+
+// registerReplies returns the handlers of the replies to the requests the
+// server sends to the client, by method of the request.
+func (l *lspHandler) registerReplies() map[methods.Method]methodHandler {
+	return map[methods.Method]methodHandler{
+		methods.MethodWorkspaceConfiguration:       l.handleConfigurationReply,
+		methods.MethodWindowWorkDoneProgressCreate: l.handleProgressReply,
+	}
+}
+
+// decoded adapts the handler of a decoded message to a methodHandler.
+func decoded[T rpc.Decodable](
+	handler func(ctx context.Context, request T) (rpc.MethodActor, error),
+) methodHandler {
+	return func(ctx context.Context, msg *rpc.BaseMessage) (rpc.MethodActor, error) {
+		request, err := rpc.Decode[T](msg)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+}
+
+// ignore is the method handler of the messages the server ignores.
+func ignore(context.Context, *rpc.BaseMessage) (rpc.MethodActor, error) {
+	return nil, nil
+}
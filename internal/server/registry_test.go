@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	rpcerrors "github.com/conneroisu/embedpls/internal/rpc/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistry tests that every registered method is reachable through
+// Handle and that unregistered methods still error.
+func TestRegistry(t *testing.T) {
+	params := `{"textDocument":{"uri":"file:///tmp/embedpls/asset.txt","text":""},` +
+		`"contentChanges":[{"text":""}],"position":{"line":0,"character":0},"capabilities":{}}`
+	handler, _ := newTestHandler()
+	for method := range handler.methods {
+		if method == methods.MethodExit {
+			// ends the session, as tested by TestHandleExit
+			continue
+		}
+		t.Run(string(method), func(t *testing.T) {
+			handler, _ := newTestHandler()
+			_, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","id":1,"method":"`+string(method)+`","params":`+params+`}`,
+			))
+			if err != nil && strings.Contains(err.Error(), "unknown method") {
+				t.Errorf("method %s is not reachable: %v", method, err)
+			}
+		})
+	}
+
+	_, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","id":1,"method":"custom/unknown","params":{}}`,
+	))
+	var rpcErr *rpcerrors.Error
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, rpcerrors.CodeMethodNotFound, rpcErr.Code)
+		assert.Equal(t, "unknown method: custom/unknown", rpcErr.Message)
+	}
+}
+
+// TestUnsupportedMethods tests that the methods of the capabilities the
+// server does not advertise have no handler and fail with MethodNotFound,
+// while unknown "$/" notifications are ignored.
+func TestUnsupportedMethods(t *testing.T) {
+	tests := []struct {
+		method       methods.Method
+		notification bool
+		wantErr      bool
+	}{
+		{method: "textDocument/prepareCallHierarchy", wantErr: true},
+		{method: "textDocument/declaration", wantErr: true},
+		{method: methods.MethodTextDocumentDefinition, wantErr: true},
+		{method: "textDocument/semanticTokens/full", wantErr: true},
+		{method: methods.MethodTextDocumentReferences, wantErr: true},
+		{method: methods.MethodTextDocumentDocumentSymbol, wantErr: true},
+		{method: methods.MethodTextDocumentRangeFormatting, wantErr: true},
+		{method: methods.MethodTextDocumentOnTypeFormatting, wantErr: true},
+		{method: methods.MethodTextDocumentCodeLens, wantErr: true},
+		{method: methods.MethodTextDocumentDocumentLink, wantErr: true},
+		{method: methods.MethodWorkspaceSymbol, wantErr: true},
+		{method: "$/custom", wantErr: true},
+		{method: "$/custom", notification: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			handler, _ := newTestHandler()
+			assert.NotContains(t, handler.methods, tt.method)
+			id := `"id":1,`
+			if tt.notification {
+				id = ""
+			}
+			resp, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0",`+id+`"method":"`+string(tt.method)+`","params":{}}`,
+			))
+			assert.Nil(t, resp)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var rpcErr *rpcerrors.Error
+			if assert.ErrorAs(t, err, &rpcErr) {
+				assert.Equal(t, rpcerrors.CodeMethodNotFound, rpcErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// renamablePattern returns the pattern under the position of a document
+// when it can be renamed, its patterns being resolved against dir.
+//
+// Only literal patterns naming a single existing file are renamable, as
+// renaming a glob or a directory has no single file to rename.
+func renamablePattern(
+	fsys resolver.FS,
+	dir string,
+	index *parsers.Index,
+	position protocol.Position,
+) (parsers.Directive, parsers.PatternToken, bool) {
+	directive, pattern, ok := index.PatternAt(position)
+	if !ok || !parsers.IsLiteralPattern(pattern.Glob) {
+		return parsers.Directive{}, parsers.PatternToken{}, false
+	}
+	info, err := fsys.Stat(filepath.Join(
+		dir,
+		filepath.FromSlash(pattern.Glob),
+	))
+	if err != nil || !info.Mode().IsRegular() {
+		return parsers.Directive{}, parsers.PatternToken{}, false
+	}
+	return directive, pattern, true
+}
+
+// patternPath returns the path on the host of the file named by a literal
+// pattern resolved against dir, cleaned.
+func patternPath(dir string, glob string) string {
+	return filepath.Join(dir, filepath.FromSlash(path.Clean(glob)))
+}
+
+// samePath reports whether two cleaned paths name the same file of fsys,
+// whatever their case when it folds case.
+func samePath(fsys resolver.FS, a, b string) bool {
+	if fsys.FoldsCase() {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// handleTextDocumentRename renames the file embedded by the pattern under
+// the cursor and updates every directive of the document naming it.
+func (l *lspHandler) handleTextDocumentRename(
+	_ context.Context,
+	request lsp.RenameRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.RenameResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+	}
+	docURI := request.Params.TextDocument.URI
+	index, ok := l.documentIndex(docURI)
+	if !ok {
+		return nil, fmt.Errorf("document not found")
+	}
+	_, current, ok := renamablePattern(l.fs, uriToDir(docURI), index, request.Params.Position)
+	if !ok {
+		return resp, nil
+	}
+	newName := path.Clean(request.Params.NewName)
+	if !parsers.IsLiteralPattern(newName) {
+		return nil, fmt.Errorf(
+			"cannot rename %q to %q: the new name must not be a pattern",
+			current.Value,
+			newName,
+		)
+	}
+	if path.IsAbs(newName) || newName == ".." ||
+		strings.HasPrefix(newName, "../") {
+		return nil, fmt.Errorf(
+			"cannot rename %q to %q: the new name must be below the package directory",
+			current.Value,
+			newName,
+		)
+	}
+	dir := uriToDir(docURI)
+	edit := protocol.TextDocumentEdit{
+		TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+				URI: docURI,
+			},
+		},
+	}
+	target := patternPath(dir, current.Glob)
+	for _, directive := range index.Directives {
+		for _, pattern := range directive.Patterns {
+			// "./hello.txt" and "hello.txt" name the same file
+			if !parsers.IsLiteralPattern(pattern.Glob) ||
+				!samePath(l.fs, patternPath(dir, pattern.Glob), target) {
+				continue
+			}
+			newText := newName
+			if pattern.All {
+				newText = resolver.AllPrefix + newName
+			}
+			edit.Edits = append(edit.Edits, protocol.TextEdit{
+				Range:   directive.Range(pattern),
+				NewText: parsers.QuotePattern(newText),
+			})
+		}
+	}
+	resp.Result = &lsp.WorkspaceEdit{
+		DocumentChanges: []interface{}{
+			edit,
+			protocol.RenameFile{
+				Kind:   protocol.RenameResourceOperation,
+				OldURI: uri.File(target),
+				NewURI: uri.File(patternPath(dir, newName)),
+			},
+		},
+	}
+	return resp, nil
+}
+
+// handleTextDocumentPrepareRename returns the range of the pattern under the
+// cursor when it is renamable so that clients only edit the file name.
+func (l *lspHandler) handleTextDocumentPrepareRename(
+	_ context.Context,
+	request lsp.PrepareRenameRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.PrepareRenameResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+	}
+	docURI := request.Params.TextDocument.URI
+	index, ok := l.documentIndex(docURI)
+	if !ok {
+		return nil, fmt.Errorf("document not found")
+	}
+	directive, pattern, ok := renamablePattern(
+		l.fs,
+		uriToDir(docURI),
+		index,
+		request.Params.Position,
+	)
+	if !ok {
+		return resp, nil
+	}
+	patternRange := directive.Range(pattern)
+	resp.Result = &patternRange
+	return resp, nil
+}
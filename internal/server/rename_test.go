@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestRename tests that renaming an embedded file renames the file and
+// updates every directive naming it.
+func TestRename(t *testing.T) {
+	source := "package main\n\n" +
+		"//go:embed hello.txt\n" +
+		"var hello string\n\n" +
+		"//go:embed hello.txt *.md\n" +
+		"var files embed.FS\n\n" +
+		"//go:embed ./hello.txt\n" +
+		"var dotted []byte\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":   source,
+		"hello.txt": "hello",
+	})
+	handler, _ := newTestHandler()
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, source)
+	rename := func(position protocol.Position, newName string) (*lsp.WorkspaceEdit, error) {
+		resp, err := handler.handleTextDocumentRename(context.Background(), lsp.RenameRequest{
+			Params: protocol.RenameParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+					Position:     position,
+				},
+				NewName: newName,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.(lsp.RenameResponse).Result, nil
+	}
+
+	edit, err := rename(protocol.Position{Line: 2, Character: 13}, "greeting.txt")
+	assert.NoError(t, err)
+	if assert.NotNil(t, edit) && assert.Len(t, edit.DocumentChanges, 2) {
+		textEdit := edit.DocumentChanges[0].(protocol.TextDocumentEdit)
+		assert.Equal(t, docURI, textEdit.TextDocument.URI)
+		assert.Equal(t, []protocol.TextEdit{
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 11},
+					End:   protocol.Position{Line: 2, Character: 20},
+				},
+				NewText: "greeting.txt",
+			},
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 5, Character: 11},
+					End:   protocol.Position{Line: 5, Character: 20},
+				},
+				NewText: "greeting.txt",
+			},
+			{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 8, Character: 11},
+					End:   protocol.Position{Line: 8, Character: 22},
+				},
+				NewText: "greeting.txt",
+			},
+		}, textEdit.Edits, "patterns naming the same file are all renamed")
+		assert.Equal(t, protocol.RenameFile{
+			Kind:   protocol.RenameResourceOperation,
+			OldURI: uri.File(filepath.Join(dir, "hello.txt")),
+			NewURI: uri.File(filepath.Join(dir, "greeting.txt")),
+		}, edit.DocumentChanges[1])
+	}
+
+	edit, err = rename(protocol.Position{Line: 8, Character: 14}, "greeting.txt")
+	assert.NoError(t, err)
+	if assert.NotNil(t, edit) && assert.Len(t, edit.DocumentChanges, 2) {
+		assert.Len(t, edit.DocumentChanges[0].(protocol.TextDocumentEdit).Edits, 3)
+		assert.Equal(t, uri.File(filepath.Join(dir, "hello.txt")),
+			edit.DocumentChanges[1].(protocol.RenameFile).OldURI)
+	}
+
+	edit, err = rename(protocol.Position{Line: 5, Character: 23}, "all.md")
+	assert.NoError(t, err)
+	assert.Nil(t, edit, "glob patterns are not renamable")
+
+	_, err = rename(protocol.Position{Line: 2, Character: 13}, "*.txt")
+	assert.Error(t, err)
+	_, err = rename(protocol.Position{Line: 2, Character: 13}, "../hello.txt")
+	assert.Error(t, err)
+}
+
+// TestPrepareRename tests that prepareRename returns the range of a
+// renamable pattern and nil elsewhere.
+func TestPrepareRename(t *testing.T) {
+	source := "package main\n\n" +
+		"//go:embed hello.txt *.md\n" +
+		"var files embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":   source,
+		"hello.txt": "hello",
+	})
+	handler, _ := newTestHandler()
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, source)
+	tests := []struct {
+		name     string
+		position protocol.Position
+		want     *protocol.Range
+	}{
+		{
+			name:     "literal pattern",
+			position: protocol.Position{Line: 2, Character: 14},
+			want: &protocol.Range{
+				Start: protocol.Position{Line: 2, Character: 11},
+				End:   protocol.Position{Line: 2, Character: 20},
+			},
+		},
+		{
+			name:     "glob pattern",
+			position: protocol.Position{Line: 2, Character: 22},
+		},
+		{
+			name:     "directive keyword",
+			position: protocol.Position{Line: 2, Character: 4},
+		},
+		{
+			name:     "not a directive",
+			position: protocol.Position{Line: 3, Character: 6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.handleTextDocumentPrepareRename(
+				context.Background(),
+				lsp.PrepareRenameRequest{
+					Params: protocol.PrepareRenameParams{
+						TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+							TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+							Position:     tt.position,
+						},
+					},
+				},
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, resp.(lsp.PrepareRenameResponse).Result)
+		})
+	}
+}
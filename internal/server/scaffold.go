@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// scaffold returns the edit inserting, above the line of a position of a
+// document, an embed.FS variable embedding a directory of the package,
+// along with the import of the embed package when it is missing.
+//
+// The arguments are the URI of the document, the position and the
+// slash-separated path of the directory relative to the document.
+func (l *lspHandler) scaffold(arguments []interface{}) (*lsp.WorkspaceEdit, error) {
+	if len(arguments) != 3 {
+		return nil, fmt.Errorf(
+			"%s expects 3 arguments, got %d",
+			lsp.CommandScaffold,
+			len(arguments),
+		)
+	}
+	document, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s expects a document URI, got %T",
+			lsp.CommandScaffold,
+			arguments[0],
+		)
+	}
+	// the position comes decoded as a generic JSON value
+	raw, err := json.Marshal(arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode position: %w", err)
+	}
+	var position protocol.Position
+	if err := json.Unmarshal(raw, &position); err != nil {
+		return nil, fmt.Errorf("%s expects a position: %w", lsp.CommandScaffold, err)
+	}
+	dir, ok := arguments[2].(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s expects a directory, got %T",
+			lsp.CommandScaffold,
+			arguments[2],
+		)
+	}
+	docURI := uri.URI(document)
+	source, ok := l.documents.Get(docURI)
+	if !ok {
+		return nil, fmt.Errorf("document not found")
+	}
+	dir = path.Clean(dir)
+	if dir == "." || !isPackagePath(dir) {
+		return nil, fmt.Errorf("%q is not a directory below the package directory", dir)
+	}
+	info, err := l.fs.Stat(filepath.Join(uriToDir(docURI), filepath.FromSlash(dir)))
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", dir)
+	}
+	qualifier, importEdit, err := embedImport(*source)
+	if err != nil {
+		return nil, err
+	}
+	typeName := "FS"
+	if qualifier != "" {
+		typeName = qualifier + ".FS"
+	}
+	edit := protocol.TextDocumentEdit{
+		TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: docURI},
+		},
+	}
+	if importEdit != nil {
+		edit.Edits = append(edit.Edits, *importEdit)
+	}
+	line := protocol.Position{Line: position.Line}
+	edit.Edits = append(edit.Edits, protocol.TextEdit{
+		Range: protocol.Range{Start: line, End: line},
+		NewText: fmt.Sprintf(
+			"//go:embed %s\nvar %s %s\n",
+			parsers.QuotePattern(dir),
+			embedVarName(dir),
+			typeName,
+		),
+	})
+	return &lsp.WorkspaceEdit{DocumentChanges: []interface{}{edit}}, nil
+}
+
+// embedImport returns the name the embed package is referred to by in a
+// Go source, along with the edit importing it when the source does not.
+//
+// A blank import, as used by the directives embedding into strings, is
+// replaced with a plain one, and a dot import refers to the package by an
+// empty name.
+func embedImport(source string) (string, *protocol.TextEdit, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ImportsOnly)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse imports: %w", err)
+	}
+	lines := parsers.NewLineIndex(source)
+	position := func(pos token.Pos) protocol.Position {
+		return lines.Position(fset.Position(pos).Offset)
+	}
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath != "embed" {
+			continue
+		}
+		switch {
+		case spec.Name == nil:
+			return "embed", nil, nil
+		case spec.Name.Name == "_":
+			return "embed", &protocol.TextEdit{
+				Range: protocol.Range{
+					Start: position(spec.Name.Pos()),
+					End:   position(spec.Path.End()),
+				},
+				NewText: `"embed"`,
+			}, nil
+		case spec.Name.Name == ".":
+			return "", nil, nil
+		default:
+			return spec.Name.Name, nil, nil
+		}
+	}
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	switch {
+	case last == nil:
+		at := protocol.Position{Line: position(file.Name.End()).Line + 1}
+		return "embed", &protocol.TextEdit{
+			Range:   protocol.Range{Start: at, End: at},
+			NewText: "\nimport \"embed\"\n",
+		}, nil
+	case last.Lparen.IsValid():
+		at := position(last.Lparen + 1)
+		return "embed", &protocol.TextEdit{
+			Range:   protocol.Range{Start: at, End: at},
+			NewText: "\n\t\"embed\"",
+		}, nil
+	default:
+		at := protocol.Position{Line: position(last.End()).Line + 1}
+		return "embed", &protocol.TextEdit{
+			Range:   protocol.Range{Start: at, End: at},
+			NewText: "import \"embed\"\n",
+		}, nil
+	}
+}
+
+// embedVarName returns the name of a variable embedding a directory, the
+// last element of its path in lower camel case, such as "webAssets" for
+// "static/web-assets".
+func embedVarName(dir string) string {
+	words := strings.FieldsFunc(path.Base(dir), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for i, word := range words {
+		runes := []rune(word)
+		if i == 0 {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	switch {
+	case name == "":
+		return "files"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "files" + name
+	case token.IsKeyword(name):
+		return name + "FS"
+	}
+	return name
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// applyTestEdits returns the text with the edits applied, which must not
+// overlap.
+func applyTestEdits(text string, edits []protocol.TextEdit) string {
+	sorted := append([]protocol.TextEdit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Range.Start, sorted[j].Range.Start
+		return a.Line > b.Line || a.Line == b.Line && a.Character > b.Character
+	})
+	for _, edit := range sorted {
+		text = parsers.ApplyChange(text, edit.Range, edit.NewText)
+	}
+	return text
+}
+
+// TestScaffold tests that scaffolding inserts the variable embedding a
+// directory above the line of the cursor, importing the embed package
+// whatever the imports of the document.
+func TestScaffold(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"web-assets/index.html": "<h1>hi</h1>",
+	})
+	tests := []struct {
+		name   string
+		source string
+		line   uint32
+		want   string
+	}{
+		{
+			name:   "no imports",
+			source: "package main\n\nfunc main() {}\n",
+			line:   2,
+			want: "package main\n\nimport \"embed\"\n\n" +
+				"//go:embed web-assets\nvar webAssets embed.FS\nfunc main() {}\n",
+		},
+		{
+			name:   "grouped imports",
+			source: "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println() }\n",
+			line:   6,
+			want: "package main\n\nimport (\n\t\"embed\"\n\t\"fmt\"\n)\n\n" +
+				"//go:embed web-assets\nvar webAssets embed.FS\nfunc main() { fmt.Println() }\n",
+		},
+		{
+			name:   "single import",
+			source: "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+			line:   4,
+			want: "package main\n\nimport \"fmt\"\nimport \"embed\"\n\n" +
+				"//go:embed web-assets\nvar webAssets embed.FS\nfunc main() { fmt.Println() }\n",
+		},
+		{
+			name:   "blank import",
+			source: "package main\n\nimport _ \"embed\"\n\n",
+			line:   4,
+			want:   "package main\n\nimport \"embed\"\n\n//go:embed web-assets\nvar webAssets embed.FS\n",
+		},
+		{
+			name:   "named import",
+			source: "package main\n\nimport e \"embed\"\n\n",
+			line:   4,
+			want:   "package main\n\nimport e \"embed\"\n\n//go:embed web-assets\nvar webAssets e.FS\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler()
+			docURI := uri.File(filepath.Join(dir, "main.go"))
+			handler.documents.Set(docURI, tt.source)
+			resp, err := handler.handleWorkspaceExecuteCommand(
+				context.Background(),
+				lsp.ExecuteCommandRequest{
+					Params: protocol.ExecuteCommandParams{
+						Command: lsp.CommandScaffold,
+						Arguments: []interface{}{
+							string(docURI),
+							map[string]interface{}{"line": tt.line, "character": 3},
+							"web-assets",
+						},
+					},
+				},
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			edit := resp.(lsp.ExecuteCommandResponse).Result.(*lsp.WorkspaceEdit)
+			if assert.Len(t, edit.DocumentChanges, 1) {
+				change := edit.DocumentChanges[0].(protocol.TextDocumentEdit)
+				assert.Equal(t, docURI, change.TextDocument.URI)
+				assert.Equal(t, tt.want, applyTestEdits(tt.source, change.Edits))
+			}
+		})
+	}
+}
+
+// TestScaffoldInvalidDirectory tests that only existing directories below
+// the package directory are scaffolded.
+func TestScaffoldInvalidDirectory(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"hello.txt": "hello",
+	})
+	handler, _ := newTestHandler()
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler.documents.Set(docURI, "package main\n")
+	for _, target := range []string{"missing", "hello.txt", "../", "."} {
+		_, err := handler.scaffold([]interface{}{
+			string(docURI),
+			protocol.Position{Line: 1},
+			target,
+		})
+		assert.Error(t, err, target)
+	}
+}
+
+// TestEmbedVarName tests naming the variables embedding directories.
+func TestEmbedVarName(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: "static", want: "static"},
+		{dir: "web/templates", want: "templates"},
+		{dir: "static/web-assets", want: "webAssets"},
+		{dir: "My_Files", want: "myFiles"},
+		{dir: "2024", want: "files2024"},
+		{dir: "go", want: "goFS"},
+		{dir: "---", want: "files"},
+	}
+	for _, tt := range tests {
+		if got := embedVarName(tt.dir); got != tt.want {
+			t.Errorf("embedVarName(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
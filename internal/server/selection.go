@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+// handleTextDocumentSelectionRange expands the selection at each requested
+// position from the pattern under it, to its directive, to the directives
+// and the declaration of the variable they embed files into.
+func (l *lspHandler) handleTextDocumentSelectionRange(
+	_ context.Context,
+	request lsp.SelectionRangeRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.SelectionRangeResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+		Result: []lsp.SelectionRange{},
+	}
+	index, ok := l.documentIndex(request.Params.TextDocument.URI)
+	for _, position := range request.Params.Positions {
+		if !ok {
+			resp.Result = append(resp.Result, emptySelection(position))
+			continue
+		}
+		resp.Result = append(resp.Result, selectionAt(index, position))
+	}
+	return resp, nil
+}
+
+// selectionAt returns the innermost selection range of an index at a
+// position, linked to the ranges containing it.
+//
+// Positions outside of any directive or embedding variable get an empty
+// range, as the specification requires a range containing each position.
+func selectionAt(index *parsers.Index, position protocol.Position) lsp.SelectionRange {
+	ranges := make([]protocol.Range, 0, 3)
+	if directive, ok := index.DirectiveAt(position); ok {
+		if _, pattern, ok := index.PatternAt(position); ok {
+			ranges = append(ranges, pattern.Range)
+		}
+		ranges = append(ranges, index.DirectiveRange(directive))
+		if block, ok := index.EmbedBlockOf(directive); ok {
+			ranges = append(ranges, index.BlockRange(block))
+		}
+	} else if block, ok := index.EmbedBlockAt(position); ok {
+		ranges = append(ranges, index.VarRange(block), index.BlockRange(block))
+	}
+	if len(ranges) == 0 {
+		return emptySelection(position)
+	}
+	var selection *lsp.SelectionRange
+	for i := len(ranges) - 1; i >= 0; i-- {
+		selection = &lsp.SelectionRange{Range: ranges[i], Parent: selection}
+	}
+	return *selection
+}
+
+// emptySelection returns the empty selection range at a position.
+func emptySelection(position protocol.Position) lsp.SelectionRange {
+	return lsp.SelectionRange{
+		Range: protocol.Range{Start: position, End: position},
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestSelectionRange tests that the selection expands from the pattern
+// under the cursor, to its directive, to the block of its variable.
+func TestSelectionRange(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n"+
+		"var (\n"+
+		"\t//go:embed a.txt  \"b c.txt\"\n"+
+		"\t//go:embed static/*\n"+
+		"\tfiles embed.FS\n"+
+		")\n")
+	rng := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+	block := &lsp.SelectionRange{Range: rng(3, 1, 5, 15)}
+	tests := []struct {
+		name     string
+		position protocol.Position
+		want     lsp.SelectionRange
+	}{
+		{
+			name:     "quoted pattern",
+			position: protocol.Position{Line: 3, Character: 22},
+			want: lsp.SelectionRange{
+				Range: rng(3, 19, 3, 28),
+				Parent: &lsp.SelectionRange{
+					Range:  rng(3, 1, 3, 28),
+					Parent: block,
+				},
+			},
+		},
+		{
+			name:     "between patterns",
+			position: protocol.Position{Line: 3, Character: 18},
+			want: lsp.SelectionRange{
+				Range:  rng(3, 1, 3, 28),
+				Parent: block,
+			},
+		},
+		{
+			name:     "variable",
+			position: protocol.Position{Line: 5, Character: 3},
+			want: lsp.SelectionRange{
+				Range:  rng(5, 1, 5, 6),
+				Parent: block,
+			},
+		},
+		{
+			name:     "outside",
+			position: protocol.Position{Line: 0, Character: 3},
+			want:     lsp.SelectionRange{Range: rng(0, 3, 0, 3)},
+		},
+	}
+	positions := make([]protocol.Position, 0, len(tests))
+	for _, tt := range tests {
+		positions = append(positions, tt.position)
+	}
+	resp, err := handler.handleTextDocumentSelectionRange(
+		context.Background(),
+		lsp.SelectionRangeRequest{
+			Params: lsp.SelectionRangeParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+				Positions:    positions,
+			},
+		},
+	)
+	assert.NoError(t, err)
+	result := resp.(lsp.SelectionRangeResponse).Result
+	if !assert.Len(t, result, len(tests)) {
+		return
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, result[i])
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+)
+
+const (
+	// directiveSignature is the signature shown for go:embed directives.
+	directiveSignature = "//go:embed pattern..."
+	// directiveDocumentation documents the go:embed directive.
+	directiveDocumentation = "Embeds the files matching the patterns into the " +
+		"variable declared on the next line, which must be a string, a []byte " +
+		"or an embed.FS. Only an embed.FS can hold more than one file."
+	// patternDocumentation documents the grammar of go:embed patterns.
+	patternDocumentation = "Space-separated path.Match patterns, relative to " +
+		"the package directory, that may be double quoted or back quoted. " +
+		"Patterns must not be absolute, contain '.' or '..' elements or " +
+		"match files outside of the module.\n\n" +
+		"A matched directory embeds all the files below it, except those " +
+		"whose name begins with '.' or '_'. The `all:` prefix, as in " +
+		"`all:static`, includes them too."
+)
+
+// handleTextDocumentSignatureHelp documents the go:embed directive when the
+// cursor is in the arguments of one.
+func (l *lspHandler) handleTextDocumentSignatureHelp(
+	_ context.Context,
+	request lsp.SignatureHelpRequest,
+) (rpc.MethodActor, error) {
+	resp := lsp.SignatureHelpResponse{
+		Response: lsp.Response{
+			RPC: lsp.RPCVersion,
+			ID:  request.ID,
+		},
+	}
+	index, ok := l.documentIndex(request.Params.TextDocument.URI)
+	if !ok {
+		return nil, fmt.Errorf("document not found")
+	}
+	if _, ok := index.DirectiveAt(request.Params.Position); !ok {
+		return resp, nil
+	}
+	resp.Result = &protocol.SignatureHelp{
+		Signatures: []protocol.SignatureInformation{
+			{
+				Label: directiveSignature,
+				Documentation: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: directiveDocumentation,
+				},
+				Parameters: []protocol.ParameterInformation{
+					{
+						Label: "pattern...",
+						Documentation: protocol.MarkupContent{
+							Kind:  protocol.Markdown,
+							Value: patternDocumentation,
+						},
+					},
+				},
+			},
+		},
+	}
+	return resp, nil
+}
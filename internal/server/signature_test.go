@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+// TestSignatureHelp tests that signature help is only returned inside the
+// arguments of a go:embed directive.
+func TestSignatureHelp(t *testing.T) {
+	handler, _ := newTestHandler()
+	docURI := uri.File("/tmp/main.go")
+	handler.documents.Set(docURI, "package main\n\n"+
+		"//go:embed \n"+
+		"var content embed.FS\n\n"+
+		"// a comment\n")
+	tests := []struct {
+		name     string
+		position protocol.Position
+		want     bool
+	}{
+		{name: "after go:embed", position: protocol.Position{Line: 2, Character: 11}, want: true},
+		{name: "on go:embed", position: protocol.Position{Line: 2, Character: 4}},
+		{name: "declaration", position: protocol.Position{Line: 3, Character: 6}},
+		{name: "comment", position: protocol.Position{Line: 5, Character: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.handleTextDocumentSignatureHelp(context.Background(), lsp.SignatureHelpRequest{
+				Params: protocol.SignatureHelpParams{
+					TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+						TextDocument: protocol.TextDocumentIdentifier{URI: docURI},
+						Position:     tt.position,
+					},
+				},
+			})
+			assert.NoError(t, err)
+			result := resp.(lsp.SignatureHelpResponse).Result
+			if !tt.want {
+				assert.Nil(t, result)
+				return
+			}
+			if assert.NotNil(t, result) && assert.Len(t, result.Signatures, 1) {
+				assert.Equal(t, directiveSignature, result.Signatures[0].Label)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"github.com/charmbracelet/log"
+	"go.lsp.dev/protocol"
+)
+
+// traceMessages is the trace value for logging messages only.
+//
+// The specification spells it "messages" while protocol.TraceMessage is
+// "message", so both are accepted.
+const traceMessages protocol.TraceValue = "messages"
+
+// setTrace maps a trace value sent by the client to the verbosity of the
+// logger of the session.
+//
+// Unknown values are ignored.
+func (l *lspHandler) setTrace(value protocol.TraceValue) {
+	switch value {
+	case protocol.TraceOff:
+		l.logger.SetLevel(log.WarnLevel)
+	case traceMessages, protocol.TraceMessage:
+		l.logger.SetLevel(log.InfoLevel)
+	case protocol.TraceVerbose:
+		l.logger.SetLevel(log.DebugLevel)
+	default:
+		l.logger.Warnf("unknown trace value: %s", value)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHandleSetTrace tests that $/setTrace sets the verbosity of the logger
+// of the session, leaving the default logger as it is, without an unknown
+// method error.
+func TestHandleSetTrace(t *testing.T) {
+	level := log.GetLevel()
+	tests := []struct {
+		value string
+		want  log.Level
+	}{
+		{value: "off", want: log.WarnLevel},
+		{value: "messages", want: log.InfoLevel},
+		{value: "verbose", want: log.DebugLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			handler, out := newTestHandler()
+			resp, err := handler.Handle(context.Background(), newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","method":"$/setTrace","params":{"value":"`+tt.value+`"}}`,
+			))
+			assert.NoError(t, err)
+			assert.Nil(t, resp)
+			assert.Empty(t, out.String())
+			assert.Equal(t, tt.want, handler.logger.GetLevel())
+			assert.Equal(t, level, log.GetLevel())
+		})
+	}
+}
+
+// TestHandleLogTrace tests that $/logTrace is acknowledged silently.
+func TestHandleLogTrace(t *testing.T) {
+	handler, out := newTestHandler()
+	resp, err := handler.Handle(context.Background(), newTestMessage(
+		t,
+		`{"jsonrpc":"2.0","method":"$/logTrace","params":{"message":"hello"}}`,
+	))
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	assert.Empty(t, out.String())
+}
@@ -1,14 +1,15 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
-	"github.com/charmbracelet/log"
 	"github.com/conneroisu/embedpls/internal/lsp"
 	"github.com/conneroisu/embedpls/internal/parsers"
+	"github.com/conneroisu/embedpls/internal/resolver"
 	"go.lsp.dev/uri"
 )
 
@@ -17,90 +18,245 @@ type embeddableResp struct {
 }
 type embeddable struct {
 	name string
-	data []byte
+	// path is the file path of the entry, from which its completion item
+	// is resolved.
+	path  string
+	isDir bool
 }
 
-func getEmbbeddables(
+// getEmbbeddables lists the files and directories that can complete the
+// pattern typed so far.
+//
+// The entries of the directory of the typed pattern, such as "static/" in
+// "static/ap", are listed, named relative to the directory of the source
+// file. Directories outside of the package directory list nothing as they
+// cannot be embedded.
+//
+// Listing stops with an error once ctx is cancelled, as the directory may
+// be large.
+func (l *lspHandler) getEmbbeddables(
+	ctx context.Context,
 	uri uri.URI,
 	curVal string,
 	errCh chan<- error,
 ) <-chan embeddableResp {
-	respCh := make(chan embeddableResp)
-	dir := filepath.Dir(uri.Filename())
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		errCh <- fmt.Errorf("error reading directory: %w", err)
-	}
-	embeddables := make([]embeddable, 0)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	respCh := make(chan embeddableResp, 1)
+	go func() {
+		prefix := ""
+		if i := strings.LastIndex(curVal, "/"); i >= 0 {
+			prefix = curVal[:i+1]
+		}
+		embeddables := make([]embeddable, 0)
+		if !isPackagePath(prefix) {
+			respCh <- embeddableResp{embeddables: embeddables}
+			return
 		}
-		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		dir := filepath.Join(l.embedDir(uri), filepath.FromSlash(prefix))
+		entries, err := l.fs.ReadDir(dir)
 		if err != nil {
-			errCh <- fmt.Errorf("error reading file: %w", err)
+			errCh <- fmt.Errorf("error reading directory: %w", err)
+			return
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				errCh <- fmt.Errorf("context cancelled: %w", err)
+				return
+			}
+			if l.unlisted(filepath.Join(dir, entry.Name()), entry.IsDir()) {
+				continue
+			}
+			if entry.IsDir() {
+				embeddables = append(embeddables, embeddable{
+					name:  prefix + entry.Name() + "/",
+					path:  filepath.Join(dir, entry.Name()),
+					isDir: true,
+				})
+				continue
+			}
+			embeddables = append(embeddables, embeddable{
+				name: prefix + entry.Name(),
+				path: filepath.Join(dir, entry.Name()),
+			})
+		}
+		respCh <- embeddableResp{
+			embeddables: embeddables,
 		}
-		embeddables = append(embeddables, embeddable{
-			name: entry.Name(),
-			data: data,
-		})
+	}()
+	return respCh
+}
+
+// isPackagePath reports whether a slash-separated path relative to a
+// package directory stays within it.
+func isPackagePath(name string) bool {
+	if path.IsAbs(name) {
+		return false
 	}
-	respCh <- embeddableResp{
-		embeddables: embeddables,
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." {
+			return false
+		}
 	}
-	return respCh
+	return true
 }
 
-func (l *lspHandler) getHoverResp(req lsp.HoverRequest, errCh chan<- error) <-chan lsp.HoverResult {
-	respCh := make(chan lsp.HoverResult)
+// getHoverResp returns the hover of a position of a document, nil when
+// the position holds nothing to hover.
+func (l *lspHandler) getHoverResp(
+	ctx context.Context,
+	req lsp.HoverRequest,
+	errCh chan<- error,
+) <-chan *lsp.HoverResult {
+	respCh := make(chan *lsp.HoverResult, 1)
 	go func() {
 		doc, ok := l.documents.Get(req.Params.TextDocument.URI)
 		if !ok {
 			errCh <- fmt.Errorf("document not found")
 			return
 		}
+		index, _ := l.documentIndex(req.Params.TextDocument.URI)
+		block, ok := index.EmbedBlockAt(req.Params.Position)
+		if ok {
+			respCh <- &lsp.HoverResult{
+				Contents: l.embedBlockHover(ctx, req.Params.TextDocument.URI, block),
+			}
+			return
+		}
 		curVal, state, err := parsers.ParseSourcePosition(
 			doc,
 			req.Params.Position,
 		)
 		if err != nil {
 			errCh <- err
+			return
 		}
 		if state == parsers.StateUnknown {
-			errCh <- nil
+			// positions away from any pattern have no hover, which is
+			// answered with null
+			respCh <- nil
 			return
 		}
-		content, err := relativeReadFile(req.Params.TextDocument.URI, curVal)
+		access := l.virtualPathHover(ctx, req.Params.TextDocument.URI, index, req.Params.Position)
+		if tree, ok := l.directoryHover(ctx, req.Params.TextDocument.URI, curVal); ok {
+			if access != "" {
+				tree += "\n" + access
+			}
+			respCh <- &lsp.HoverResult{Contents: l.markup(tree)}
+			return
+		}
+		glob, _ := resolver.SplitAllPrefix(curVal)
+		head, size, err := relativeReadFile(
+			ctx,
+			l.files,
+			l.embedDir(req.Params.TextDocument.URI),
+			glob,
+			hoverSize,
+		)
 		if err != nil {
+			if access != "" {
+				respCh <- &lsp.HoverResult{Contents: l.markup(access)}
+				return
+			}
 			errCh <- err
 			return
 		}
-		respCh <- lsp.HoverResult{
-			Contents: content,
+		text, truncated := headText(head, size)
+		contents := l.codeBlock(strings.TrimPrefix(filepath.Ext(glob), "."), text)
+		if truncated {
+			contents += fmt.Sprintf("\n… %s in total\n", formatSize(size))
+		}
+		if access != "" {
+			contents += "\n" + access
 		}
+		respCh <- &lsp.HoverResult{Contents: l.markup(contents)}
 	}()
 	return respCh
 }
 
-func relativeReadFile(uri uri.URI, embedPath string) (string, error) {
-	dir := filepath.Dir(uri.Filename())
-	entries, err := os.ReadDir(dir)
+// relativeReadFile reads from fsys the start of the file named by an
+// embedding path relative to the directory the patterns of a document are
+// resolved against, up to limit bytes, and returns it with the size of the
+// whole file.
+//
+// The path is resolved by the rules of go:embed, so it must embed exactly
+// one file, and by the rules of fsys: a case-insensitive file system finds
+// the path whatever its case. Files within the limit are read whole, so
+// that fsys may cache them.
+func relativeReadFile(
+	ctx context.Context,
+	fsys resolver.FS,
+	dir string,
+	embedPath string,
+	limit int,
+) (string, int64, error) {
+	resolution, err := resolver.Inspect(ctx, fsys, dir, embedPath)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", 0, fmt.Errorf("context cancelled: %w", ctxErr)
+	}
+	if err != nil || len(resolution.Files) == 0 {
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean(embedPath)))
+		return "", 0, fmt.Errorf(
+			"file not found: %s in %s (%s)",
+			path.Clean(embedPath),
+			filepath.Dir(target),
+			exampleEntries(fsys, filepath.Dir(target)),
+		)
+	}
+	if len(resolution.Files) > 1 {
+		return "", 0, fmt.Errorf(
+			"%s embeds %d files, not a single one",
+			embedPath,
+			len(resolution.Files),
+		)
+	}
+	filename := filepath.Join(dir, filepath.FromSlash(resolution.Files[0]))
+	info, err := fsys.Stat(filename)
 	if err != nil {
-		return "", fmt.Errorf("error reading directory: %w", err)
+		return "", 0, fmt.Errorf("error reading file: %w", err)
+	}
+	if info.Size() > int64(limit) {
+		head, size, err := readHead(fsys, filename, limit)
+		if err != nil {
+			return "", 0, fmt.Errorf("error reading file: %w", err)
+		}
+		return string(head), size, nil
 	}
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading file: %w", err)
+	}
+	return string(data), int64(len(data)), nil
+}
+
+const (
+	// maxExampleEntries bounds the number of entries listed in the errors
+	// of the files not found in a directory.
+	maxExampleEntries = 3
+)
+
+// exampleEntries describes the first entries of a directory of fsys,
+// hinting at what a path not found in it may have meant to name.
+func exampleEntries(fsys resolver.FS, dir string) string {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return "no such directory"
+	}
+	if len(entries) == 0 {
+		return "empty directory"
+	}
+	names := make([]string, 0, maxExampleEntries)
 	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+		if len(names) == maxExampleEntries {
+			break
 		}
-		if strings.HasSuffix(entry.Name(), embedPath) {
-			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
-			if err != nil {
-				return "", fmt.Errorf("error reading file: %w", err)
-			}
-			log.Debugf("found file: %s", entry.Name())
-			log.Debugf("file content: %s", string(data))
-			return string(data), nil
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
 		}
+		names = append(names, name)
+	}
+	listed := "has " + strings.Join(names, ", ")
+	if more := len(entries) - len(names); more > 0 {
+		listed += fmt.Sprintf(" and %d more", more)
 	}
-	return "", fmt.Errorf("file not found")
+	return listed
 }
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/conneroisu/embedpls/internal/config"
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+)
+
+const (
+	// fileWatchersID is the id of the registration of the file watchers.
+	fileWatchersID = "embedpls-watched-files"
+)
+
+// registerFileWatchers asks the client to notify the server when files of
+// the workspace are created or deleted, as either can change the files
+// matched by the directives of the open documents, and when configuration
+// files are changed.
+func (l *lspHandler) registerFileWatchers(ctx context.Context) error {
+	_, err := l.writer.WriteRequest(
+		ctx,
+		methods.MethodClientRegisterCapability,
+		protocol.RegistrationParams{
+			Registrations: []protocol.Registration{{
+				ID:     fileWatchersID,
+				Method: string(methods.MethodWorkspaceDidChangeWatchedFiles),
+				RegisterOptions: protocol.DidChangeWatchedFilesRegistrationOptions{
+					Watchers: []protocol.FileSystemWatcher{
+						{
+							GlobPattern: "**/*",
+							Kind:        protocol.WatchKindCreate + protocol.WatchKindDelete,
+						},
+						{
+							GlobPattern: "**/{" + strings.Join(config.Files, ",") + "}",
+							Kind: protocol.WatchKindCreate +
+								protocol.WatchKindChange +
+								protocol.WatchKindDelete,
+						},
+					},
+				},
+			}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register file watchers: %w", err)
+	}
+	return nil
+}
+
+// handleWorkspaceDidChangeWatchedFiles publishes the diagnostics of the
+// open documents again when watched files are created or deleted,
+// reloading the configuration first when a configuration file of the
+// workspace root changed.
+func (l *lspHandler) handleWorkspaceDidChangeWatchedFiles(
+	ctx context.Context,
+	request lsp.DidChangeWatchedFilesNotification,
+) (rpc.MethodActor, error) {
+	if len(request.Params.Changes) == 0 {
+		return nil, nil
+	}
+	for _, change := range request.Params.Changes {
+		if !l.isConfigFile(change.URI) {
+			continue
+		}
+		cfg, err := l.loadConfig(l.settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload configuration: %w", err)
+		}
+		l.setConfig(cfg)
+		break
+	}
+	return nil, l.republishDiagnostics(ctx)
+}
+
+// isConfigFile reports whether a file is one of the configuration files
+// looked up in the workspace root.
+func (l *lspHandler) isConfigFile(file uri.URI) bool {
+	if l.root == "" {
+		return false
+	}
+	filename := uriToPath(file)
+	return filepath.Dir(filename) == l.root &&
+		slices.Contains(config.Files, filepath.Base(filename))
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.lsp.dev/uri"
+)
+
+// TestRegisterFileWatchers tests that file watchers are registered once
+// the client is initialized only when it supports dynamic registration.
+func TestRegisterFileWatchers(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		want         bool
+	}{
+		{
+			name:         "dynamic registration",
+			capabilities: `{"workspace":{"didChangeWatchedFiles":{"dynamicRegistration":true}}}`,
+			want:         true,
+		},
+		{
+			name:         "static registration",
+			capabilities: `{"workspace":{"didChangeWatchedFiles":{}}}`,
+		},
+		{
+			name:         "no capabilities",
+			capabilities: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, out := newTestHandler()
+			ctx := context.Background()
+			_, err := handler.Handle(ctx, newTestMessage(t,
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":`+
+					tt.capabilities+`}}`,
+			))
+			assert.NoError(t, err)
+			_, err = handler.Handle(ctx, newTestMessage(
+				t,
+				`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+			))
+			assert.NoError(t, err)
+			messages := readTestMessages(t, out)
+			if !tt.want {
+				assert.Empty(t, messages)
+				return
+			}
+			if !assert.Len(t, messages, 1) {
+				return
+			}
+			assert.Equal(t, "client/registerCapability", messages[0]["method"])
+			assert.Equal(t, map[string]interface{}{
+				"registrations": []interface{}{map[string]interface{}{
+					"id":     "embedpls-watched-files",
+					"method": "workspace/didChangeWatchedFiles",
+					"registerOptions": map[string]interface{}{
+						"watchers": []interface{}{
+							map[string]interface{}{
+								"globPattern": "**/*",
+								"kind":        float64(5),
+							},
+							map[string]interface{}{
+								"globPattern": "**/{.embedpls.json,.embedpls.yaml,.embedpls.yml}",
+								"kind":        float64(7),
+							},
+						},
+					},
+				}},
+			}, messages[0]["params"])
+
+			resp, err := handler.Handle(ctx, newTestMessage(t, fmt.Sprintf(
+				`{"jsonrpc":"2.0","id":%v,"result":null}`,
+				messages[0]["id"],
+			)))
+			assert.NoError(t, err)
+			assert.Nil(t, resp)
+		})
+	}
+}
+
+// TestDidChangeWatchedFiles tests that creating a file changing what a
+// directive embeds publishes the diagnostics of the open documents again.
+func TestDidChangeWatchedFiles(t *testing.T) {
+	source := "package main\n\n//go:embed static\nvar f embed.FS\n"
+	dir := writeTestFiles(t, map[string]string{
+		"main.go":           source,
+		"static/app.js":     "app",
+		"static/sub/lib.js": "lib",
+	})
+	docURI := uri.File(filepath.Join(dir, "main.go"))
+	handler, out := newTestHandler()
+	ctx := context.Background()
+
+	params, err := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": docURI, "version": 1, "text": source},
+	})
+	assert.NoError(t, err)
+	_, err = handler.Handle(ctx, newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":`+string(params)+`}`,
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, publishedDiagnostics(t, out))
+
+	created := filepath.Join(dir, "static", "sub", "go.mod")
+	assert.NoError(t, os.WriteFile(created, []byte("module example.com/sub\n"), 0644))
+	params, err = json.Marshal(map[string]interface{}{
+		"changes": []interface{}{map[string]interface{}{"uri": uri.File(created), "type": 1}},
+	})
+	assert.NoError(t, err)
+	_, err = handler.Handle(ctx, newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"workspace/didChangeWatchedFiles","params":`+string(params)+`}`,
+	))
+	assert.NoError(t, err)
+	diagnostics := publishedDiagnostics(t, out)
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, "embed/nested-module", diagnostics[0].(map[string]interface{})["code"])
+	}
+}
+
+// TestDidChangeWatchedConfigFile tests that editing the configuration file
+// of the workspace root reloads the configuration, keeping the settings of
+// the client applied over it.
+func TestDidChangeWatchedConfigFile(t *testing.T) {
+	root := writeTestFiles(t, map[string]string{
+		".embedpls.yaml": "ignore: [\"*.tmp\"]\n",
+	})
+	handler, _ := newTestHandler()
+	ctx := context.Background()
+	_, err := handler.Handle(ctx, newTestMessage(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":"`+
+			string(uri.File(root))+`","capabilities":{}}}`,
+	))
+	assert.NoError(t, err)
+	_, err = handler.Handle(ctx, newTestMessage(t,
+		`{"jsonrpc":"2.0","method":"workspace/didChangeConfiguration","params":`+
+			`{"settings":{"embedpls":{"previewSize":64}}}}`,
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*.tmp"}, handler.currentConfig().Ignore)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{name: "other file", file: "notes.yaml", content: "ignore: [x]\n", want: []string{"*.tmp"}},
+		{name: "config file", file: ".embedpls.yaml", content: "ignore: [dist/]\n", want: []string{"dist/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(root, tt.file)
+			assert.NoError(t, os.WriteFile(filename, []byte(tt.content), 0644))
+			params, err := json.Marshal(map[string]interface{}{
+				"changes": []interface{}{map[string]interface{}{"uri": uri.File(filename), "type": 2}},
+			})
+			assert.NoError(t, err)
+			_, err = handler.Handle(ctx, newTestMessage(t,
+				`{"jsonrpc":"2.0","method":"workspace/didChangeWatchedFiles","params":`+string(params)+`}`,
+			))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, handler.currentConfig().Ignore)
+			assert.Equal(t, 64, handler.currentConfig().PreviewSize)
+		})
+	}
+}
+
+// TestIsConfigFile tests recognizing the configuration files of the
+// workspace root, without panicking on URIs that are not file URIs.
+func TestIsConfigFile(t *testing.T) {
+	handler, _ := newTestHandler()
+	handler.root = filepath.FromSlash("/work/project")
+	tests := []struct {
+		name string
+		file uri.URI
+		want bool
+	}{
+		{name: "config file", file: uri.File(filepath.FromSlash("/work/project/.embedpls.json")), want: true},
+		{name: "escaped", file: "file:///work/project/%2Eembedpls.yml", want: true},
+		{name: "nested config file", file: uri.File(filepath.FromSlash("/work/project/sub/.embedpls.json"))},
+		{name: "other file", file: uri.File(filepath.FromSlash("/work/project/main.go"))},
+		{name: "untitled", file: "untitled:Untitled-1"},
+		{name: "malformed", file: "file://%zz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handler.isConfigFile(tt.file); got != tt.want {
+				t.Errorf("isConfigFile(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
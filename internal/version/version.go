@@ -0,0 +1,24 @@
+// Package version reports the version of the running embedpls build.
+package version
+
+import "runtime/debug"
+
+// version is the version set at link time with
+// -ldflags "-X github.com/conneroisu/embedpls/internal/version.version=v1.2.3".
+var version string
+
+// develVersion is the version reported by builds of a working tree.
+const develVersion = "(devel)"
+
+// Version returns the version of the running build: the version set at
+// link time, otherwise the module version recorded by go install,
+// otherwise "(devel)".
+func Version() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return develVersion
+}
@@ -0,0 +1,17 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVersion tests that the version set at link time takes precedence
+// over the build info.
+func TestVersion(t *testing.T) {
+	assert.NotEmpty(t, Version())
+
+	version = "v1.2.3"
+	t.Cleanup(func() { version = "" })
+	assert.Equal(t, "v1.2.3", Version())
+}
@@ -0,0 +1,42 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/conneroisu/embedpls/server"
+)
+
+// ExampleServer_Serve initializes an in-process server and prints the
+// name it reports to the client.
+func ExampleServer_Serve() {
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`
+	in := strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body))
+	out := &bytes.Buffer{}
+	err := server.New(server.Options{}).Serve(context.Background(), struct {
+		io.Reader
+		io.Writer
+	}{in, out})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_, content, _ := strings.Cut(out.String(), "\r\n\r\n")
+	var response struct {
+		Result struct {
+			ServerInfo struct {
+				Name string `json:"name"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(content), &response); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(response.Result.ServerInfo.Name)
+	// Output: embedpls
+}
@@ -0,0 +1,210 @@
+// Package server exposes the embedpls language server to Go programs.
+//
+// It serves the language server protocol over any reader and writer, so
+// test harnesses and combined language servers can run embedpls in
+// process instead of spawning the embedpls executable.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/lsp/methods"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	rpcerrors "github.com/conneroisu/embedpls/internal/rpc/errors"
+	"github.com/conneroisu/embedpls/internal/safe"
+	handlers "github.com/conneroisu/embedpls/internal/server"
+	"github.com/conneroisu/embedpls/internal/version"
+	"go.lsp.dev/uri"
+)
+
+// Options configures a Server.
+type Options struct {
+	// MaxMessageSize is the maximum size, in bytes, of the content of a
+	// message read from the client. It defaults to 16MB.
+	MaxMessageSize int
+	// Version is the version reported to clients at initialization. It
+	// defaults to the version of the embedpls build.
+	Version string
+	// RequestTimeout bounds the time given to the server to handle a
+	// message, so that a stalled file system cannot hang a request. It
+	// defaults to one second.
+	RequestTimeout time.Duration
+	// Root is the directory of the workspace root, used instead of the
+	// root sent by the client at initialization when not empty, for
+	// clients not sending a sensible one.
+	Root string
+}
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends the
+// exit notification without requesting the shutdown of the session first,
+// which the protocol asks to end with a failure exit code.
+var ErrExitWithoutShutdown = handlers.ErrExitWithoutShutdown
+
+// Server is an embedpls language server.
+type Server struct {
+	opts Options
+}
+
+// New creates a new Server.
+func New(opts Options) *Server {
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = rpc.DefaultMaxMessageSize
+	}
+	if opts.Version == "" {
+		opts.Version = version.Version()
+	}
+	return &Server{opts: opts}
+}
+
+// Serve runs the read loop of the server, answering the messages read
+// from rw on rw until rw is exhausted or the client sends the exit
+// notification.
+//
+// Exiting only ends the session: the requests still running are cancelled
+// and Serve returns, nil after a shutdown request and
+// ErrExitWithoutShutdown otherwise, leaving the process and the other
+// sessions of the Server running.
+//
+// Every call serves a new session with its own documents, so a Server may
+// serve several clients at once. Malformed messages are logged and
+// skipped, resuming at the next Content-Length header, those whose content
+// is not JSON being answered with a ParseError.
+func (s *Server) Serve(ctx context.Context, rw io.ReadWriter) error {
+	scanner := rpc.NewScannerWithLimit(rw, s.opts.MaxMessageSize)
+	rpcWriter := rpc.NewWriter(rw)
+	innerCtx, cancel := context.WithCancel(ctx)
+	documents := safe.NewSafeMap[uri.URI, string]()
+	handler := handlers.NewLSPHandler(
+		documents,
+		rpcWriter,
+		handlers.HandlerOptions{
+			Version: s.opts.Version,
+			Timeout: s.opts.RequestTimeout,
+			Root:    s.opts.Root,
+		},
+	)
+	defer cancel()
+	for scanner.Scan() {
+		decoded, err := rpc.DecodeMessage(scanner.Bytes())
+		if err != nil {
+			// a malformed frame is skipped by the scanner, so the
+			// session survives it
+			log.Errorf("failed to decode message: %s", err)
+			replyParseError(innerCtx, rpcWriter, err)
+			continue
+		}
+		resp, err := handler.Handle(
+			innerCtx,
+			decoded,
+		)
+		if errors.Is(err, handlers.ErrExitWithoutShutdown) {
+			return ErrExitWithoutShutdown
+		}
+		if errors.Is(err, handlers.ErrExit) {
+			return nil
+		}
+		reply(innerCtx, rpcWriter, decoded, resp, err)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read message: %w", err)
+	}
+	return nil
+}
+
+// reply writes the response to a handled message, or the error response
+// when handling a request failed.
+//
+// Requests whose handler returns no response are answered with a null
+// result. Notifications and replies to the requests of the server are
+// never answered, even when their handler mistakenly returns a response.
+func reply(
+	ctx context.Context,
+	writer *rpc.Writer,
+	msg *rpc.BaseMessage,
+	resp rpc.MethodActor,
+	err error,
+) {
+	if err != nil {
+		log.Errorf(
+			"failed to handle message: %s",
+			err,
+		)
+	}
+	if msg.Method == "" || msg.IsNotification() {
+		if err == nil && !isNull(resp) {
+			log.Warnf("dropping response to %s", resp.Method())
+		}
+		return
+	}
+	if err != nil {
+		resp = errorResponse(msg, err)
+	}
+	if isNull(resp) {
+		// every request is answered, those without a result with null
+		resp = lsp.NewNullResponse(msg.ID, methods.Method(msg.Method))
+	}
+	if err := writer.WriteResponse(ctx, resp); err != nil {
+		log.Errorf(
+			"failed to write (%s) response: %s",
+			resp.Method(),
+			err,
+		)
+	}
+}
+
+// replyParseError answers a frame whose content failed to parse with a
+// ParseError, its id being unknown. Frames with a malformed header are not
+// answered, as nothing tells they are requests.
+func replyParseError(ctx context.Context, writer *rpc.Writer, err error) {
+	var rpcErr *rpcerrors.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpcerrors.CodeParseError {
+		return
+	}
+	if err := writer.WriteResponse(ctx, lsp.NewParseErrorResponse(rpcErr.Message)); err != nil {
+		log.Errorf("failed to write parse error response: %s", err)
+	}
+}
+
+// errorResponse returns the response to a request that failed with err.
+//
+// Errors carrying a JSON-RPC error code are answered with it, others are
+// internal errors.
+func errorResponse(msg *rpc.BaseMessage, err error) lsp.ErrorResponse {
+	code, message := rpcerrors.CodeInternalError, err.Error()
+	var rpcErr *rpcerrors.Error
+	if errors.As(err, &rpcErr) {
+		code, message = rpcErr.Code, rpcErr.Message
+	}
+	return lsp.NewErrorResponse(
+		msg.ID,
+		methods.Method(msg.Method),
+		int(code),
+		message,
+	)
+}
+
+// isNull checks if the given interface is nil or points to a nil value
+func isNull(i interface{}) bool {
+	if i == nil {
+		return true
+	}
+	// Use reflect.ValueOf only if the kind is valid for checking nil
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.Chan,
+		reflect.Func,
+		reflect.Interface,
+		reflect.Map,
+		reflect.Ptr,
+		reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/embedpls/internal/lsp"
+	"github.com/conneroisu/embedpls/internal/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestServeMaxMessageSize tests that the maximum message size of the
+// options bounds the messages read from the client.
+func TestServeMaxMessageSize(t *testing.T) {
+	body := `{"jsonrpc":"2.0","method":"initialized","params":{}}`
+	message := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{name: "default", opts: Options{}},
+		{name: "large enough", opts: Options{MaxMessageSize: len(body)}},
+		{name: "too small", opts: Options{MaxMessageSize: len(body) - 1}, wantErr: rpc.ErrMessageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(tt.opts).Serve(context.Background(), struct {
+				io.Reader
+				io.Writer
+			}{strings.NewReader(message), io.Discard})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestServeErrorResponse tests that failed requests are answered with a
+// JSON-RPC error while failed notifications are not answered.
+func TestServeErrorResponse(t *testing.T) {
+	frame := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	in := frame(`{"jsonrpc":"2.0","method":"custom/unknown","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","id":7,"method":"textDocument/completion","params":{`+
+			`"textDocument":{"uri":"file:///tmp/missing.go"},"position":{"line":0,"character":0}}}`)
+	out := &strings.Builder{}
+	err := New(Options{}).Serve(context.Background(), struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(in), out})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(out.String(), "Content-Length"))
+	_, content, _ := strings.Cut(out.String(), "\r\n\r\n")
+	assert.JSONEq(
+		t,
+		`{"jsonrpc":"2.0","id":7,"error":{"code":-32603,"message":"document not found"}}`,
+		content,
+	)
+}
+
+// TestServeMalformedFrame tests that malformed frames are skipped and the
+// messages following them still answered.
+func TestServeMalformedFrame(t *testing.T) {
+	frame := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	valid := frame(`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`)
+	tests := []struct {
+		name    string
+		garbage string
+		// wantParseError is whether the garbage is answered with a
+		// ParseError.
+		wantParseError bool
+	}{
+		{name: "invalid JSON", garbage: frame(`{"jsonrpc":`), wantParseError: true},
+		{name: "invalid header", garbage: "Content-Length: abc\r\n\r\n"},
+		{name: "missing header", garbage: "garbage\r\n\r\n"},
+		{name: "garbage before header", garbage: "garbage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &strings.Builder{}
+			err := New(Options{}).Serve(context.Background(), struct {
+				io.Reader
+				io.Writer
+			}{strings.NewReader(tt.garbage + valid), out})
+			assert.NoError(t, err)
+			responses := 1
+			if tt.wantParseError {
+				responses++
+				assert.Contains(t, out.String(), `"id":null,"error":{"code":-32700`)
+			}
+			assert.Equal(t, responses, strings.Count(out.String(), "Content-Length"))
+			assert.Contains(t, out.String(), `"id":3`)
+		})
+	}
+}
+
+// TestServeHeaderFields tests that the header fields other than
+// Content-Length are ignored, whatever their order and the case of their
+// names.
+func TestServeHeaderFields(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":3,"method":"shutdown"}`
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "content type after", header: fmt.Sprintf("Content-Length: %d\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8", len(body))},
+		{name: "content type before", header: fmt.Sprintf("Content-Type: application/vscode-jsonrpc; charset=utf-8\r\nContent-Length: %d", len(body))},
+		{name: "lower case", header: fmt.Sprintf("content-length:%d", len(body))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &strings.Builder{}
+			err := New(Options{}).Serve(context.Background(), struct {
+				io.Reader
+				io.Writer
+			}{strings.NewReader(tt.header + "\r\n\r\n" + body), out})
+			assert.NoError(t, err)
+			assert.Contains(t, out.String(), `"id":3`)
+		})
+	}
+}
+
+// TestServeMethodNotFound tests that requests of unsupported methods are
+// answered with the MethodNotFound error code.
+func TestServeMethodNotFound(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":5,"method":"textDocument/prepareCallHierarchy","params":{}}`
+	out := &strings.Builder{}
+	err := New(Options{}).Serve(context.Background(), struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)), out})
+	assert.NoError(t, err)
+	_, content, _ := strings.Cut(out.String(), "\r\n\r\n")
+	assert.JSONEq(
+		t,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32601,"message":"unknown method: textDocument/prepareCallHierarchy"}}`,
+		content,
+	)
+}
+
+// TestReplyNotification tests that a notification is never answered, even
+// when its handler mistakenly returns a response.
+func TestReplyNotification(t *testing.T) {
+	body := `{"jsonrpc":"2.0","method":"textDocument/didSave","params":{}}`
+	msg, err := rpc.DecodeMessage([]byte(fmt.Sprintf(
+		"Content-Length: %d\r\n\r\n%s",
+		len(body),
+		body,
+	)))
+	assert.NoError(t, err)
+	out := &strings.Builder{}
+	reply(
+		context.Background(),
+		rpc.NewWriter(out),
+		msg,
+		&lsp.HoverResponse{Response: lsp.Response{RPC: lsp.RPCVersion}},
+		nil,
+	)
+	assert.Empty(t, out.String())
+}
+
+// TestServeNullResult tests that a request whose handler has no result,
+// such as a hover away from any directive, is answered with null.
+func TestServeNullResult(t *testing.T) {
+	frame := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	in := frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{`+
+		`"uri":"file:///tmp/main.go","languageId":"go","version":1,"text":"package main\n"}}}`) +
+		frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{`+
+			`"textDocument":{"uri":"file:///tmp/main.go"},"position":{"line":0,"character":0}}}`)
+	out := &strings.Builder{}
+	err := New(Options{}).Serve(context.Background(), struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(in), out})
+	assert.NoError(t, err)
+	frames := strings.Split(out.String(), "Content-Length")
+	_, content, _ := strings.Cut(frames[len(frames)-1], "\r\n\r\n")
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":null}`, content)
+}
+
+// TestReplyNullResult tests that a request is answered with null when its
+// handler returns no response, even when its id is 0.
+func TestReplyNullResult(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":0,"method":"textDocument/hover","params":{}}`
+	msg, err := rpc.DecodeMessage([]byte(fmt.Sprintf(
+		"Content-Length: %d\r\n\r\n%s",
+		len(body),
+		body,
+	)))
+	assert.NoError(t, err)
+	out := &strings.Builder{}
+	reply(context.Background(), rpc.NewWriter(out), msg, nil, nil)
+	_, content, _ := strings.Cut(out.String(), "\r\n\r\n")
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":0,"result":null}`, content)
+}
+
+// TestServeExit tests that the exit notification ends the session without
+// exiting the process, reading no message past it.
+func TestServeExit(t *testing.T) {
+	frame := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	exit := frame(`{"jsonrpc":"2.0","method":"exit"}`)
+	after := frame(`{"jsonrpc":"2.0","id":9,"method":"shutdown"}`)
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+		want    int
+	}{
+		{
+			name: "after shutdown",
+			in:   frame(`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`) + exit + after,
+			want: 1,
+		},
+		{
+			name:    "without shutdown",
+			in:      exit + after,
+			wantErr: ErrExitWithoutShutdown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &strings.Builder{}
+			err := New(Options{}).Serve(context.Background(), struct {
+				io.Reader
+				io.Writer
+			}{strings.NewReader(tt.in), out})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, strings.Count(out.String(), "Content-Length"))
+		})
+	}
+}